@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"taskg/internal/app"
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse github.com/org/repo[@ref]",
+	Short: "Browse the tasks of a GitHub repository read-only, without cloning it",
+	Long: `Fetches the Taskfile of a GitHub repository, and the local Taskfiles it includes, over HTTPS into a temporary
+directory and opens them read-only: tasks, commands and descriptions can be inspected, but nothing runs.
+A branch, tag or commit can follow an @, e.g. taskg browse github.com/go-task/task@v3.39.2.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := taskmeta.ParseGitHubRepo(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, cfgErr := config.Load()
+		if cfgErr != nil {
+			fmt.Fprintf(os.Stderr, "taskg: ignoring config: %v\n", cfgErr)
+		}
+		if !cmd.Flags().Changed("group-by") {
+			groupBy = cfg.GroupBy
+		}
+		if !config.ValidGroupBy(groupBy) {
+			groupBy = config.GroupPrefix
+		}
+
+		dir, err := os.MkdirTemp("", "taskg-browse-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		fmt.Fprintf(os.Stderr, "Fetching Taskfiles from %s...\n", repo)
+		if err := taskmeta.FetchGitHubTaskfiles(context.Background(), repo, dir); err != nil {
+			return err
+		}
+
+		model := app.NewTaskModel(nil, theme, !noMouse, repo.Owner+"/"+repo.Name)
+		model.SetBrowseRoot(dir, repo.String())
+		model.LoadAsync()
+		model.SetGroupBy(groupBy)
+		model.SetHideUnsupported(cfg.HideUnsupported)
+		model.SetGrid(cfg.GridColumns, cfg.GridColumnWidth)
+		model.SetHideCommands(cfg.HideCommands)
+		model.SetPinnedTabs(cfg.PinnedTabs)
+		model.SetHideTabCounts(cfg.HideTabCounts)
+		model.SetTree(cfg.Tree)
+		model.SetIcons(cfg.Icons, cfg.IconMap)
+		model.SetHeader(cfg.Header, cfg.Subheader)
+		model.SetChrome(cfg.HideHeader, cfg.HideFooter, cfg.Borderless)
+		model.SetTypeToSearch(cfg.TypeToSearch)
+		model.SetScrollMargin(cfg.ScrollMargin)
+		model.SetDescLines(cfg.DescLines)
+		if !cmd.Flags().Changed("ascii") {
+			ascii = cfg.ASCII || app.LimitedTerminal(os.Getenv)
+		}
+		model.SetASCII(ascii)
+		model.SetReducedMotion(cfg.ReducedMotion)
+		model.SetAccessible(accessible || cfg.Accessible)
+		if err := model.SetMaskPatterns(cfg.Mask); err != nil {
+			fmt.Fprintf(os.Stderr, "taskg: ignoring mask patterns: %v\n", err)
+		}
+		options := []tea.ProgramOption{tea.WithAltScreen()}
+		if !noMouse {
+			options = append(options, tea.WithMouseCellMotion())
+		}
+		_, err = tea.NewProgram(model, options...).Run()
+		return err
+	},
+}
+
+func init() {
+	browseCmd.Flags().StringVar(&theme, "theme", "dark", "Theme: dark, light or high-contrast")
+	browseCmd.Flags().BoolVar(&noMouse, "no-mouse", false, "Disable mouse support")
+	browseCmd.Flags().BoolVar(&ascii, "ascii", false, "Draw with ASCII characters only (default: on for the Linux console and non-UTF-8 locales)")
+	browseCmd.Flags().BoolVar(&accessible, "accessible", false, "Render plain lines of text without boxes, for screen readers and braille displays")
+	browseCmd.Flags().StringVar(&groupBy, "group-by", config.GroupPrefix, "Tab grouping: prefix, namespace, file, tag or flat")
+	rootCmd.AddCommand(browseCmd)
+}
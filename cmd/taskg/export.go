@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"taskg/internal/config"
+	"taskg/internal/export"
+	"taskg/internal/taskmeta"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	ghaTasks     []string
+	ghaName      string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Generate files for other tools from the discovered tasks",
+}
+
+var exportVSCodeCmd = &cobra.Command{
+	Use:   "vscode",
+	Short: "Write the tasks to .vscode/tasks.json",
+	Long: `Writes the discovered tasks to .vscode/tasks.json in the project root, so VS Code can run them from its
+task picker. Labels are "<backend>: <name>" and stay the same across regenerations. Entries added by hand are
+kept, as are extra fields set on generated entries, such as presentation or dependsOn. Variables documented
+with a "Usage: task name -- VAR=\"default\"" description are asked for when the task starts.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, tasks, err := exportTasks(cmd)
+		if err != nil {
+			return err
+		}
+		path := exportOutput
+		if path == "" {
+			path = filepath.Join(root, ".vscode", "tasks.json")
+		}
+		var existing []byte
+		if path != "-" {
+			existing, err = os.ReadFile(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		out, err := export.VSCodeTasks(tasks, root, existing)
+		if err != nil {
+			return err
+		}
+		return writeExport(path, out, len(tasks))
+	},
+}
+
+var exportMarkdownCmd = &cobra.Command{
+	Use:   "md",
+	Short: "Write the tasks to TASKS.md",
+	Long: `Writes TASKS.md to the project root: a table of the tasks with their descriptions, commands and
+dependencies, grouped by include namespace. Regenerate it whenever the Taskfile changes.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, tasks, err := exportTasks(cmd)
+		if err != nil {
+			return err
+		}
+		path := exportOutput
+		if path == "" {
+			path = filepath.Join(root, "TASKS.md")
+		}
+		out := export.Markdown(tasks, "Tasks of "+filepath.Base(root))
+		return writeExport(path, out, len(tasks))
+	},
+}
+
+var exportGHACmd = &cobra.Command{
+	Use:   "gha",
+	Short: "Write a GitHub Actions workflow running the given tasks",
+	Long: `Writes .github/workflows/tasks.yml with one job per --task, run on pushes and pull requests. Each job
+checks out the repository, installs Task (or Node for package.json scripts) and runs the task from its
+directory, so the Taskfile stays the single source of truth for what CI does.
+
+  taskg export gha --task ci
+  taskg export gha --task lint --task test -o .github/workflows/ci.yml`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(ghaTasks) == 0 {
+			return errors.New("name the tasks to run with --task")
+		}
+		root, tasks, err := exportTasks(cmd)
+		if err != nil {
+			return err
+		}
+		path := exportOutput
+		if path == "" {
+			path = filepath.Join(root, ".github", "workflows", "tasks.yml")
+		}
+		out, err := export.GitHubWorkflow(tasks, root, ghaName, ghaTasks)
+		if err != nil {
+			return err
+		}
+		return writeExport(path, out, len(ghaTasks))
+	},
+}
+
+// exportTasks discovers the tasks to export, like the TUI does on start.
+func exportTasks(cmd *cobra.Command) (string, []taskmeta.Task, error) {
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil {
+		fmt.Fprintf(os.Stderr, "taskg: ignoring config: %v\n", cfgErr)
+	}
+	if !cmd.Flags().Changed("recursive") {
+		recursive = cfg.Recursive
+	}
+	taskmeta.DiscoveryTimeout = cfg.DiscoveryTimeout
+	root, err := serveRoot()
+	if err != nil {
+		return "", nil, err
+	}
+	discover := taskmeta.DiscoverTasks
+	if mixed {
+		discover = taskmeta.DiscoverAll
+	}
+	var tasks []taskmeta.Task
+	if recursive {
+		tasks, err = taskmeta.DiscoverRecursive(root, discover)
+	} else {
+		tasks, err = discover(root)
+	}
+	var partial *taskmeta.PartialError
+	if errors.As(err, &partial) {
+		fmt.Fprintf(os.Stderr, "taskg: %v\n", err)
+		err = nil
+	}
+	return root, tasks, err
+}
+
+// writeExport writes out to path, or to stdout for "-".
+func writeExport(path string, out []byte, n int) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d tasks to %s\n", n, path)
+	return nil
+}
+
+func init() {
+	exportCmd.PersistentFlags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	exportCmd.PersistentFlags().BoolVar(&mixed, "mixed", false, "Also export Makefile targets and package.json scripts")
+	exportCmd.PersistentFlags().BoolVar(&recursive, "recursive", false, "Also export the tasks of Taskfiles in subdirectories")
+	exportCmd.PersistentFlags().StringVarP(&exportOutput, "output", "o", "", "File to write, or - for stdout")
+	exportGHACmd.Flags().StringArrayVar(&ghaTasks, "task", nil, "Task to run in a job of its own; repeat for more")
+	exportGHACmd.Flags().StringVar(&ghaName, "name", "Tasks", "Name of the workflow")
+	exportCmd.AddCommand(exportVSCodeCmd, exportMarkdownCmd, exportGHACmd)
+	rootCmd.AddCommand(exportCmd)
+}
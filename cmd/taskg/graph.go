@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"taskg/internal/export"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat string
+	graphTask   string
+	graphOutput string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the task dependency graph as Graphviz DOT or Mermaid",
+	Long: `Prints the dependency graph of the discovered tasks, with an arrow from each task to the tasks in its
+deps:. Include namespaces and subprojects are drawn as nested clusters, and deps on tasks taskg does not list,
+such as internal ones, as dashed boxes. With --task only that task and what it depends on are drawn.
+
+  taskg graph | dot -Tsvg > tasks.svg
+  taskg graph --format mermaid --task ci`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(export.GraphFormats, graphFormat) {
+			return fmt.Errorf("--format must be one of %s", strings.Join(export.GraphFormats, ", "))
+		}
+		_, tasks, err := exportTasks(cmd)
+		if err != nil {
+			return err
+		}
+		out, err := export.Graph(tasks, graphFormat, graphTask)
+		if err != nil {
+			return err
+		}
+		return writeExport(graphOutput, out, len(tasks))
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	graphCmd.Flags().BoolVar(&recursive, "recursive", false, "Also draw the tasks of Taskfiles in subdirectories")
+	graphCmd.Flags().StringVar(&graphFormat, "format", export.GraphDOT, "Output format: "+strings.Join(export.GraphFormats, " or "))
+	graphCmd.Flags().StringVar(&graphTask, "task", "", "Only draw this task and the tasks it depends on")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "-", "File to write, or - for stdout")
+	rootCmd.AddCommand(graphCmd)
+}
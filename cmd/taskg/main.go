@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"taskg/internal/app"
+	"taskg/internal/config"
+	"taskg/internal/hooks"
+	"taskg/internal/runner"
 	"taskg/internal/taskmeta"
+	"taskg/internal/telemetry"
 	"taskg/internal/version"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +29,23 @@ var (
 	theme      string
 	noMouse    bool
 	projectDir string
+	mixed      bool
+	scrollback int
+	jobs       int
+	noPTY      bool
+	recursive  bool
+	layout     string
+	height     string
+	printOnly  bool
+	groupBy    string
+	timeout    time.Duration
+	runIn      string
+	global     bool
+	retries    int
+	backoff    time.Duration
+	ascii      bool
+	accessible bool
+	noMotion   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -28,6 +55,64 @@ var rootCmd = &cobra.Command{
 and lets you search, inspect, and run them. It requires the 'task' binary to be installed and on PATH.`,
 	Version: version.Version,
 	Run: func(cmd *cobra.Command, args []string) {
+		cfg, cfgErr := config.Load()
+		if cfgErr != nil {
+			fmt.Fprintf(os.Stderr, "taskg: ignoring config: %v\n", cfgErr)
+		}
+		// Flags given on the command line win over the config file.
+		if !cmd.Flags().Changed("recursive") {
+			recursive = cfg.Recursive
+		}
+		if !cmd.Flags().Changed("project-layout") {
+			layout = cfg.ProjectLayout
+		}
+		if layout != config.LayoutTabs && layout != config.LayoutColumn {
+			fmt.Fprintf(os.Stderr, "--project-layout must be %q or %q\n", config.LayoutTabs, config.LayoutColumn)
+			os.Exit(2)
+		}
+		if !cmd.Flags().Changed("group-by") {
+			groupBy = cfg.GroupBy
+		}
+		if !config.ValidGroupBy(groupBy) {
+			fmt.Fprintf(os.Stderr, "--group-by must be one of %s\n", strings.Join(config.GroupStrategies, ", "))
+			os.Exit(2)
+		}
+		if !cmd.Flags().Changed("discovery-timeout") {
+			timeout = cfg.DiscoveryTimeout
+		}
+		if timeout <= 0 {
+			fmt.Fprintln(os.Stderr, "--discovery-timeout must be positive")
+			os.Exit(2)
+		}
+		taskmeta.DiscoveryTimeout = timeout
+		if !cmd.Flags().Changed("run-in") {
+			runIn = cfg.RunIn
+		}
+		if !config.ValidRunIn(runIn) {
+			fmt.Fprintf(os.Stderr, "--run-in must be one of %s or a command with {cmd}\n", strings.Join(config.RunInTargets, ", "))
+			os.Exit(2)
+		}
+		if !cmd.Flags().Changed("ascii") {
+			ascii = cfg.ASCII || app.LimitedTerminal(os.Getenv)
+		}
+		if !cmd.Flags().Changed("accessible") {
+			accessible = cfg.Accessible
+		}
+		if !cmd.Flags().Changed("reduced-motion") {
+			noMotion = cfg.ReducedMotion
+		}
+		if cmd.Flags().Changed("retry") {
+			// --retry applies to every task, not only the configured ones.
+			cfg.Retry.Count, cfg.Retry.Tasks = retries, nil
+		}
+		if cmd.Flags().Changed("retry-backoff") {
+			cfg.Retry.Backoff = backoff
+		}
+		if cfg.Retry.Count < 0 || cfg.Retry.Backoff < 0 {
+			fmt.Fprintln(os.Stderr, "--retry and --retry-backoff must not be negative")
+			os.Exit(2)
+		}
+
 		// Determine working directory / project root
 		startDir := projectDir
 		if startDir == "" {
@@ -35,29 +120,90 @@ and lets you search, inspect, and run them. It requires the 'task' binary to be
 			cwd, _ := os.Getwd()
 			startDir = cwd
 		}
+		if abs, err := filepath.Abs(startDir); err == nil {
+			startDir = abs
+		}
 		root, err := taskmeta.FindNearestTaskfileRoot(startDir)
-		var tasks []taskmeta.Task
+		if err != nil && (mixed || recursive) {
+			// Mixed mode can work from a Makefile or package.json alone, and
+			// recursive mode from Taskfiles that only exist in subdirectories.
+			root, err = startDir, nil
+		}
 		var model *app.TaskModel
-		if err != nil {
+		if global {
+			// The registered projects are shown like the subprojects of
+			// recursive mode.
+			dir, projects, err := globalProjects()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "taskg: %v\n", err)
+				os.Exit(2)
+			}
+			recursive = true
+			model = app.NewTaskModel(nil, theme, !noMouse, "global")
+			model.SetGlobal(dir, projects)
+			model.LoadAsync()
+		} else if err != nil {
 			model = app.NewTaskModel(nil, theme, !noMouse, filepath.Base(startDir))
+			model.SetStartDir(startDir)
 			model.Error("No Taskfile found in this or parent directories. Use --project to point elsewhere or create a Taskfile.yml.")
 		} else {
-			tasks, err = taskmeta.DiscoverTasks(root)
-			if err != nil {
-				model = app.NewTaskModel(nil, theme, !noMouse, filepath.Base(root))
-				model.SetProjectRoot(root)
-				model.Error(fmt.Sprintf("Failed to enumerate tasks: %v", err))
-			} else if len(tasks) == 0 {
-				model = app.NewTaskModel(nil, theme, !noMouse, filepath.Base(root))
-				model.SetProjectRoot(root)
-				model.Error("No tasks discovered in Taskfile.")
-			} else {
-				model = app.NewTaskModel(tasks, theme, !noMouse, filepath.Base(root))
-				model.SetProjectRoot(root)
-			}
+			// Discovery runs inside the TUI so the first frame is not held up
+			// by big monorepos or slow file systems.
+			model = app.NewTaskModel(nil, theme, !noMouse, filepath.Base(root))
+			model.SetProjectRoot(root)
+			model.LoadAsync()
+		}
+		model.SetMixedBackends(mixed)
+		model.SetRecursive(recursive, layout)
+		model.SetGroupBy(groupBy)
+		model.SetConfirmPatterns(cfg.Confirm)
+		model.SetShowPlan(cfg.ShowPlan)
+		model.SetHooks(cfg.Hooks)
+		model.SetTelemetry(cfg.Telemetry)
+		model.SetLogs(cfg.Logs)
+		model.SetRetry(cfg.Retry)
+		model.SetContainers(cfg.Containers)
+		model.SetHideUnsupported(cfg.HideUnsupported)
+		model.SetGrid(cfg.GridColumns, cfg.GridColumnWidth)
+		model.SetHideCommands(cfg.HideCommands)
+		model.SetPinnedTabs(cfg.PinnedTabs)
+		model.SetHideTabCounts(cfg.HideTabCounts)
+		model.SetTree(cfg.Tree)
+		model.SetIcons(cfg.Icons, cfg.IconMap)
+		model.SetHeader(cfg.Header, cfg.Subheader)
+		model.SetChrome(cfg.HideHeader, cfg.HideFooter, cfg.Borderless)
+		model.SetTypeToSearch(cfg.TypeToSearch)
+		model.SetScrollMargin(cfg.ScrollMargin)
+		model.SetDescLines(cfg.DescLines)
+		model.SetASCII(ascii)
+		model.SetReducedMotion(noMotion)
+		model.SetAccessible(accessible)
+		if err := model.SetMaskPatterns(cfg.Mask); err != nil {
+			fmt.Fprintf(os.Stderr, "taskg: ignoring mask patterns: %v\n", err)
+		}
+		model.SetScrollback(scrollback)
+		model.SetMaxJobs(jobs)
+		model.SetPTY(!noPTY)
+		if err := model.SetInlineHeight(height); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		// --height renders inline below the prompt and, like fzf, prints the
+		// selection instead of running it.
+		if height != "" {
+			printOnly = true
+		}
+		if !printOnly {
+			model.SetRunIn(runIn)
 		}
 		var options []tea.ProgramOption
-		options = append(options, tea.WithAltScreen())
+		if height == "" {
+			options = append(options, tea.WithAltScreen())
+		}
+		if printOnly {
+			// Keep stdout clean for $(taskg --print).
+			options = append(options, tea.WithOutput(os.Stderr))
+		}
 		if !noMouse {
 			options = append(options, tea.WithMouseCellMotion())
 		}
@@ -68,10 +214,22 @@ and lets you search, inspect, and run them. It requires the 'task' binary to be
 		}
 		// After TUI exits, check if a task should be run
 		if m, ok := finalModel.(*app.TaskModel); ok {
+			// Don't leave in-TUI runs behind as orphans
+			if m.CancelJobs() {
+				m.WaitJobs()
+			}
+			if printOnly {
+				if m.ShouldRun() {
+					fmt.Println(printCommand(m))
+				} else {
+					os.Exit(130) // nothing selected, as fzf does on abort
+				}
+				return
+			}
 			if m.ShouldRun() {
 				taskCmd := m.TaskToRun()
 				// Clear the screen for better visibility
-				fmt.Print("\033[H\033[2J")
+				clearScreen()
 				fmt.Println()
 
 				if len(taskCmd) == 0 {
@@ -79,35 +237,159 @@ and lets you search, inspect, and run them. It requires the 'task' binary to be
 					return
 				}
 
-				taskName := taskCmd[0]
 				taskArgs := taskCmd[1:]
 
-				argsForExec := []string{taskName}
-				if len(taskArgs) > 0 {
-					argsForExec = append(argsForExec, taskArgs...)
-				}
+				// Route to the binary owning the task (task, make, npm).
+				bin, argsForExec := m.RunInvocation()
 
-				c := exec.Command("task", argsForExec...)
-				if root != "" {
-					c.Dir = root
+				c := exec.Command(bin, argsForExec...)
+				// The user may have switched projects inside the TUI; in
+				// recursive mode the task runs from its own subproject.
+				if dir := m.RunTarget().WorkDir(m.ProjectRoot()); dir != "" {
+					c.Dir = dir
 				}
 				c.Stdout = os.Stdout
 				c.Stderr = os.Stderr
 				c.Stdin = os.Stdin
-				if err := c.Run(); err != nil {
-					// The task exiting with a non-zero status is not necessarily an
-					// error in the TUI runner, so just log it.
+				if env := m.RunEnv(); len(env) > 0 {
+					c.Env = append(os.Environ(), env...)
+				}
+				// Ctrl+C reaches the task too (same console / process group); keep
+				// taskg alive until the task has exited so its status is reported.
+				interrupts := make(chan os.Signal, 1)
+				signal.Notify(interrupts, os.Interrupt)
+				started := time.Now()
+				err := c.Run()
+				signal.Stop(interrupts)
+				notifyHooks(cfg.Hooks, m.RunTarget(), taskArgs, c.Dir, started, c.ProcessState)
+				exportTrace(cfg.Telemetry, m.RunTarget(), taskArgs, c.Dir, started, c.ProcessState)
+				m.RecordRunResult(time.Since(started), err == nil)
+				if err != nil {
+					// Propagate the task's exit code so scripts and CI can rely on it.
 					fmt.Fprintf(os.Stderr, "Task exited: %v\n", err)
+					var exitErr *exec.ExitError
+					if errors.As(err, &exitErr) {
+						os.Exit(exitStatus(exitErr.ProcessState))
+					}
+					os.Exit(1)
 				}
 			}
 		}
 	},
 }
 
+// exitStatus is the status taskg exits with after the task ended with
+// state: the task's own exit code, or 128 plus the number of the signal that
+// killed it (130 for Ctrl+C), as a shell reports it.
+func exitStatus(state *os.ProcessState) int {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	if code := state.ExitCode(); code > 0 {
+		return code
+	}
+	return 1
+}
+
+// notifyHooks calls the configured webhooks for a run that ended with state,
+// which is nil when the task could not be started.
+func notifyHooks(list []config.Hook, t taskmeta.Task, args []string, dir string, started time.Time, state *os.ProcessState) {
+	if state == nil {
+		return
+	}
+	r := hooks.Run{
+		Task:     t.Name,
+		Args:     args,
+		Project:  filepath.Base(dir),
+		Dir:      dir,
+		Started:  started,
+		Duration: time.Since(started),
+		ExitCode: state.ExitCode(),
+	}
+	if !hooks.Wanted(list, r) {
+		return
+	}
+	if err := hooks.Notify(context.Background(), list, r); err != nil {
+		fmt.Fprintf(os.Stderr, "taskg: webhook failed: %v\n", err)
+	}
+}
+
+// exportTrace exports a run that ended with state as a trace, unless the
+// task could not be started.
+func exportTrace(cfg config.Telemetry, t taskmeta.Task, args []string, dir string, started time.Time, state *os.ProcessState) {
+	if state == nil || !telemetry.Enabled(cfg) {
+		return
+	}
+	r := telemetry.Run{
+		Task:     t.Name,
+		Args:     args,
+		Project:  filepath.Base(dir),
+		Dir:      dir,
+		Started:  started,
+		Duration: time.Since(started),
+		ExitCode: state.ExitCode(),
+	}
+	if err := telemetry.Export(context.Background(), cfg, r); err != nil {
+		fmt.Fprintf(os.Stderr, "taskg: telemetry export failed: %v\n", err)
+	}
+}
+
+// printCommand renders the chosen task as a shell command for --print,
+// including env overrides and the project directory when it is not the
+// current one. The line does not change the shell's working directory. It is
+// PowerShell on Windows, unless taskg runs in an MSYS shell such as Git Bash.
+func printCommand(m *app.TaskModel) string {
+	target := m.RunTarget()
+	args := m.TaskToRun()[1:]
+	bin, argv := target.Invocation(args)
+	if m.RunsInContainer() {
+		bin, argv = m.RunInvocation()
+	} else if target.Script != "" {
+		// The built-in runner's script is not meant to be pasted.
+		bin, argv = "task", append([]string{target.Name}, args...)
+	}
+	cwd, _ := os.Getwd()
+	if dir := target.WorkDir(m.ProjectRoot()); dir != "" && dir != cwd && !m.RunsInContainer() {
+		switch bin {
+		case "make":
+			argv = append([]string{"-C", dir}, argv...)
+		case "npm":
+			argv = append([]string{"--prefix", dir}, argv...)
+		default:
+			argv = append([]string{"-d", dir}, argv...)
+		}
+	}
+	if taskmeta.PowerShellPrompt() {
+		return taskmeta.PowerShellLine(m.RunEnv(), bin, argv)
+	}
+	line := taskmeta.CommandLine(bin, argv)
+	if env := m.RunEnv(); len(env) > 0 {
+		line = taskmeta.CommandLine("env", env) + " " + line
+	}
+	return line
+}
+
 func init() {
-	rootCmd.Flags().StringVar(&theme, "theme", "dark", "Theme: dark or light")
+	rootCmd.Flags().StringVar(&theme, "theme", "dark", "Theme: dark, light or high-contrast")
 	rootCmd.Flags().BoolVar(&noMouse, "no-mouse", false, "Disable mouse support")
+	rootCmd.Flags().BoolVar(&ascii, "ascii", false, "Draw with ASCII characters only (default: on for the Linux console and non-UTF-8 locales)")
+	rootCmd.Flags().BoolVar(&noMotion, "reduced-motion", false, "Turn off animations such as the discovery spinner")
+	rootCmd.Flags().BoolVar(&accessible, "accessible", false, "Render plain lines of text without boxes, for screen readers and braille displays")
 	rootCmd.Flags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	rootCmd.Flags().BoolVar(&mixed, "mixed", false, "Also discover Makefile targets and package.json scripts, grouped by backend")
+	rootCmd.Flags().BoolVar(&recursive, "recursive", false, "Scan subdirectories for further Taskfiles (monorepo mode)")
+	rootCmd.Flags().BoolVar(&global, "global", false, "List the tasks of every registered project (see taskg projects)")
+	rootCmd.Flags().StringVar(&layout, "project-layout", config.LayoutTabs, "How --recursive shows subprojects: tabs or column")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", config.GroupPrefix, "Tab grouping: prefix, namespace, file, tag or flat")
+	rootCmd.Flags().DurationVar(&timeout, "discovery-timeout", config.DefaultDiscoveryTimeout, "Give up on parsing the Taskfile after this long and fall back to reading its YAML")
+	rootCmd.Flags().StringVar(&height, "height", "", "Render inline below the prompt using this many lines or percent (e.g. 40%) instead of the full screen; implies --print")
+	rootCmd.Flags().BoolVar(&printOnly, "print", false, "Print the selected task's command line to stdout instead of running it")
+	rootCmd.Flags().StringVar(&runIn, "run-in", "", "Run tasks in a new pane and keep taskg open: tmux-split, tmux-window, zellij, wezterm, kitty or a command with {cmd}")
+	rootCmd.Flags().IntVar(&jobs, "jobs", 4, "Maximum number of marked tasks run concurrently inside the TUI")
+	rootCmd.Flags().BoolVar(&noPTY, "no-pty", false, "Run in-TUI tasks with plain pipes instead of a pseudo-terminal")
+	rootCmd.Flags().IntVar(&scrollback, "scrollback", runner.DefaultScrollback, "Maximum number of output lines kept for in-TUI runs")
+	rootCmd.Flags().IntVar(&retries, "retry", 0, "Retry failed in-TUI runs this many times")
+	rootCmd.Flags().DurationVar(&backoff, "retry-backoff", 0, "Wait this long before the first retry, doubling for each further one")
 }
 
 func main() {
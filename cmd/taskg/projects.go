@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+
+	"github.com/spf13/cobra"
+)
+
+var projectName string
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List the projects registered for taskg --global",
+	Long: `Lists the registered projects, whose tasks taskg --global shows together. Register a project with
+"taskg projects add" from inside it.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := state.LoadRegistry()
+		if err != nil {
+			return err
+		}
+		if len(reg.Projects) == 0 {
+			fmt.Fprintln(os.Stderr, "No registered projects: add one with taskg projects add")
+			return nil
+		}
+		width := 0
+		for _, p := range reg.Projects {
+			width = max(width, len(p.Name))
+		}
+		for _, p := range reg.Projects {
+			fmt.Printf("%-*s  %s\n", width, p.Name, p.Root)
+		}
+		return nil
+	},
+}
+
+var projectsAddCmd = &cobra.Command{
+	Use:   "add [dir]",
+	Short: "Register the project in dir (default: the current one)",
+	Long: `Registers the project whose Taskfile is nearest to dir, or the current directory, for taskg --global.
+Its tasks are listed under the name of its directory, or under --name.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start := "."
+		if len(args) == 1 {
+			start = args[0]
+		}
+		root, err := taskmeta.FindNearestTaskfileRoot(start)
+		if err != nil {
+			return err
+		}
+		name := projectName
+		if name == "" {
+			name = filepath.Base(root)
+		}
+		reg, err := state.LoadRegistry()
+		if err != nil {
+			return err
+		}
+		for _, p := range reg.Projects {
+			if p.Name == name && p.Root != root {
+				return fmt.Errorf("%s is already the name of %s; pick another with --name", name, p.Root)
+			}
+		}
+		reg.Add(state.Project{Name: name, Root: root})
+		if err := reg.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Registered %s as %s\n", root, name)
+		return nil
+	},
+}
+
+var projectsRemoveCmd = &cobra.Command{
+	Use:          "remove <name|dir>",
+	Short:        "Unregister a project",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := state.LoadRegistry()
+		if err != nil {
+			return err
+		}
+		removed := reg.Remove(args[0])
+		if abs, err := filepath.Abs(args[0]); !removed && err == nil {
+			removed = reg.Remove(abs)
+		}
+		if !removed {
+			return fmt.Errorf("no registered project %s", args[0])
+		}
+		return reg.Save()
+	},
+}
+
+// globalProjects returns the registered projects that still exist and the
+// directory global mode keeps its state in.
+func globalProjects() (string, []state.Project, error) {
+	reg, err := state.LoadRegistry()
+	if err != nil {
+		return "", nil, err
+	}
+	var projects []state.Project
+	for _, p := range reg.Projects {
+		if info, err := os.Stat(p.Root); err == nil && info.IsDir() {
+			projects = append(projects, p)
+		} else {
+			fmt.Fprintf(os.Stderr, "taskg: skipping %s: %s is gone\n", p.Name, p.Root)
+		}
+	}
+	if len(projects) == 0 {
+		return "", nil, errors.New("no registered projects: add one with taskg projects add")
+	}
+	dir, err := state.GlobalDir()
+	return dir, projects, err
+}
+
+func init() {
+	projectsAddCmd.Flags().StringVar(&projectName, "name", "", "Name to list the project's tasks under (default: its directory name)")
+	projectsCmd.AddCommand(projectsAddCmd, projectsRemoveCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+
+	"taskg/internal/config"
+	"taskg/internal/runner"
+	"taskg/internal/server"
+	"taskg/internal/taskmeta"
+
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "lsp-ish",
+	Short: "Speak JSON-RPC over stdio so editor plugins can list and run tasks",
+	Long: `Speaks JSON-RPC 2.0 on stdin and stdout, with messages framed as in LSP (a Content-Length header, a blank
+line, then the JSON body), so Neovim and VS Code plugins can reuse their LSP client libraries.
+
+Methods:
+  listTasks                       tasks of the nearest Taskfile
+  runTask {name, args, confirm}   start a task and return the run
+  streamOutput {id}               send "output" notifications {id, line} and answer when the run ends
+  cancelRun {id}                  stop a run
+  listRuns                        runs still going and the last 100 finished ones
+  shutdown, exit                  as in LSP
+
+Runs still going when stdin closes are stopped.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadServeConfig()
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("discovery-timeout") {
+			timeout = cfg.DiscoveryTimeout
+		}
+		taskmeta.DiscoveryTimeout = timeout
+		root, err := serveRoot()
+		if err != nil {
+			return err
+		}
+		srv := server.New(root)
+		srv.Mixed = mixed
+		srv.Confirm = cfg.Confirm
+		srv.Policy = cfg.Remote
+		srv.Hooks = cfg.Hooks
+		srv.Telemetry = cfg.Telemetry
+		srv.Scrollback = scrollback
+		defer func() {
+			srv.CancelRuns()
+			srv.WaitRuns()
+		}()
+		return srv.ServeRPC(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rpcCmd.Flags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	rpcCmd.Flags().BoolVar(&mixed, "mixed", false, "Also list Makefile targets and package.json scripts")
+	rpcCmd.Flags().DurationVar(&timeout, "discovery-timeout", config.DefaultDiscoveryTimeout, "Give up on parsing the Taskfile after this long and fall back to reading its YAML")
+	rpcCmd.Flags().IntVar(&scrollback, "scrollback", runner.DefaultScrollback, "Maximum number of output lines kept per run")
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/runner"
+	"taskg/internal/server"
+	"taskg/internal/taskmeta"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr  string
+	serveToken string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the project's tasks over HTTP so they can be listed and run with curl or a dashboard",
+	Long: `Starts an HTTP server exposing the tasks of the nearest Taskfile:
+
+  GET  /tasks            list tasks
+  POST /run/{name}       run a task; needs "Content-Type: application/json", the JSON body
+                         {"args": ["VAR=value"], "confirm": true} is optional
+  GET  /runs             list runs
+  GET  /runs/{id}        status of a run
+  GET  /runs/{id}/logs   output of a run as server-sent events (curl -N)
+
+Anyone who can reach the address can run tasks, so it listens on localhost by default. Set --token
+(or TASKG_SERVE_TOKEN) to require an "Authorization: Bearer <token>" header. Requests from web pages
+of other origins are refused, and so are, without a token, requests for host names other than
+localhost.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadServeConfig()
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("discovery-timeout") {
+			timeout = cfg.DiscoveryTimeout
+		}
+		taskmeta.DiscoveryTimeout = timeout
+		if serveToken == "" {
+			serveToken = os.Getenv("TASKG_SERVE_TOKEN")
+		}
+
+		root, err := serveRoot()
+		if err != nil {
+			return err
+		}
+
+		srv := server.New(root)
+		srv.Mixed = mixed
+		srv.Confirm = cfg.Confirm
+		srv.Policy = cfg.Remote
+		srv.Hooks = cfg.Hooks
+		srv.Telemetry = cfg.Telemetry
+		srv.Token = serveToken
+		srv.Scrollback = scrollback
+		if host, _, err := net.SplitHostPort(serveAddr); err == nil && host == "" && serveToken == "" {
+			fmt.Fprintln(os.Stderr, "taskg: listening on all interfaces without --token: anyone who can reach this machine can run tasks")
+		}
+		hs := &http.Server{Addr: serveAddr, Handler: srv.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			srv.CancelRuns()
+			shutdown, cancel := context.WithTimeout(context.Background(), runner.KillGrace)
+			defer cancel()
+			_ = hs.Shutdown(shutdown)
+		}()
+		fmt.Fprintf(os.Stderr, "Serving tasks of %s on http://%s\n", root, serveAddr)
+		if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		srv.WaitRuns()
+		return nil
+	},
+}
+
+// loadServeConfig loads the config of the commands that let other programs
+// run tasks. Unlike the TUI, they do not go on with the defaults when the
+// config file is broken: the defaults have no remote: policy, so every
+// task would be allowed.
+func loadServeConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("not serving tasks without the remote: policy of the config: %w", err)
+	}
+	return cfg, nil
+}
+
+// serveRoot finds the project to serve from --project or the working
+// directory, like the TUI does on start.
+func serveRoot() (string, error) {
+	startDir := projectDir
+	if startDir == "" {
+		startDir, _ = os.Getwd()
+	}
+	if abs, err := filepath.Abs(startDir); err == nil {
+		startDir = abs
+	}
+	root, err := taskmeta.FindNearestTaskfileRoot(startDir)
+	if err != nil && (mixed || recursive) {
+		// Mixed mode can work from a Makefile or package.json alone, and
+		// recursive mode from Taskfiles that only exist in subdirectories.
+		return startDir, nil
+	}
+	return root, err
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8787", "Address to listen on, e.g. :8787 for all interfaces")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on every request")
+	serveCmd.Flags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	serveCmd.Flags().BoolVar(&mixed, "mixed", false, "Also serve Makefile targets and package.json scripts")
+	serveCmd.Flags().DurationVar(&timeout, "discovery-timeout", config.DefaultDiscoveryTimeout, "Give up on parsing the Taskfile after this long and fall back to reading its YAML")
+	serveCmd.Flags().IntVar(&scrollback, "scrollback", runner.DefaultScrollback, "Maximum number of output lines kept per run")
+	rootCmd.AddCommand(serveCmd)
+}
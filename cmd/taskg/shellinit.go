@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	widgetKey    string
+	widgetHeight string
+)
+
+// keySpecRe accepts ctrl-x / alt-x (also with "+") for a single letter.
+var keySpecRe = regexp.MustCompile(`^(ctrl|alt)[-+]([a-z])$`)
+
+var shellInitCmd = &cobra.Command{
+	Use:       "shell-init {bash|zsh|fish}",
+	Short:     "Print a shell snippet binding a key to open taskg and insert the chosen command",
+	Long:      "Prints a snippet that binds a key (ctrl-t by default) to open taskg inline in print mode and insert the\nresulting task command into the command line buffer, e.g. add to ~/.zshrc:\n\n  eval \"$(taskg shell-init zsh)\"",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := keySpecRe.FindStringSubmatch(strings.ToLower(widgetKey))
+		if m == nil {
+			return fmt.Errorf("unsupported --key %q: use ctrl-<letter> or alt-<letter>", widgetKey)
+		}
+		mod, letter := m[1], m[2]
+		// Single-quoted in the snippets, so keep it free of quotes.
+		if strings.ContainsAny(widgetHeight, `'"`) {
+			return fmt.Errorf("invalid --height %q", widgetHeight)
+		}
+
+		var snippet string
+		switch args[0] {
+		case "zsh":
+			key := "^" + strings.ToUpper(letter)
+			if mod == "alt" {
+				key = `\e` + letter
+			}
+			snippet = fmt.Sprintf(zshWidget, widgetHeight, key)
+		case "bash":
+			key := `\C-` + letter
+			if mod == "alt" {
+				key = `\e` + letter
+			}
+			snippet = fmt.Sprintf(bashWidget, widgetHeight, key)
+		case "fish":
+			key := `\c` + letter
+			if mod == "alt" {
+				key = `\e` + letter
+			}
+			snippet = fmt.Sprintf(fishWidget, widgetHeight, key)
+		default:
+			return fmt.Errorf("unsupported shell %q: use bash, zsh or fish", args[0])
+		}
+		fmt.Fprint(cmd.OutOrStdout(), snippet)
+		return nil
+	},
+}
+
+const zshWidget = `# taskg widget: add to ~/.zshrc with  eval "$(taskg shell-init zsh)"
+taskg-widget() {
+  local cmd
+  cmd="$(taskg --height '%s' < /dev/tty)"
+  local ret=$?
+  if [[ -n $cmd ]]; then
+    LBUFFER="${LBUFFER}${cmd}"
+  fi
+  zle reset-prompt
+  return $ret
+}
+zle -N taskg-widget
+bindkey '%s' taskg-widget
+`
+
+const bashWidget = `# taskg widget: add to ~/.bashrc with  eval "$(taskg shell-init bash)"
+__taskg_widget() {
+  local cmd
+  cmd="$(taskg --height '%s' < /dev/tty)" || return
+  READLINE_LINE="${READLINE_LINE:0:$READLINE_POINT}${cmd}${READLINE_LINE:$READLINE_POINT}"
+  READLINE_POINT=$(( READLINE_POINT + ${#cmd} ))
+}
+bind -m emacs-standard -x '"%s": __taskg_widget'
+`
+
+const fishWidget = `# taskg widget: add to ~/.config/fish/config.fish with  taskg shell-init fish | source
+function __taskg_widget
+  set -l cmd (taskg --height '%s' < /dev/tty)
+  and commandline -i -- $cmd
+  commandline -f repaint
+end
+bind %s __taskg_widget
+`
+
+func init() {
+	shellInitCmd.Flags().StringVar(&widgetKey, "key", "ctrl-t", "Key to bind: ctrl-<letter> or alt-<letter>")
+	shellInitCmd.Flags().StringVar(&widgetHeight, "height", "40%", "Height of the inline picker")
+	rootCmd.AddCommand(shellInitCmd)
+}
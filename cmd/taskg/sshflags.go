@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+
+	"taskg/internal/config"
+	"taskg/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sshAddr           string
+	sshHostKey        string
+	sshAuthorizedKeys string
+)
+
+// sshServeCmd serves the TUI over SSH. The server lives in sshserve.go,
+// which needs the wish module and is only built with -tags wish, so regular
+// builds leave the SSH code out.
+var sshServeCmd = &cobra.Command{
+	Use:   "ssh-serve",
+	Short: "Serve the task TUI over SSH for a shared build box",
+	Long: `Serves the task TUI of the nearest Taskfile over SSH, one session per connection, so a team can run the
+project's tasks on a shared machine without shelling in. Only keys listed in --authorized-keys may connect.
+Tasks run inside the TUI, as with Ctrl+O, and stop when the session ends.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("this taskg was built without SSH support: rebuild it with `go build -tags wish ./cmd/taskg`")
+	},
+}
+
+func init() {
+	sshServeCmd.Flags().StringVar(&sshAddr, "addr", "localhost:23234", "Address to listen on")
+	sshServeCmd.Flags().StringVar(&sshHostKey, "host-key", "", "Host key file, created when missing (default: ssh_host_ed25519 next to the config file)")
+	sshServeCmd.Flags().StringVar(&sshAuthorizedKeys, "authorized-keys", "", "Public keys allowed to connect (default: ~/.ssh/authorized_keys)")
+	sshServeCmd.Flags().StringVar(&projectDir, "project", "", "Start directory for locating nearest Taskfile (defaults to CWD)")
+	sshServeCmd.Flags().StringVar(&theme, "theme", "dark", "Theme: dark or light")
+	sshServeCmd.Flags().BoolVar(&mixed, "mixed", false, "Also discover Makefile targets and package.json scripts")
+	sshServeCmd.Flags().DurationVar(&timeout, "discovery-timeout", config.DefaultDiscoveryTimeout, "Give up on parsing the Taskfile after this long and fall back to reading its YAML")
+	sshServeCmd.Flags().IntVar(&jobs, "jobs", 4, "Maximum number of marked tasks run concurrently per session")
+	sshServeCmd.Flags().IntVar(&scrollback, "scrollback", runner.DefaultScrollback, "Maximum number of output lines kept per run")
+	rootCmd.AddCommand(sshServeCmd)
+}
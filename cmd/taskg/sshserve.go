@@ -0,0 +1,159 @@
+//go:build wish
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"taskg/internal/app"
+	"taskg/internal/config"
+	"taskg/internal/runner"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/spf13/cobra"
+)
+
+// modelKey stores each session's TaskModel in its ssh.Context.
+type modelKey struct{}
+
+func init() {
+	sshServeCmd.RunE = runSSHServe
+}
+
+func runSSHServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadServeConfig()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("discovery-timeout") {
+		timeout = cfg.DiscoveryTimeout
+	}
+	taskmeta.DiscoveryTimeout = timeout
+	root, err := serveRoot()
+	if err != nil {
+		return err
+	}
+	if sshHostKey == "" {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		sshHostKey = filepath.Join(filepath.Dir(path), "ssh_host_ed25519")
+	}
+	if sshAuthorizedKeys == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		sshAuthorizedKeys = filepath.Join(home, ".ssh", "authorized_keys")
+	}
+
+	// Each session gets its own model; tasks run inside the TUI since
+	// nothing can run on the client after it exits.
+	handler := func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+		m := app.NewTaskModel(nil, theme, true, filepath.Base(root))
+		m.SetProjectRoot(root)
+		m.LoadAsync()
+		m.SetMixedBackends(mixed)
+		m.SetConfirmPatterns(cfg.Confirm)
+		m.SetPolicy(cfg.Remote)
+		m.SetShowPlan(cfg.ShowPlan)
+		m.SetHooks(cfg.Hooks)
+		m.SetTelemetry(cfg.Telemetry)
+		m.SetLogs(cfg.Logs)
+		m.SetRetry(cfg.Retry)
+		m.SetHideUnsupported(cfg.HideUnsupported)
+		m.SetGrid(cfg.GridColumns, cfg.GridColumnWidth)
+		m.SetHideCommands(cfg.HideCommands)
+		m.SetPinnedTabs(cfg.PinnedTabs)
+		m.SetHideTabCounts(cfg.HideTabCounts)
+		m.SetTree(cfg.Tree)
+		m.SetIcons(cfg.Icons, cfg.IconMap)
+		m.SetHeader(cfg.Header, cfg.Subheader)
+		m.SetChrome(cfg.HideHeader, cfg.HideFooter, cfg.Borderless)
+		m.SetTypeToSearch(cfg.TypeToSearch)
+		m.SetScrollMargin(cfg.ScrollMargin)
+		m.SetDescLines(cfg.DescLines)
+		m.SetASCII(cfg.ASCII || app.LimitedTerminal(sessionEnv(s)))
+		m.SetReducedMotion(cfg.ReducedMotion)
+		m.SetAccessible(cfg.Accessible)
+		if err := m.SetMaskPatterns(cfg.Mask); err != nil {
+			m.Error(fmt.Sprintf("Bad mask patterns: %v", err))
+		}
+		m.SetScrollback(scrollback)
+		m.SetMaxJobs(jobs)
+		m.SetPTY(true)
+		m.SetInlineOnly(true)
+		s.Context().SetValue(modelKey{}, m)
+		return m, []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	}
+	// Runs started from a session stop when it ends.
+	cancelJobs := func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			next(s)
+			if m, ok := s.Context().Value(modelKey{}).(*app.TaskModel); ok {
+				m.CancelJobs()
+			}
+		}
+	}
+
+	srv, err := wish.NewServer(
+		wish.WithAddress(sshAddr),
+		wish.WithHostKeyPath(sshHostKey),
+		wish.WithAuthorizedKeys(sshAuthorizedKeys),
+		wish.WithMiddleware(
+			bm.Middleware(handler),
+			cancelJobs,
+			activeterm.Middleware(),
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), runner.KillGrace+time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving the tasks of %s over SSH on %s (keys from %s)\n", root, sshAddr, sshAuthorizedKeys)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// sessionEnv looks up the environment of the client of s, with its
+// terminal type as TERM.
+func sessionEnv(s ssh.Session) func(string) string {
+	return func(key string) string {
+		if key == "TERM" {
+			pty, _, _ := s.Pty()
+			return pty.Term
+		}
+		for _, kv := range s.Environ() {
+			if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+				return v
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// clearScreen clears the terminal before the selected task runs.
+func clearScreen() {
+	fmt.Print("\033[H\033[2J")
+}
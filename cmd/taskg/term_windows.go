@@ -0,0 +1,20 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// clearScreen clears the console before the selected task runs. Legacy
+// conhost windows do not interpret ANSI escapes, so ask cmd.exe to do it and
+// only fall back to escapes when that fails.
+func clearScreen() {
+	c := exec.Command("cmd", "/c", "cls")
+	c.Stdout = os.Stdout
+	if err := c.Run(); err != nil {
+		fmt.Print("\033[H\033[2J")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+	"taskg/internal/workspace"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceName     string
+	workspaceParallel bool
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace <task> [VAR=value...]",
+	Short: "Run a task in every project of a workspace",
+	Long: `Runs the task in each project root listed under workspaces: in the config file, one after another or,
+with --parallel, all at once with each output line prefixed by the project. A summary of which projects
+passed, failed or have no such task is printed at the end; the exit status is 1 if any failed.
+
+  # ~/.config/taskg/config.yml
+  workspaces:
+    services: [~/src/api, ~/src/web, ~/src/worker]
+
+  taskg workspace test
+  taskg workspace -w services --parallel lint FIX=true`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, cfgErr := config.Load()
+		if cfgErr != nil {
+			return fmt.Errorf("config: %w", cfgErr)
+		}
+		roots, err := workspaceRoots(cfg.Workspaces)
+		if err != nil {
+			return err
+		}
+		taskmeta.DiscoveryTimeout = cfg.DiscoveryTimeout
+		discover := taskmeta.DiscoverTasks
+		if mixed {
+			discover = taskmeta.DiscoverAll
+		}
+
+		// Ctrl+C stops the run but not taskg, so the summary is printed.
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		defer signal.Stop(sig)
+		cancel := make(chan struct{})
+		go func() {
+			if _, ok := <-sig; ok {
+				close(cancel)
+			}
+		}()
+
+		results := workspace.Run(workspace.Projects(roots), args[0], args[1:], workspace.Options{
+			Parallel: workspaceParallel,
+			Discover: discover,
+			Out:      os.Stdout,
+			Cancel:   cancel,
+		})
+		if workspaceParallel {
+			fmt.Println()
+		}
+		workspace.Summary(os.Stdout, results)
+		select {
+		case <-cancel:
+			os.Exit(130)
+		default:
+		}
+		for _, r := range results {
+			if r.Status == workspace.Failed {
+				os.Exit(1) // the summary says which
+			}
+		}
+		return nil
+	},
+}
+
+// workspaceRoots returns the roots of the workspace named by --workspace,
+// or of the only configured one.
+func workspaceRoots(spaces map[string][]string) ([]string, error) {
+	names := slices.Sorted(maps.Keys(spaces))
+	if len(names) == 0 {
+		return nil, errors.New("no workspaces configured: list project roots under workspaces: in the config file")
+	}
+	name := workspaceName
+	if name == "" {
+		if len(names) > 1 {
+			return nil, fmt.Errorf("pick a workspace with -w: %s", strings.Join(names, ", "))
+		}
+		name = names[0]
+	}
+	roots, ok := spaces[name]
+	if !ok {
+		return nil, fmt.Errorf("no workspace %s; configured: %s", name, strings.Join(names, ", "))
+	}
+	return roots, nil
+}
+
+func init() {
+	workspaceCmd.Flags().StringVarP(&workspaceName, "workspace", "w", "", "Workspace to run in (default: the only configured one)")
+	workspaceCmd.Flags().BoolVar(&workspaceParallel, "parallel", false, "Run in all projects at once")
+	workspaceCmd.Flags().BoolVar(&mixed, "mixed", false, "Also look for the task among Makefile targets and package.json scripts")
+	rootCmd.AddCommand(workspaceCmd)
+}
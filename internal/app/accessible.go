@@ -0,0 +1,48 @@
+package app
+
+import "taskg/internal/styles"
+
+// Accessible mode (--accessible, or accessible: true in the config file)
+// renders for screen readers and braille displays: no boxes or logo, ASCII
+// only, and every task on a line of its own that starts with a plain marker,
+// ">" for the selection, "*" for marked tasks and "-" for the others.
+
+// SetAccessible turns accessible rendering on or off.
+func (m *TaskModel) SetAccessible(enabled bool) {
+	m.accessible = enabled
+	if enabled {
+		m.SetASCII(true)
+		m.SetReducedMotion(true)
+	}
+	m.setTheme(m.themeName)
+}
+
+// setTheme switches to the theme called name, without boxes in accessible
+// mode and without the frame when borders are hidden.
+func (m *TaskModel) setTheme(name string) {
+	m.themeName = name
+	m.theme = styles.ByName(name)
+	if m.accessible {
+		m.theme = styles.Plain(m.theme)
+	} else if m.borderless {
+		m.theme = styles.Frameless(m.theme)
+	}
+	m.itemHeight = 0 // re-measure with the new styles
+	m.invalidateRows()
+}
+
+// selectionBar is the mark left of the selected row.
+func (m TaskModel) selectionBar() string {
+	if m.accessible {
+		return ">"
+	}
+	return "▎"
+}
+
+// rowMarker is the bullet of the task identified by key in accessible mode.
+func (m TaskModel) rowMarker(key string) string {
+	if m.marked[key] {
+		return "*"
+	}
+	return "-"
+}
@@ -1,19 +1,27 @@
 package app
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
+	"taskg/internal/config"
+	"taskg/internal/state"
 	"taskg/internal/styles"
 	"taskg/internal/taskmeta"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // Model: TaskModel represents the TUI state for browsing Taskfile tasks.
@@ -27,17 +35,18 @@ type TaskModel struct {
 	searchMode    bool
 	searchQuery   string
 	searchInput   textinput.Model
+	searchGlobal  bool // search all tabs instead of the active one (ctrl+g)
 	theme         styles.Theme
 	mouseEnabled  bool
 	width         int
 	height        int
 	lastCommand   []string // Can now hold command and args
-	statusMessage string
-	statusTimeout time.Time
+	runTarget     taskmeta.Task
+	toasts        []toast // status messages, see toast.go
 	projectName   string
 	projectRoot   string // for refresh functionality
 	errorMessage  string
-	// favorites placeholders
+	// favorites are the starred tasks by taskKey (see contextmenu.go)
 	favorites       map[string]bool
 	quitAfterSelect bool
 	// tab scroll state
@@ -48,11 +57,57 @@ type TaskModel struct {
 	listOffset int
 	// cached dynamic measurements
 	itemHeight int // includes trailing spacing newline after each item
+	render     *renderCache
+	index      *searchIndex // built lazily over tasks, see searchindex.go
+	filterSeq  int          // latest debounced filter request
+	// hideCmds leaves out the command line of rows (see cmdpreview.go)
+	hideCmds bool
+	// neverRun narrows the list to tasks with no run history (see lastrun.go)
+	neverRun bool
+	// hideHeader, hideFooter and borderless reclaim rows (see chrome.go)
+	hideHeader, hideFooter, borderless bool
+	// scrollMargin is how many rows are kept above and below the selection
+	scrollMargin int
+	// descLines is how many lines the task text of a row wraps to at most
+	descLines int
+	// cmdViewTask is the task whose command line is scrolled by cmdScroll
+	// columns, or expanded (see cmdview.go)
+	cmdViewTask string
+	cmdScroll   int
+	cmdExpanded bool
+	// typeToSearch is what typing a letter in the list does (see search.go)
+	typeToSearch string
+	// clickRow and clickAt tell a double click from two clicks
+	clickRow int
+	clickAt  time.Time
+	// ascii draws with ASCII characters only (see ascii.go)
+	ascii bool
+	// accessible renders plain lines of text for screen readers (see accessible.go)
+	accessible bool
+	// reducedMotion stills animations (see ticker.go)
+	reducedMotion bool
+	// tickAt is when the pending tick fires, zero when none is (see ticker.go)
+	tickAt time.Time
+	// icons maps categories and words of task names to icons, nil when off (see icons.go)
+	icons map[string]string
+	// header and subheader templates and the details they show (see header.go)
+	headerTmpl, subheaderTmpl *template.Template
+	gitBranch, taskVersion    string
 	// tab-related state
 	tabs      []string                   // list of tab names (prefixes + "main")
 	activeTab string                     // currently active tab name
+	pendingUI *state.UIState             // saved tab and selection, applied once tasks load
 	tabTasks  map[string][]taskmeta.Task // tasks grouped by tab
-	sortMode  string                     // "file" or "alpha"
+	sortMode  string                     // "file", "alpha" or "smart" (frecency)
+	masker    *masker                    // redacts secrets in command previews and output
+	// confirm prompt for dangerous tasks (see confirm.go)
+	confirmPatterns []string    // task name patterns that ask before running
+	showPlan        bool        // runs of tasks with deps show their plan first
+	pendingRun      *pendingRun // run waiting for y/N, shown as a dialog
+	// mixedBackends enables Makefile/package.json discovery next to the Taskfile.
+	mixedBackends bool
+	// policy limits the tasks remote sessions may run (see policy.go), nil for all.
+	policy *config.Policy
 
 	// Modal state for tasks that require variables
 	modalMode      bool
@@ -60,12 +115,179 @@ type TaskModel struct {
 	modalVariables []struct {
 		Name         string
 		DefaultValue string
+		Choices      []string // allowed values, shown as a select (see choices.go)
+		Choice       int
 	}
 	modalFocused int
 	modalError   error
-}
 
-type tickMsg time.Time
+	// Preset menu and naming state (see presets.go)
+	presetMode     bool
+	presetSelected int
+	presetNaming   bool
+	presetName     textinput.Model
+	presetEditing  string // preset whose values the form was opened with
+
+	// Env file picker state (see envfiles.go)
+	envFileMode     bool
+	envFileList     []string
+	envFileSelected int
+
+	// Run options popup (see runopts.go)
+	runOptsMode     bool
+	runOptsSelected int
+	runOpts         map[string]bool // toggled Task flags
+	runFlags        []string        // flags for the run being started, then any "--" CLI_ARGS
+
+	// Env override editor state (see env.go)
+	envMode      bool
+	envTask      string
+	envInputs    []textinput.Model
+	envFocused   int
+	envRemember  bool
+	envError     string
+	envOverrides map[string]map[string]string // session overrides keyed by task name
+	runEnv       []string                     // overrides applied to the task chosen for execution
+
+	// direnv environment of the project root (see direnv.go)
+	direnvRoot string // root the variables were exported for, "" when not loaded
+	direnvEnv  []string
+
+	// Task creation form state (see create.go)
+	createMode    bool
+	createInputs  []textinput.Model
+	createFocused int
+	createError   string
+	startDir      string // where a Taskfile is created when none was found
+
+	duplicates  map[string]bool         // keys of tasks listed more than once, see duplicates.go
+	taskfileErr *taskmeta.TaskfileError // position of the last discovery's parse error, see taskfileerr.go
+	// hideUnsupported drops tasks not meant for this OS/arch (see platforms.go)
+	hideUnsupported bool
+	// browseSource is the repository shown read-only (see browse.go)
+	browseSource string
+
+	// Task YAML editor state (see edit.go)
+	editMode  bool
+	editTask  taskmeta.Task
+	editPath  string
+	editName  string // name of editTask in editPath, without include namespaces
+	editInput textarea.Model
+	editError string
+
+	// Note editor state (see notes.go)
+	noteMode  bool
+	noteTask  taskmeta.Task
+	noteInput textarea.Model
+
+	// Detail pane (see detail.go)
+	showDetail   bool
+	summaries    map[string]summaryEntry  // `task --summary` cache keyed by taskKey
+	compiled     map[string]compiledEntry // tasks compiled for env and rendered cmds, keyed by taskKey (see compiled.go)
+	renderedCmds bool                     // the detail pane shows commands with templates rendered
+	detailOffset int                      // first detail line shown, for detailTask
+	detailTask   string                   // taskKey the detail pane was scrolled for
+	// showYAML shows the task's Taskfile YAML instead (see yamlview.go)
+	showYAML   bool
+	yamlBodies map[string]yamlEntry
+
+	// taskStatus caches `task --status` results keyed by taskKey (see status.go)
+	taskStatus map[string]upToDate
+
+	// state is the per-project local state (.taskg/state.json)
+	state *state.State
+	// trustedRemotes are the remote Taskfiles trusted per project (see
+	// remote.go)
+	trustedRemotes *state.Trust
+
+	// Grid layout of wide terminals (see grid.go)
+	gridColumnsMax  int
+	gridColumnWidth int
+
+	// Embedded runner and output pane (see output.go)
+	outputMode bool
+	out        outputPane
+	jobs       []*job // current run group shown in the output pane
+	maxJobs    int
+	scrollback int
+	usePTY     bool
+	marked     map[string]bool // multi-selection for parallel runs, keyed by taskKey
+	runInline  bool            // the pending execution runs inside the TUI instead of after exit
+	inlineOnly bool            // every run stays inside the TUI, see SetInlineOnly
+	hooks      []config.Hook   // webhooks called after each run (see hooks.go)
+	logs       config.Logs     // run log files (see logs.go)
+	retry      config.Retry    // default retry policy (see retry.go)
+	repeat     repeatState     // re-run schedule of the output pane (see repeat.go)
+	// telemetry exports each run as a trace (see telemetry.go)
+	telemetry config.Telemetry
+
+	// External run target (see spawn.go)
+	runIn          string // configured run_in preset or template
+	runSpawn       string // target the pending execution opens in, "" for none
+	spawnRequested bool   // Alt+Enter asked for the pending execution to open in a new pane
+
+	// Container runs (see container.go)
+	containers         map[string]config.Container // keyed by project root
+	runContainer       bool                        // the pending execution runs in its project's container
+	containerRequested bool                        // Alt+C asked for the pending execution to run in a container
+
+	// Global mode lists the tasks of these registered projects (see global.go)
+	global []state.Project
+
+	// Recursive (monorepo) mode: subprojects shown as tabs or as a column
+	recursive     bool
+	projectLayout string
+
+	// Inline (no alt-screen) operation, see inline.go
+	inline        bool
+	heightLines   int
+	heightPercent int
+	quitting      bool
+
+	// Project switcher (see projects.go)
+	projectPicker   bool
+	projectChoices  []string
+	projectSelected int
+
+	// Tab overflow menu (see tabmenu.go)
+	tabMenu         bool
+	tabMenuSelected int
+	// configPins are the tabs pinned by the config file (see taborder.go)
+	configPins []string
+	// hideTabCounts leaves the task counts out of tab labels (see tabbadges.go)
+	hideTabCounts bool
+	// Tree layout: one list with a section per tab (see tree.go)
+	tree      bool
+	collapsed map[string]bool
+
+	// Context menu of the selected task (see contextmenu.go)
+	ctxMenu     bool
+	ctxSelected int
+	ctxArgsMode bool // asking for the arguments of "Run with arguments"
+	ctxArgs     textinput.Model
+
+	// Go-to mode: type-ahead jumping to a task (see goto.go)
+	gotoMode  bool
+	gotoQuery string
+	gotoMiss  bool // no task starts with gotoQuery
+
+	// Command palette and run history overlay (see palette.go)
+	paletteMode     bool
+	paletteInput    textinput.Model
+	paletteSelected int
+	historyMode     bool
+	historySelected int
+	themeName       string
+
+	// groupBy is the tab grouping strategy, see grouping.go
+	groupBy string
+
+	// Initial discovery running in the background (see loading.go)
+	loading bool
+	spinner spinner.Model
+	// discoveryWarning explains incomplete results of the last discovery
+	discoveryWarning string
+}
 
 // refreshMsg is sent when task refresh is complete
 type refreshMsg struct {
@@ -74,14 +296,11 @@ type refreshMsg struct {
 }
 
 func NewTaskModel(tasks []taskmeta.Task, themeName string, mouseEnabled bool, projectName string) *TaskModel {
-	theme := styles.NewDarkTheme()
-	if themeName == "light" {
-		theme = styles.NewLightTheme()
-	}
+	theme := styles.ByName(themeName)
 
 	// Sort tasks by line number to preserve order from Taskfile
 	sort.SliceStable(tasks, func(i, j int) bool {
-		return tasks[i].Line < tasks[j].Line
+		return fileOrderLess(tasks[i], tasks[j])
 	})
 
 	// Make a copy of the original tasks to restore sorting
@@ -93,13 +312,23 @@ func NewTaskModel(tasks []taskmeta.Task, themeName string, mouseEnabled bool, pr
 		originalTasks: originalTasks,
 		filteredTasks: tasks,
 		theme:         theme,
+		themeName:     themeName,
 		mouseEnabled:  mouseEnabled,
-		statusTimeout: time.Now(),
 		projectName:   projectName,
 		favorites:     make(map[string]bool),
 		tabTasks:      make(map[string][]taskmeta.Task),
 		sortMode:      "file", // default to file order
+		groupBy:       config.GroupPrefix,
 		lastCommand:   []string{},
+		envOverrides:  make(map[string]map[string]string),
+		marked:        make(map[string]bool),
+		taskStatus:    make(map[string]upToDate),
+		summaries:     make(map[string]summaryEntry),
+		compiled:      make(map[string]compiledEntry),
+		yamlBodies:    make(map[string]yamlEntry),
+		collapsed:     make(map[string]bool),
+		state:         &state.State{},
+		render:        newRenderCache(),
 	}
 	ti := textinput.New()
 	ti.Placeholder = "Type to filter tasks"
@@ -107,20 +336,82 @@ func NewTaskModel(tasks []taskmeta.Task, themeName string, mouseEnabled bool, pr
 	ti.Width = 40
 	ti.Prompt = "🔍 "
 	m.searchInput = ti
-	m.buildTabs()    // Build tabs from tasks
-	m.updateFilter() // Apply initial filter
+	oi := textinput.New()
+	oi.Placeholder = "Search output"
+	oi.CharLimit = 128
+	oi.Width = 40
+	oi.Prompt = "🔍 "
+	m.out.search = oi
+	m.SetHeader("", "")
+	m.masker, _ = newMasker(nil) // the built-in patterns always compile
+	m.buildTabs()                // Build tabs from tasks
+	m.updateFilter()             // Apply initial filter
 	return m
 }
 
 // Error sets a persistent empty-state error message.
 func (m *TaskModel) Error(msg string) { m.errorMessage = msg }
 
-// SetProjectRoot sets the project root for refresh functionality
-func (m *TaskModel) SetProjectRoot(root string) { m.projectRoot = root }
+// SetProjectRoot sets the project root for refresh functionality, loads
+// the project's local state and records it in the recent projects list.
+func (m *TaskModel) SetProjectRoot(root string) {
+	m.projectRoot = root
+	if err := rememberProject(root); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save recent projects: %v", err))
+	}
+	m.loadState(root)
+}
 
-func (m TaskModel) Init() tea.Cmd { return tickCmd() }
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Millisecond*200, func(t time.Time) tea.Msg { return tickMsg(t) })
+// loadState loads the local state kept in root.
+func (m *TaskModel) loadState(root string) {
+	st, err := state.Load(root)
+	if err != nil {
+		m.setWarning(fmt.Sprintf("Could not read state: %v", err))
+	}
+	m.state = st
+	if m.trustedRemotes, err = state.LoadTrust(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not read trusted Taskfiles: %v", err))
+	}
+	for name, env := range st.Env {
+		m.envOverrides[name] = env
+	}
+	m.favorites = make(map[string]bool)
+	for _, key := range st.Favorites {
+		m.favorites[key] = true
+	}
+	m.loadUIState()
+}
+
+// ProjectRoot returns the current project root, which changes when the user
+// switches projects from the picker.
+func (m TaskModel) ProjectRoot() string { return m.projectRoot }
+
+// SetMixedBackends makes refresh use every backend provider instead of only the Taskfile.
+func (m *TaskModel) SetMixedBackends(enabled bool) { m.mixedBackends = enabled }
+
+// SetRecursive makes refresh scan subdirectories for further Taskfiles and
+// sets how subprojects are presented (config.LayoutTabs or config.LayoutColumn).
+func (m *TaskModel) SetRecursive(enabled bool, layout string) {
+	m.recursive = enabled
+	m.projectLayout = layout
+	m.invalidateRows()
+	m.buildTabs()
+	m.updateFilter()
+}
+
+// usingBuiltinRunner reports whether Taskfile tasks run through the limited
+// built-in runner because the task binary is missing.
+func (m TaskModel) usingBuiltinRunner() bool {
+	for _, t := range m.originalTasks {
+		if t.Script != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *TaskModel) Init() tea.Cmd {
+	return tea.Batch(m.tickCmd(), m.loadingCmds(), m.direnvCmd(), m.headerCmd())
 }
 
 func (m *TaskModel) refreshCmd() tea.Cmd {
@@ -128,17 +419,64 @@ func (m *TaskModel) refreshCmd() tea.Cmd {
 		if m.projectRoot == "" {
 			return refreshMsg{nil, fmt.Errorf("no project root set")}
 		}
-		tasks, err := taskmeta.DiscoverTasks(m.projectRoot)
+		if m.browsing() {
+			tasks, err := taskmeta.BrowseTasks(m.projectRoot)
+			return refreshMsg{tasks, err}
+		}
+		discover := taskmeta.DiscoverTasks
+		if m.mixedBackends {
+			discover = taskmeta.DiscoverAll
+		}
+		// Listed first, so a project added while discovering shows up
+		// next time instead of being cached as covered.
+		dirs := m.projectDirs()
+		var tasks []taskmeta.Task
+		var err error
+		if m.globalMode() {
+			tasks, err = m.discoverGlobal(discover)
+		} else if m.recursive {
+			tasks, err = taskmeta.DiscoverRecursive(m.projectRoot, discover)
+		} else {
+			tasks, err = discover(m.projectRoot)
+		}
+		if err == nil {
+			// Best effort: a failed write only costs the next startup.
+			_ = taskmeta.SaveCache(m.projectRoot, m.discoveryMode(), dirs, tasks)
+		}
 		return refreshMsg{tasks, err}
 	}
 }
 
 func (m *TaskModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	m.resetCmdView()
+	// Lazily check up-to-date status for whatever became visible, and fetch
+	// the summary of the selected task for the detail pane.
+	if checks := m.statusCheckCmds(); checks != nil {
+		cmd = tea.Batch(cmd, checks)
+	}
+	if summary := m.summaryCmd(); summary != nil {
+		cmd = tea.Batch(cmd, summary)
+	}
+	if compile := m.compileCmd(); compile != nil {
+		cmd = tea.Batch(cmd, compile)
+	}
+	if body := m.yamlCmd(); body != nil {
+		cmd = tea.Batch(cmd, body)
+	}
+	if tick := m.tickCmd(); tick != nil {
+		cmd = tea.Batch(cmd, tick)
+	}
+	return model, cmd
+}
+
+func (m *TaskModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
-		m.height = msg.Height
+		m.height = m.limitHeight(msg.Height)
 		m.ensureSelectionVisible()
+		m.resizeJobs()
 	case tea.KeyMsg:
 		return m.handleKeys(msg)
 	case tea.MouseMsg:
@@ -147,23 +485,172 @@ func (m *TaskModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m.handleMouse(msg)
 	case tickMsg:
-		return m, tickCmd()
-	case refreshMsg:
+		m.handleTick(time.Time(msg))
+		return m, nil
+	case runEventsMsg:
+		return m, m.handleRunEvents(msg)
+	case retryMsg:
+		return m, m.handleRetry(msg)
+	case repeatMsg:
+		return m, m.handleRepeat(msg)
+	case statusResultMsg:
+		m.handleStatusResult(msg)
+		return m, nil
+	case summaryMsg:
+		m.summaries[msg.key] = summaryEntry{text: msg.text, err: msg.err}
+		return m, nil
+	case compiledMsg:
+		m.compiled[msg.key] = compiledEntry{task: msg.task, err: msg.err}
+		return m, nil
+	case yamlMsg:
+		m.yamlBodies[msg.key] = yamlEntry{body: msg.body, err: msg.err}
+		return m, nil
+	case pagerClosedMsg:
+		m.handlePagerClosed(msg)
+		return m, nil
+	case hookMsg:
+		m.handleHook(msg)
+		return m, nil
+	case telemetryMsg:
+		m.handleTelemetry(msg)
+		return m, nil
+	case direnvMsg:
+		m.handleDirenv(msg)
+		return m, nil
+	case headerMsg:
+		m.handleHeader(msg)
+		return m, nil
+	case spawnedMsg:
+		m.handleSpawned(msg)
+		return m, nil
+	case configEditedMsg:
 		if msg.err != nil {
-			m.setStatus(fmt.Sprintf("Refresh failed: %v", msg.err))
+			m.setError(fmt.Sprintf("Editor failed: %v", msg.err))
 		} else {
-			m.tasks = msg.tasks
-			m.buildTabs() // Rebuild tabs after refresh
+			m.setStatus("Config saved - restart taskg to apply it")
+		}
+		return m, nil
+	case filterMsg:
+		if msg.seq == m.filterSeq {
 			m.updateFilter()
-			m.setStatus(fmt.Sprintf("Refreshed - %d tasks found", len(msg.tasks)))
+		}
+		return m, nil
+	case spinner.TickMsg:
+		return m, m.updateSpinner(msg)
+	case taskfileEditedMsg:
+		if msg.err != nil {
+			m.setError(fmt.Sprintf("Editor failed: %v", msg.err))
+			return m, nil
+		}
+		m.setStatus("Refreshing tasks...")
+		return m, m.refreshCmd()
+	case refreshMsg:
+		initial := m.loading
+		m.loading = false
+		var partial *taskmeta.PartialError
+		if errors.As(msg.err, &partial) {
+			// Usable tasks from a fallback source: show them with a warning.
+			m.discoveryWarning = "⚠ " + partial.Error()
+			msg.err = nil
+		} else if msg.err == nil {
+			m.discoveryWarning = ""
+		}
+		m.taskfileErr = nil
+		errors.As(msg.err, &m.taskfileErr)
+		if msg.err != nil {
+			m.setError(fmt.Sprintf("Refresh failed: %v", msg.err))
+			if len(m.tasks) == 0 {
+				m.errorMessage = fmt.Sprintf("Failed to enumerate tasks: %v", msg.err)
+				if e := m.taskfileErr; e != nil {
+					m.errorMessage = "Invalid Taskfile: " + strings.Replace(e.Error(), e.File, m.relPath(e.File), 1)
+				}
+			}
+		} else {
+			m.setTasks(msg.tasks)
+			if !initial {
+				m.setStatus(fmt.Sprintf("Refreshed - %d tasks found", len(msg.tasks)))
+			}
 		}
 		return m, nil
 	}
 	return m, nil
 }
 
+// setTasks replaces the task list after discovery.
+func (m *TaskModel) setTasks(tasks []taskmeta.Task) {
+	m.tasks = m.supportedTasks(tasks)
+	sort.SliceStable(m.tasks, func(i, j int) bool {
+		return fileOrderLess(m.tasks[i], m.tasks[j])
+	})
+	m.originalTasks = make([]taskmeta.Task, len(m.tasks))
+	copy(m.originalTasks, m.tasks)
+	m.taskStatus = make(map[string]upToDate)
+	m.summaries = make(map[string]summaryEntry)
+	m.compiled = make(map[string]compiledEntry)
+	m.yamlBodies = make(map[string]yamlEntry)
+	m.invalidateRows() // the project column width depends on all tasks
+	m.index = nil
+	m.duplicates = duplicateKeys(m.tasks)
+	m.buildTabs() // Rebuild tabs after refresh
+	m.updateFilter()
+	m.restoreUIState()
+	if len(m.tasks) == 0 {
+		m.errorMessage = "No tasks discovered in Taskfile."
+	} else {
+		m.errorMessage = ""
+	}
+}
+
 func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pendingRun != nil {
+		return m.handleConfirmKeys(msg)
+	}
+	if m.envMode {
+		return m.handleEnvKeys(msg)
+	}
+	if m.noteMode {
+		return m.handleNoteKeys(msg)
+	}
+	if m.createMode {
+		return m.handleCreateKeys(msg)
+	}
+	if m.editMode {
+		return m.handleEditKeys(msg)
+	}
+	if m.projectPicker {
+		return m.handleProjectKeys(msg)
+	}
+	if m.tabMenu {
+		return m.handleTabMenuKeys(msg)
+	}
+	if m.ctxMenu {
+		return m.handleContextKeys(msg)
+	}
+	if m.gotoMode {
+		return m.handleGotoKeys(msg)
+	}
+	if m.paletteMode {
+		return m.handlePaletteKeys(msg)
+	}
+	if m.historyMode {
+		return m.handleHistoryKeys(msg)
+	}
+	if m.outputMode && !m.modalMode {
+		return m.handleOutputKeys(msg)
+	}
+	if m.presetMode {
+		return m.handlePresetKeys(msg)
+	}
+	if m.runOptsMode {
+		return m.handleRunOptionsKeys(msg)
+	}
+	if m.envFileMode {
+		return m.handleEnvFileKeys(msg)
+	}
 	if m.modalMode {
+		if m.presetNaming {
+			return m.handlePresetNameKeys(msg)
+		}
 		// In modal mode, handle input fields
 		switch msg.String() {
 		case "esc":
@@ -171,13 +658,10 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case "enter":
 			// Submit and run task
-			args := []string{m.filteredTasks[m.selected].Name}
-			for i, v := range m.modalVariables {
-				args = append(args, fmt.Sprintf("%s=%s", v.Name, m.modalInputs[i].Value()))
-			}
-			m.lastCommand = args
-			m.quitAfterSelect = true
-			return m, tea.Quit
+			m.modalMode = false
+			return m, m.execute(m.filteredTasks[m.selected], m.modalArgs())
+		case "ctrl+s":
+			return m, m.startPresetNaming()
 		case "tab":
 			// Switch focus
 			m.modalInputs[m.modalFocused].Blur()
@@ -194,6 +678,10 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, textinput.Blink
 		}
 
+		if m.isChoice(m.modalFocused) {
+			m.handleChoiceKey(m.modalFocused, msg.String())
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.modalInputs[m.modalFocused], cmd = m.modalInputs[m.modalFocused].Update(msg)
 		return m, cmd
@@ -205,24 +693,18 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// input component for normal editing.
 		switch msg.String() {
 		case "up", "k":
-			if m.selected > 0 {
-				m.selected--
-				m.ensureSelectionVisible()
-			}
+			m.moveRow(-1)
 			return m, nil
 		case "down", "j":
-			if m.selected < len(m.filteredTasks)-1 {
-				m.selected++
-				m.ensureSelectionVisible()
-			}
+			m.moveRow(1)
 			return m, nil
 		case "pgup":
-			step := m.visibleListHeight()
+			step := m.visibleTaskCount()
 			m.selected = max(0, m.selected-step)
 			m.ensureSelectionVisible()
 			return m, nil
 		case "pgdown":
-			step := m.visibleListHeight()
+			step := m.visibleTaskCount()
 			m.selected = min(len(m.filteredTasks)-1, m.selected+step)
 			m.ensureSelectionVisible()
 			return m, nil
@@ -234,12 +716,17 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selected = len(m.filteredTasks) - 1
 			m.ensureSelectionVisible()
 			return m, nil
+		case "ctrl+g":
+			m.toggleSearchScope()
+			return m, nil
 		}
 
 		var cmd tea.Cmd
 		m.searchInput, cmd = m.searchInput.Update(msg)
-		m.searchQuery = m.searchInput.Value()
-		m.updateFilter()
+		if m.searchInput.Value() != m.searchQuery {
+			m.searchQuery = m.searchInput.Value()
+			cmd = tea.Batch(cmd, m.filterSoon())
+		}
 		if msg.String() == "esc" {
 			m.searchMode = false
 			m.searchInput.Reset()
@@ -247,59 +734,121 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.updateFilter()
 		}
 		if msg.String() == "enter" {
+			m.updateFilter() // don't run from a list a pending filter would change
 			m.searchMode = false
+			if i := m.exactMatch(); i >= 0 {
+				m.selected = i
+			}
 			// If there are filtered tasks, execute the selected one
 			if len(m.filteredTasks) > 0 {
-				return m, m.markForExecution()
+				return m, m.markForExecution(false)
 			}
 		}
 		return m, cmd
 	}
 
+	// A paste arrives whole (bracketed paste) and lands in the search box,
+	// so none of its characters is taken for a key.
+	if msg.Paste {
+		m.startSearch(string(msg.Runes))
+		return m, nil
+	}
+
 	// Auto-activate search mode when the user types a printable character
-	// that is not already a single-key command (navigation or quit).
+	// that type_to_search does not keep for a single-key command.
 	// This enables "type-to-search" UX.
 	if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
 		r := msg.Runes[0]
-		// Reserved single-letter keys we don\'t want to hijack for search.
-		// q: quit, j/k: navigation, r: refresh.
-		if r != 'q' && r != 'j' && r != 'k' && r != 'r' && unicode.IsPrint(r) && !unicode.IsSpace(r) {
-			m.searchMode = true
-			m.searchInput.Focus()
-			m.searchInput.SetValue(string(r))
-			m.searchQuery = m.searchInput.Value()
-			m.updateFilter()
+		// Digits run one of the first visible tasks instead of searching.
+		if r >= '1' && r <= '0'+maxHotkeys {
+			return m, m.runHotkey(int(r - '0'))
+		}
+		if m.typeStartsSearch(r) {
+			m.startSearch(string(r))
 			return m, nil
 		}
 	}
 
+	if m.handleTreeKeys(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
+	case "ctrl+e":
+		return m, m.openEnvEditor()
+	case "ctrl+n":
+		return m, m.openNoteEditor()
+	case "ctrl+t":
+		return m, m.openCreateForm()
+	case "f2":
+		return m, m.openTaskEditor()
+	case "f3":
+		m.toggleRenderedCmds()
+	case "f4":
+		return m, m.openTaskfileError()
+	case "alt+p":
+		m.toggleCommandLines()
+	case "alt+h":
+		m.toggleHeader()
+	case "alt+f":
+		m.toggleFooter()
+	case "alt+b":
+		m.toggleBorders()
+	case "alt+y":
+		m.toggleYAML()
+	case "alt+t":
+		m.openTabMenu()
+	case "m", "alt+m":
+		m.openContextMenu()
+	case "x", "alt+x":
+		m.toggleExpandCmds()
+	case "shift+left", "alt+left":
+		m.scrollCmds(-cmdScrollStep)
+	case "shift+right", "alt+right":
+		m.scrollCmds(cmdScrollStep)
+	case "'", ";":
+		m.openGoto()
+	case "ctrl+shift+left":
+		m.moveTab(-1)
+	case "ctrl+shift+right":
+		m.moveTab(1)
+	case "ctrl+shift+up":
+		m.togglePinTab()
+	case "ctrl+p":
+		m.openProjectPicker()
+	case "ctrl+k":
+		return m, m.openPalette()
+	case "ctrl+f":
+		m.openRunOptions()
+	case "ctrl+g":
+		m.toggleSearchScope()
+	case "ctrl+d":
+		m.showDetail = !m.showDetail
+		m.ensureSelectionVisible()
+	case "shift+down", "shift+up":
+		if m.showDetail {
+			m.scrollDetail(msg.String() == "shift+down")
+		}
 	case "ctrl+s":
 		m.toggleSortMode()
 		m.setStatus(fmt.Sprintf("Sorted by %s", m.sortMode))
 		return m, nil
-	case "q", "ctrl+c":
-		return m, tea.Quit
+	case "q", "ctrl+q", "ctrl+c":
+		return m, m.quit()
 	case "r", "ctrl+r":
-		// Start refresh operation
+		// Start refresh operation; the .envrc may have changed too
 		m.setStatus("Refreshing tasks...")
-		return m, m.refreshCmd()
+		return m, tea.Batch(m.refreshCmd(), m.direnvCmd(), m.headerCmd())
 	case "up", "k":
-		if m.selected > 0 {
-			m.selected--
-			m.ensureSelectionVisible()
-		}
+		m.moveRow(-1)
 	case "down", "j":
-		if m.selected < len(m.filteredTasks)-1 {
-			m.selected++
-			m.ensureSelectionVisible()
-		}
+		m.moveRow(1)
 	case "pgup":
-		step := m.visibleListHeight()
+		step := m.visibleTaskCount()
 		m.selected = max(0, m.selected-step)
 		m.ensureSelectionVisible()
 	case "pgdown":
-		step := m.visibleListHeight()
+		step := m.visibleTaskCount()
 		m.selected = min(len(m.filteredTasks)-1, m.selected+step)
 		m.ensureSelectionVisible()
 	case "home":
@@ -309,7 +858,63 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.selected = len(m.filteredTasks) - 1
 		m.ensureSelectionVisible()
 	case "enter":
-		return m, m.markForExecution()
+		return m, m.markForExecution(false)
+	case "alt+enter":
+		return m, m.markForSpawn()
+	case "alt+c":
+		return m, m.markForContainer()
+	case "ctrl+o":
+		// Run inside the TUI, streaming output to the output pane. With
+		// marked tasks, run all of them in parallel instead.
+		if marked := m.markedTasks(); len(marked) > 0 {
+			jobs := make([]*job, len(marked))
+			for i, t := range marked {
+				jobs[i] = &job{task: t}
+			}
+			for _, t := range marked {
+				if m.refusePolicy(t) {
+					return m, nil
+				}
+				if m.needsTrust(t) != "" {
+					m.setError(fmt.Sprintf("Run %s on its own first to trust its remote Taskfile", t.Name))
+					return m, nil
+				}
+			}
+			m.marked = make(map[string]bool)
+			if len(m.promptedJobs(jobs)) > 0 {
+				m.pendingRun = &pendingRun{task: jobs[0].task, jobs: jobs, inline: true}
+				return m, nil
+			}
+			return m, m.startRuns(jobs)
+		}
+		return m, m.markForExecution(true)
+	case " ":
+		// Toggle the multi-selection mark on the current task
+		if len(m.filteredTasks) > 0 && !m.refuseReadOnly() {
+			if t := m.filteredTasks[m.selected]; !t.Supported() {
+				m.setError(unsupportedError(t))
+				break
+			} else if err := taskmeta.ShellError(t); err != nil {
+				m.setError(err.Error())
+				break
+			} else if m.refusePolicy(t) {
+				break
+			}
+			key := taskKey(m.filteredTasks[m.selected])
+			m.marked[key] = !m.marked[key]
+			if !m.marked[key] {
+				delete(m.marked, key)
+			}
+			if m.selected < len(m.filteredTasks)-1 {
+				m.selected++
+				m.ensureSelectionVisible()
+			}
+		}
+	case "ctrl+l":
+		// Reopen the output pane of the last in-TUI run
+		if len(m.jobs) > 0 {
+			m.outputMode = true
+		}
 	case "/":
 		m.searchMode = true
 		m.searchInput.Focus()
@@ -321,7 +926,7 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.updateFilter()
 		} else {
 			// If no search query to clear, quit the app
-			return m, tea.Quit
+			return m, m.quit()
 		}
 	case "tab":
 		// Move to next tab
@@ -334,13 +939,13 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.moveToPrevTab()
 		}
 	case "left":
-		// Move to previous tab
-		if len(m.tabs) > 1 {
+		// Move left in the grid, or to the previous tab
+		if !m.moveInRow(-1) && len(m.tabs) > 1 {
 			m.moveToPrevTab()
 		}
 	case "right":
-		// Move to next tab
-		if len(m.tabs) > 1 {
+		// Move right in the grid, or to the next tab
+		if !m.moveInRow(1) && len(m.tabs) > 1 {
 			m.moveToNextTab()
 		}
 	}
@@ -349,151 +954,284 @@ func (m *TaskModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // Legacy view handlers removed.
 
+// doubleClickTime is how soon a second click on a task must follow the
+// first to run it.
+const doubleClickTime = 400 * time.Millisecond
+
 func (m *TaskModel) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.MouseLeft:
-		// Check if click is on tabs (line 2, after header)
-		if msg.Y == 2 && len(m.tabs) > 1 {
-			// Calculate which tab was clicked
-			tabIndex := m.getTabIndexAtX(msg.X)
-			if tabIndex >= 0 && tabIndex < len(m.tabs) {
-				m.activeTab = m.tabs[tabIndex]
-				m.updateFilter()
-			}
-		} else if msg.Y >= 4 { // after header, tabs, and search (if present)
-			adjustY := msg.Y - 4
-			if m.searchMode || m.searchQuery != "" {
-				adjustY-- // Account for search box
-			}
-			if adjustY >= 0 && adjustY < len(m.filteredTasks) {
-				m.selected = adjustY
-			}
+	// Only presses count: drags and releases are ignored, so moving the
+	// mouse with the button held never runs a task.
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	// Clicks are hit-tested against the list, so they would land on tasks
+	// hidden behind an overlay and, say, run one past a pending y/N.
+	if !m.listViewActive() {
+		return m, nil
+	}
+	if msg.Button == tea.MouseButtonRight {
+		if i := m.listIndexAt(msg.X, msg.Y); i >= 0 && i < len(m.filteredTasks) {
+			m.selected = i
+			m.openContextMenu()
 		}
-	case tea.MouseLeft | tea.MouseMotion:
-		if msg.Y >= 4 {
-			adjustY := msg.Y - 4
-			if m.searchMode || m.searchQuery != "" {
-				adjustY--
-			}
-			if adjustY >= 0 && adjustY < len(m.filteredTasks) && adjustY == m.selected {
-				return m, m.markForExecution()
-			}
+		return m, nil
+	}
+	if msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+	// Check if click is on tabs (below the header)
+	if msg.Y == m.tabsTop() && m.onTabOverflowArrow(msg.X) {
+		m.openTabMenu()
+	} else if msg.Y == m.tabsTop() && m.showTabStrip() {
+		// Calculate which tab was clicked
+		tabIndex := m.getTabIndexAtX(msg.X)
+		if tabIndex >= 0 && tabIndex < len(m.tabs) {
+			m.activeTab = m.tabs[tabIndex]
+			m.updateFilter()
 		}
+	} else if msg.Y >= m.listTop() { // after header, tabs, and search (if present)
+		i := m.listIndexAt(msg.X, msg.Y)
+		if i < 0 || i >= len(m.filteredTasks) {
+			return m, nil
+		}
+		// A click selects a task; a second one on it soon after runs it.
+		double := i == m.clickRow && time.Since(m.clickAt) < doubleClickTime
+		m.selected = i
+		if double {
+			m.clickAt = time.Time{}
+			return m, m.markForExecution(false)
+		}
+		m.clickRow, m.clickAt = i, time.Now()
 	}
 	return m, nil
 }
 
-func (m *TaskModel) markForExecution() tea.Cmd {
+// listViewActive reports whether the plain task list is what the screen
+// shows, with no prompt, form, menu or output pane over it.
+func (m TaskModel) listViewActive() bool {
+	return m.pendingRun == nil && !m.envMode && !m.noteMode && !m.createMode &&
+		!m.editMode && !m.projectPicker && !m.tabMenu && !m.ctxMenu &&
+		!m.gotoMode && !m.paletteMode && !m.historyMode && !m.outputMode &&
+		!m.presetMode && !m.runOptsMode && !m.envFileMode && !m.modalMode
+}
+
+// markForExecution runs the selected task, first prompting for variables when
+// its description documents them. With inline set, the task runs inside the
+// TUI; otherwise the TUI quits and main execs it.
+func (m *TaskModel) markForExecution(inline bool) tea.Cmd {
+	return m.markForExecutionWith(inline, nil)
+}
+
+// markForExecutionWith is markForExecution passing flags, e.g. --force, to
+// Task ahead of the variables.
+func (m *TaskModel) markForExecutionWith(inline bool, flags []string) tea.Cmd {
+	m.runFlags = flags
 	if len(m.filteredTasks) == 0 {
 		return nil
 	}
 	task := m.filteredTasks[m.selected]
+	if isSection(task) {
+		m.setCollapsed(m.selected, !m.collapsed[task.Name])
+		return nil
+	}
+	if !task.Supported() {
+		m.setError(unsupportedError(task))
+		return nil
+	}
+	if err := taskmeta.ShellError(task); err != nil {
+		m.setError(err.Error())
+		return nil
+	}
+	if m.refuseReadOnly() || m.refusePolicy(task) {
+		return nil
+	}
+	m.runInline = inline || m.inlineOnly
+	m.runContainer = m.containerRequested
+	m.runSpawn = ""
+	if !m.runInline {
+		m.runSpawn = m.spawnTarget(m.spawnRequested)
+	}
+	if m.openEnvFilePicker(task) {
+		return nil
+	}
+	return m.prepareRun(task)
+}
 
+// prepareRun asks for the variables of task, if any, and runs it.
+func (m *TaskModel) prepareRun(task taskmeta.Task) tea.Cmd {
 	// Check for variables in description
-	re := regexp.MustCompile(`(\w+)="([^"]+)"`)                // Corrected: escaped quotes within regex string
-	usageRe := regexp.MustCompile(`Usage: task [^ ]+ -- (.*)`) // Corrected: escaped quotes within regex string
-	usageMatch := usageRe.FindStringSubmatch(task.Desc)
-
-	if len(usageMatch) > 1 {
-		// Variables are required, enter modal mode
-		m.modalMode = true
-		m.modalFocused = 0
-		m.modalError = nil
-		m.modalVariables = nil
-		m.modalInputs = nil
+	if vars, ok := task.UsageVars(); ok {
+		if m.openPresets(task) {
+			return nil
+		}
+		m.presetEditing = ""
+		return m.openVariables(vars, nil)
+	}
 
-		matches := re.FindAllStringSubmatch(usageMatch[1], -1)
-		for _, match := range matches {
-			m.modalVariables = append(m.modalVariables, struct {
-				Name         string
-				DefaultValue string
-			}{Name: match[1], DefaultValue: match[2]})
+	// No variables, run task directly
+	return m.execute(task, nil)
+}
 
-			ti := textinput.New()
-			ti.SetValue(match[2])
-			ti.CharLimit = 256
-			ti.Width = 50 // Adjusted for new fancy box
-			m.modalInputs = append(m.modalInputs, ti)
+// openVariables opens the form asking for vars before the selected task
+// runs, filled in with values or else the defaults.
+func (m *TaskModel) openVariables(vars []taskmeta.UsageVar, values map[string]string) tea.Cmd {
+	// Variables are required, enter modal mode
+	m.modalMode = true
+	m.modalFocused = 0
+	m.modalError = nil
+	m.modalVariables = nil
+	m.modalInputs = nil
+
+	for _, v := range vars {
+		value, ok := values[v.Name]
+		if !ok || (len(v.Choices) > 0 && !slices.Contains(v.Choices, value)) {
+			value = v.Default
 		}
+		m.modalVariables = append(m.modalVariables, struct {
+			Name         string
+			DefaultValue string
+			Choices      []string
+			Choice       int
+		}{Name: v.Name, DefaultValue: v.Default, Choices: v.Choices, Choice: max(0, slices.Index(v.Choices, value))})
 
-		if len(m.modalInputs) > 0 {
-			m.modalInputs[0].Focus()
-		}
+		ti := textinput.New()
+		ti.SetValue(value)
+		ti.CharLimit = 256
+		ti.Width = 50 // Adjusted for new fancy box
+		m.modalInputs = append(m.modalInputs, ti)
+	}
 
-		return textinput.Blink // Don\'t quit, stay in modal and blink cursor
+	if len(m.modalInputs) > 0 {
+		m.modalInputs[0].Focus()
 	}
 
-	// No variables, run task directly
-	m.lastCommand = []string{task.Name}
-	m.quitAfterSelect = true
-	return tea.Quit
+	return textinput.Blink // Don't quit, stay in modal and blink cursor
 }
 
-func (m *TaskModel) toggleSortMode() {
-	// Preserve selection
-	var selectedTaskName string
-	if len(m.filteredTasks) > 0 && m.selected >= 0 && m.selected < len(m.filteredTasks) {
-		selectedTaskName = m.filteredTasks[m.selected].Name
+// execute runs task with args either in the embedded runner or, by default,
+// by quitting the TUI so main can exec it in the foreground.
+func (m *TaskModel) execute(task taskmeta.Task, args []string) tea.Cmd {
+	if len(m.runFlags) > 0 {
+		// Arguments from "--" on are for CLI_ARGS and go after the variables.
+		flags, cliArgs := m.runFlags, []string(nil)
+		if i := slices.Index(flags, "--"); i >= 0 {
+			flags, cliArgs = flags[:i], flags[i:]
+		}
+		args = append(append(slices.Clone(flags), args...), cliArgs...)
+		m.runFlags = nil
+	}
+	others := m.parallelRun(task, args)
+	if m.refuseParallel(others) {
+		return nil
+	}
+	if src := m.needsTrust(task); src != "" {
+		m.pendingRun = &pendingRun{task: task, args: args, inline: m.runInline, spawn: m.runSpawn, container: m.runContainer, trust: src}
+		return nil
+	}
+	prompt := m.asksViaTaskg(task, m.runInline, false)
+	confirm := m.needsConfirm(task) || prompt || slices.ContainsFunc(others, m.needsConfirm)
+	plan := m.plan(task) != nil || slices.ContainsFunc(others, func(o taskmeta.Task) bool { return m.plan(o) != nil })
+	if confirm || plan {
+		m.pendingRun = &pendingRun{task: task, args: args, inline: m.runInline, spawn: m.runSpawn, container: m.runContainer, prompt: prompt, plan: !confirm, parallel: others}
+		return nil
 	}
+	return m.executeConfirmed(task, args)
+}
+
+// executeConfirmed is execute without the confirmation prompt.
+func (m *TaskModel) executeConfirmed(task taskmeta.Task, args []string) tea.Cmd {
+	if m.runInline {
+		cmd := m.startRun(task, args)
+		if cmd != nil {
+			m.focusPrompt(task)
+		}
+		return cmd
+	}
+	if m.runSpawn != "" {
+		return m.spawn(task, args)
+	}
+	m.recordRuns(task)
+	m.runTarget = task
+	m.runEnv = append(m.envFor(task), m.experimentEnv(task)...)
+	m.lastCommand = append([]string{task.Name}, args...)
+	m.quitAfterSelect = true
+	return m.quit()
+}
 
-	if m.sortMode == "file" {
+func (m *TaskModel) toggleSortMode() {
+	// updateFilter keeps the selected task selected
+	switch m.sortMode {
+	case "file":
 		m.sortMode = "alpha"
-	} else {
+	case "alpha":
+		m.sortMode = "smart"
+	default:
 		m.sortMode = "file"
 	}
 
 	m.buildTabs()
 	m.updateFilter()
-
-	// Restore selection
-	if selectedTaskName != "" {
-		for i, t := range m.filteredTasks {
-			if t.Name == selectedTaskName {
-				m.selected = i
-				break
-			}
-		}
-	}
-	m.ensureSelectionVisible()
 }
 
 // Accessors used by main program after TUI exits.
 func (m TaskModel) ShouldRun() bool     { return m.quitAfterSelect && len(m.lastCommand) > 0 }
 func (m TaskModel) TaskToRun() []string { return m.lastCommand }
 
+// RunTarget returns the task chosen for execution, including its backend.
+func (m TaskModel) RunTarget() taskmeta.Task { return m.runTarget }
+
+// RunEnv returns KEY=value overrides to append to the task's environment.
+func (m TaskModel) RunEnv() []string { return m.runEnv }
+
 // (Removed legacy grouping functions & types)
 
 func (m *TaskModel) updateFilter() {
-	// If there\'s a search query, run the search across all tasks (global
-	// search), otherwise show tasks for the currently active tab.
+	prev, prevSelected := m.filteredTasks, m.selected
+
+	// Search within the active tab unless global search is toggled on (ctrl+g).
 	var baseTasks []taskmeta.Task
-	if m.searchQuery != "" {
+	if m.searchQuery != "" && m.searchGlobal {
 		// global search across all discovered tasks
 		baseTasks = m.tasks
+	} else if m.tree {
+		// Searching the tree layout searches every task, without headers.
+		baseTasks = m.tasks
+		if m.searchQuery == "" {
+			baseTasks = m.treeTasks()
+		}
 	} else {
 		baseTasks = m.tabTasks[m.activeTab]
 		if baseTasks == nil {
 			baseTasks = []taskmeta.Task{}
 		}
 	}
+	baseTasks = m.keepNeverRun(baseTasks)
 
-	if m.searchQuery == "" {
+	if name, ok := exactQuery(m.searchQuery); ok {
+		res := baseTasks
+		if name != "" {
+			res = nil
+			for _, t := range baseTasks {
+				if t.Name == name {
+					res = append(res, t)
+				}
+			}
+		}
+		m.filteredTasks = res
+	} else if m.searchQuery == "" {
 		m.filteredTasks = baseTasks
 	} else {
-		q := strings.ToLower(m.searchQuery)
+		hits := m.searchIdx().match(strings.ToLower(m.searchQuery))
 		var res []taskmeta.Task
 		for _, t := range baseTasks {
-			hay := strings.ToLower(t.Name + " " + t.Desc + " " + strings.Join(t.Cmds, " "))
-			if strings.Contains(hay, q) {
+			if hits[taskKey(t)] {
 				res = append(res, t)
 			}
 		}
 		m.filteredTasks = res
 	}
 
-	if m.selected >= len(m.filteredTasks) {
-		m.selected = max(0, len(m.filteredTasks)-1)
-	}
+	m.restoreSelection(prev, prevSelected)
 	m.ensureSelectionVisible()
 }
 
@@ -505,20 +1243,16 @@ func (m *TaskModel) buildTabs() {
 	// Use originalTasks to ensure file order is always the base
 	tasksToProcess := m.originalTasks
 
+	group := m.tabGrouper(tasksToProcess)
 	for _, task := range tasksToProcess {
-		var prefix string
-		parts := strings.SplitN(task.Name, "-", 2)
-		if len(parts) > 1 {
-			prefix = parts[0]
-		} else {
-			prefix = "main"
-		}
-
-		if !prefixSet[prefix] {
-			prefixes = append(prefixes, prefix)
-			prefixSet[prefix] = true
+		// A task may be listed under several tabs (e.g. one per tag).
+		for _, prefix := range group(task) {
+			if !prefixSet[prefix] {
+				prefixes = append(prefixes, prefix)
+				prefixSet[prefix] = true
+			}
+			prefixMap[prefix] = append(prefixMap[prefix], task)
 		}
-		prefixMap[prefix] = append(prefixMap[prefix], task)
 	}
 
 	// Sort tasks within each tab
@@ -527,9 +1261,23 @@ func (m *TaskModel) buildTabs() {
 			sort.SliceStable(tasks, func(i, j int) bool {
 				return tasks[i].Name < tasks[j].Name
 			})
+		} else if m.sortMode == "smart" {
+			// Favorites, then the most used tasks first; never-run ones
+			// keep file order.
+			now := time.Now()
+			sort.SliceStable(tasks, func(i, j int) bool {
+				if fi, fj := m.favorites[taskKey(tasks[i])], m.favorites[taskKey(tasks[j])]; fi != fj {
+					return fi
+				}
+				si, sj := m.state.Frecency(taskKey(tasks[i]), now), m.state.Frecency(taskKey(tasks[j]), now)
+				if si != sj {
+					return si > sj
+				}
+				return fileOrderLess(tasks[i], tasks[j])
+			})
 		} else { // "file"
 			sort.SliceStable(tasks, func(i, j int) bool {
-				return tasks[i].Line < tasks[j].Line
+				return fileOrderLess(tasks[i], tasks[j])
 			})
 		}
 	}
@@ -550,8 +1298,15 @@ func (m *TaskModel) buildTabs() {
 		prefixes = append(prefixes[:mainIndex], prefixes[mainIndex+1:]...)
 		prefixes = append([]string{mainPrefix}, prefixes...)
 	}
+	// ...and the catch-all tab of tag grouping last
+	for i, p := range prefixes {
+		if p == untaggedTab {
+			prefixes = append(append(prefixes[:i:i], prefixes[i+1:]...), untaggedTab)
+			break
+		}
+	}
 
-	m.tabs = prefixes
+	m.tabs = m.arrangeTabs(prefixes)
 	m.tabTasks = prefixMap
 
 	// Ensure active tab is still valid
@@ -567,6 +1322,38 @@ func (m *TaskModel) buildTabs() {
 	}
 }
 
+// taskKey identifies a task across backends.
+func taskKey(t taskmeta.Task) string { return t.Backend + ":" + t.Project + ":" + t.Name }
+
+// fileOrderLess orders tasks as they appear in their files, keeping the tasks
+// of one subproject together in recursive mode.
+func fileOrderLess(a, b taskmeta.Task) bool {
+	if a.Project != b.Project {
+		return a.Project < b.Project
+	}
+	return a.Line < b.Line
+}
+
+// markedTasks returns the marked tasks in file order.
+func (m *TaskModel) markedTasks() []taskmeta.Task {
+	var out []taskmeta.Task
+	for _, t := range m.originalTasks {
+		if m.marked[taskKey(t)] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func hasMultipleBackends(tasks []taskmeta.Task) bool {
+	for _, t := range tasks {
+		if t.Backend != tasks[0].Backend {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *TaskModel) moveToNextTab() {
 	if len(m.tabs) <= 1 {
 		return
@@ -636,26 +1423,15 @@ func (m *TaskModel) ensureTabVisible(tabIndex int) {
 		return
 	}
 
-	// Calculate how many tabs can fit in current width
-	availableWidth := m.width - 14 // Leave space for borders and arrows
-	if availableWidth < 20 {
-		availableWidth = 20
-	}
+	// Calculate how many tabs can fit in current width, keeping a cell for
+	// each arrow
+	availableWidth := tabStripWidth(m.listWidth()) - 2
 
 	visibleCount := 0
 	currentWidth := 0
 
 	for i := 0; i < len(m.tabs); i++ {
-		tab := m.tabs[i]
-		tabName := tab
-		if tab == "main" {
-			tabName = "Main"
-		} else {
-			tabName = m.titleCase(tab)
-		}
-
-		// Account for highlight bar and space (2 chars) + padding + margins
-		tabWidth := len(tabName) + 8 // highlight bar + space + padding + margins
+		tabWidth := lipgloss.Width(m.renderTab(m.tabs[i]))
 		if currentWidth+tabWidth > availableWidth {
 			break
 		}
@@ -692,13 +1468,15 @@ func (m *TaskModel) getTabIndexAtX(x int) int {
 		return -1
 	}
 
-	// Simple approximation - each tab takes about 10-15 characters
-	// This is a rough estimate, for precise clicking we\'d need to track exact positions
-	// Start after border padding plus header indent so clicks map when tabs are indented under the title/logo.
-	pos := 2 + m.headerIndent
+	// Tabs are laid out as renderTabs does: after the app border and padding,
+	// the tab strip's padding and the ◀ arrow when tabs are scrolled off.
+	appFrameW, _ := m.theme.AppContainer.GetFrameSize()
+	pos := appFrameW/2 + m.theme.TabsContainer.GetPaddingLeft() + m.headerIndent
+	if m.tabOffset > 0 {
+		pos++
+	}
 	for i := m.tabOffset; i < len(m.tabs); i++ {
-		tab := m.tabs[i]
-		tabWidth := len(tab) + 8 // tab name + highlight bar + space + padding + margins
+		tabWidth := lipgloss.Width(m.renderTab(m.tabs[i]))
 		if x >= pos && x < pos+tabWidth {
 			return i
 		}
@@ -707,11 +1485,6 @@ func (m *TaskModel) getTabIndexAtX(x int) int {
 	return -1
 }
 
-func (m *TaskModel) setStatus(message string) {
-	m.statusMessage = message
-	m.statusTimeout = time.Now().Add(3 * time.Second)
-}
-
 // visibleListHeight calculates how many command boxes fit given current height.
 // Layout rows: 1 title + 1 tabs (if any) + 1 search (optional) + list + 1 status + 1 footer borders/padding already handled by container.
 func (m *TaskModel) visibleListHeight() int {
@@ -723,35 +1496,14 @@ func (m *TaskModel) visibleListHeight() int {
 		} // sane fallback
 	}
 
-	const (
-		containerOverhead = 4 // AppContainer border + padding vertical
-		headerHeight      = 2
-		tabsHeight        = 3
-		searchHeight      = 3
-		statusHeight      = 1
-		footerHeight      = 3
-	)
-	avail := m.height
-	if avail <= 0 {
-		avail = 24
-	}
-	inner := avail - containerOverhead
-	if inner < 10 {
-		inner = 10
-	}
-	overhead := headerHeight + statusHeight + footerHeight
-	// Add tabs height if we have multiple tabs
-	if len(m.tabs) > 1 {
-		overhead += tabsHeight
+	// Count the rows that fit from the top of the list; past its end, the
+	// space left is counted in items of itemHeight.
+	lines := m.listLines()
+	items := 0
+	for r := m.listOffset / m.gridColumns(); lines >= m.rowHeight(r); r++ {
+		lines -= m.rowHeight(r)
+		items++
 	}
-	if m.searchMode || m.searchQuery != "" {
-		overhead += searchHeight
-	}
-	remaining := inner - overhead
-	if remaining < m.itemHeight {
-		return 1
-	}
-	items := remaining / m.itemHeight
 	if items < 1 {
 		items = 1
 	}
@@ -775,6 +1527,9 @@ func (m *TaskModel) measureItemHeight() int {
 	sampleTask := "  • sample-task - Sample description"
 	sampleCmd := "    [echo hello | ls -la]"
 	sampleContent := sampleTask + "\n" + sampleCmd
+	if m.hideCmds {
+		sampleContent = sampleTask
+	}
 
 	style := m.theme.CommandBox
 	str := style.Copy().Width(innerWidth).Render(sampleContent)
@@ -784,26 +1539,195 @@ func (m *TaskModel) measureItemHeight() int {
 	return lines
 }
 
-// ensureSelectionVisible adjusts listOffset to keep selected index in viewport.
+// renderRow renders the list box of t: name line plus command line.
+func (m TaskModel) renderRow(t taskmeta.Task, selected bool, hotkey, width int) string {
+	// Multi-line format: [indicator] task-name - description
+	//                    [indent] [command1 | command2 | ...]
+	var prefix string
+	var taskStyle lipgloss.Style
+	// Tasks marked for a parallel run get a filled diamond instead of the dot
+	dotGlyph, dotStyle := "•", m.theme.Accent
+	if m.marked[taskKey(t)] {
+		dotGlyph, dotStyle = "◆", m.theme.Highlight
+	}
+	if m.accessible {
+		dotGlyph = m.rowMarker(taskKey(t))
+	}
+	if selected {
+		bar := m.theme.Highlight.Render(m.selectionBar())
+		dot := m.theme.Highlight.Render(dotGlyph)
+		prefix = fmt.Sprintf("%s %s", bar, dot)
+		taskStyle = m.theme.Highlight
+	} else {
+		// Two spaces replace the bar + following space (bar + space == width 2)
+		dot := dotStyle.Render(dotGlyph)
+		prefix = fmt.Sprintf("  %s", dot)
+		taskStyle = m.theme.TaskName
+		switch {
+		case !t.Supported():
+			taskStyle = m.theme.Help
+		case t.Desc == "":
+			// Undescribed tasks are usually helpers, listed by --list-all only.
+			taskStyle = m.theme.TaskName.Copy().Bold(false).Faint(true)
+		}
+	}
+
+	// Format: task-name - description (if available)
+	taskText := m.renderHotkey(hotkey) + m.taskIcon(t) + taskStyle.Render(t.Name)
+	if m.favorites[taskKey(t)] {
+		taskText += " " + m.theme.Accent.Render("★")
+	}
+	if m.recursive && m.projectLayout == config.LayoutColumn {
+		taskText = m.renderProjectColumn(t) + taskText
+	}
+	if badge := m.statusBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if tags := m.renderTags(t); tags != "" {
+		taskText += " " + tags
+	}
+	if m.duplicates[taskKey(t)] {
+		taskText += " " + m.theme.Help.Render("("+m.sourceLabel(t)+")")
+	}
+	if badge := m.platformBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if badge := m.policyBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if badge := m.promptBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if badge := m.remoteBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if badge := m.estimateBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if badge := m.lastRunBadge(t); badge != "" {
+		taskText += " " + badge
+	}
+	if m.noteFor(t) != "" {
+		taskText += " " + m.theme.Accent.Render("✎")
+	}
+	if t.Desc != "" && t.Desc != "-" {
+		// Do NOT accent the description when selected; only the name gets highlight.
+		descStyle := m.theme.Command
+		if !t.Supported() {
+			descStyle = m.theme.Help
+		}
+		taskText += " - " + descStyle.Render(t.Desc)
+	}
+
+	// First line: task name and description, wrapping under the task text
+	indent := ansi.StringWidth(prefix) + 1
+	contentWidth := width - m.theme.CommandBox.GetHorizontalPadding()
+	taskLines := m.wrapTaskText(taskText, contentWidth-indent)
+	line := fmt.Sprintf("%s %s", prefix, strings.Join(taskLines, "\n"+strings.Repeat(" ", indent)))
+
+	// Second line: commands (indented), or one line per command when expanded
+	var cmdLine string
+	scroll, expanded := m.cmdView(t, selected)
+	switch {
+	case expanded:
+		cmdLine = m.renderExpandedCmds(t, contentWidth)
+	case len(t.Cmds) > 0 && !m.hideCmds:
+		cmdLine = m.renderCmdLine(t, scroll, contentWidth)
+	}
+
+	// Combine both lines
+	var fullContent string
+	if cmdLine != "" {
+		fullContent = line + "\n" + cmdLine
+	} else {
+		fullContent = line
+	}
+
+	box, selectedBox := m.boxStyles(width)
+	if selected {
+		return selectedBox.Render(fullContent)
+	}
+	return box.Render(fullContent)
+}
+
+// SetScrollMargin keeps n rows of the list above and below the selection
+// while scrolling, like Vim's scrolloff; a large n keeps it centered.
+func (m *TaskModel) SetScrollMargin(n int) { m.scrollMargin = n }
+
+// ensureSelectionVisible adjusts listOffset to keep selected index in viewport,
+// scrollMargin rows away from its edges where the list allows.
+// In a grid the offset moves by whole rows.
 func (m *TaskModel) ensureSelectionVisible() {
 	listHeight := m.visibleListHeight()
-	if m.selected < m.listOffset {
-		m.listOffset = m.selected
+	cols := m.gridColumns()
+	row, offset := m.selected/cols, m.listOffset/cols
+	margin := min(m.scrollMargin, (listHeight-1)/2)
+	if row < offset+margin {
+		offset = row - margin
 	}
-	if m.selected >= m.listOffset+listHeight {
-		m.listOffset = m.selected - listHeight + 1
+	// Rows differ in height, so scroll down one row at a time until the
+	// selection and the margin below it fit.
+	last := min(row+margin, m.listRows()-1)
+	for offset < row && !m.rowsFit(offset, last) {
+		offset++
 	}
-	maxOffset := max(0, len(m.filteredTasks)-listHeight)
-	if m.listOffset > maxOffset {
-		m.listOffset = maxOffset
+	if maxOffset := m.maxListRow(); offset > maxOffset {
+		offset = maxOffset
 	}
-	if m.listOffset < 0 {
-		m.listOffset = 0
+	if offset < 0 {
+		offset = 0
 	}
+	m.listOffset = offset * cols
 }
 
-func (m TaskModel) View() string {
+func (m TaskModel) view() string {
+	if m.inline && m.quitting {
+		return ""
+	}
+	if m.pendingRun != nil {
+		return m.renderConfirm()
+	}
+	if m.envMode {
+		return m.renderEnvEditor()
+	}
+	if m.noteMode {
+		return m.renderNoteEditor()
+	}
+	if m.createMode {
+		return m.renderCreateForm()
+	}
+	if m.editMode {
+		return m.renderTaskEditor()
+	}
+	if m.projectPicker {
+		return m.renderProjectPicker()
+	}
+	if m.tabMenu {
+		return m.renderTabMenu()
+	}
+	if m.ctxMenu {
+		return m.renderContextMenu()
+	}
+	if m.paletteMode {
+		return m.renderPalette()
+	}
+	if m.historyMode {
+		return m.renderHistory()
+	}
+	if m.presetMode {
+		return m.renderPresets()
+	}
+	if m.runOptsMode {
+		return m.renderRunOptions()
+	}
+	if m.envFileMode {
+		return m.renderEnvFilePicker()
+	}
+
 	mainView := m.renderList()
+	if m.outputMode {
+		mainView = m.renderOutput()
+	}
 
 	if m.modalMode {
 		fancyBorder := lipgloss.Border{
@@ -828,18 +1752,32 @@ func (m TaskModel) View() string {
 			m.modalInputs[i].Prompt = "▪ "
 			m.modalInputs[i].PromptStyle = m.theme.Highlight
 
+			field := m.modalInputs[i].View()
+			if m.isChoice(i) {
+				field = m.renderChoice(i)
+			}
 			inputBox := lipgloss.NewStyle().
 				Border(fancyBorder, true).
 				BorderForeground(m.theme.HighlightColor).
 				Padding(0, 1).
-				Render(m.modalInputs[i].View())
+				Render(field)
 
 			sections = append(sections, inputBox)
 		}
+		if m.presetNaming {
+			sections = append(sections, m.theme.Help.Render("Save as preset:")+" "+m.presetName.View())
+		}
 
 		tabKey := m.theme.Highlight.Copy().Render("TAB")
 		enterKey := m.theme.Highlight.Copy().Render("ENTER")
 		helperText := fmt.Sprintf("%s to change field, %s to run", tabKey, enterKey)
+		if m.hasChoices() {
+			helperText = fmt.Sprintf("%s to change field, %s to choose, %s to run", tabKey, m.theme.Highlight.Render("←→"), enterKey)
+		}
+		helperText += fmt.Sprintf(", %s to save as preset", m.theme.Highlight.Render("^S"))
+		if m.presetNaming {
+			helperText = fmt.Sprintf("%s to save the preset, %s to go back", enterKey, m.theme.Highlight.Render("ESC"))
+		}
 		helper := m.theme.Help.Copy().Italic(true).Render(helperText)
 		sections = append(sections, helper)
 
@@ -879,15 +1817,26 @@ func (m TaskModel) renderList() string {
 	}
 
 	// Refactored header: title on the left, logo on the far right (two lines).
-	proj := m.projectName
-	if proj == "" {
-		proj = "(no Taskfile)"
+	appTitle := truncateStringToWidth(m.headerLine(m.headerTmpl), innerWidth-16)
+	// The subheader gives way to warnings.
+	secondLine := truncateStringToWidth(m.headerLine(m.subheaderTmpl), innerWidth-8)
+	if m.browsing() {
+		secondLine = "Browsing " + m.browseSource + " read-only"
+		if m.discoveryWarning != "" {
+			secondLine += ": " + m.discoveryWarning
+		}
+		secondLine = truncateStringToWidth(secondLine, innerWidth-8)
+	} else if m.usingBuiltinRunner() {
+		secondLine = "⚠ task binary not found: using the limited built-in runner (sh, dir and env only)"
+	} else if m.discoveryWarning != "" {
+		secondLine = truncateStringToWidth(m.discoveryWarning, innerWidth-8)
 	}
-	appTitle := "Task Runner Gui - taskg" // could append proj if desired
-	secondLine := ""                      // reserved for future help/hints
 
 	// Logo (2-line block glyph) now rendered at the right edge
 	logoLines := []string{"░▀░▀░  ", "░▄░▄░"}
+	if m.accessible {
+		logoLines = []string{"", ""}
+	}
 	logoStyledLines := make([]string, len(logoLines))
 	logoWidth := 0
 	for i, l := range logoLines {
@@ -902,7 +1851,15 @@ func (m TaskModel) renderList() string {
 	m.headerIndent = 0
 
 	titleRendered := m.theme.AppTitle.Render(appTitle)
+	if m.direnvVars() != nil {
+		titleRendered += " " + m.theme.Accent.Render("[direnv]")
+	}
 	secondRendered := m.theme.Help.Render(secondLine)
+	if m.usingBuiltinRunner() {
+		secondRendered = m.theme.Error.Render(secondLine)
+	} else if m.discoveryWarning != "" {
+		secondRendered = m.theme.Warning.Render(secondLine)
+	}
 
 	space1 := innerWidth - lipgloss.Width(titleRendered) - logoWidth
 	if space1 < 1 {
@@ -915,12 +1872,14 @@ func (m TaskModel) renderList() string {
 
 	firstLine := titleRendered + strings.Repeat(" ", space1) + logoStyledLines[0]
 	secondLineOut := secondRendered + strings.Repeat(" ", space2) + logoStyledLines[1]
-	content.WriteString(firstLine + "\n" + secondLineOut + "\n")
+	if !m.hideHeader {
+		content.WriteString(firstLine + "\n" + secondLineOut + "\n")
+	}
 
 	// Render tabs if we have multiple tabs. We indent them so the first tab aligns
 	// with the title (which starts after the logo). headerIndent is stored for
 	// mouse hit testing.
-	if len(m.tabs) > 1 {
+	if m.showTabStrip() {
 		// Header indent no longer needed (logo on right); keep 0 so first tab aligns with title start.
 		m.headerIndent = 0
 		content.WriteString(m.renderTabs(innerWidth) + "\n")
@@ -931,14 +1890,19 @@ func (m TaskModel) renderList() string {
 	// Search
 	if m.searchMode {
 		box := m.theme.SearchBox.Copy()
-		content.WriteString(box.Width(innerWidth).Render(m.searchInput.View()) + "\n")
+		content.WriteString(box.Width(innerWidth).Render(m.searchBoxView()) + "\n")
+	} else if m.gotoMode {
+		box := m.theme.SearchBox.Copy()
+		content.WriteString(box.Width(innerWidth).Render(m.gotoView()) + "\n")
 	} else if m.searchQuery != "" {
-		info := fmt.Sprintf("🔍 %s  ( / edit  esc clear )", m.searchQuery)
+		info := fmt.Sprintf("🔍 [%s] %s  ( / edit  ^G scope  esc clear )", m.searchScope(), m.searchQuery)
 		box := m.theme.SearchBox.Copy()
 		content.WriteString(box.Width(innerWidth).Render(info) + "\n")
 	}
 
-	if len(m.filteredTasks) == 0 {
+	if m.loading {
+		content.WriteString(m.renderLoading(innerWidth) + "\n")
+	} else if len(m.filteredTasks) == 0 {
 		help := m.theme.Help.Copy()
 		content.WriteString(help.Width(innerWidth).Render("No tasks found") + "\n")
 	}
@@ -946,82 +1910,34 @@ func (m TaskModel) renderList() string {
 		errStyle := m.theme.Error.Copy()
 		content.WriteString(errStyle.Width(innerWidth).Render(m.errorMessage) + "\n")
 		help := m.theme.Help.Copy()
-		content.WriteString(help.Width(innerWidth).Render("Create a Taskfile.yml, e.g:\nversion: '3'\ntasks:\n  hello:\n    desc: Say hello\n    cmds:\n      - echo 'Hello from Task'") + "\n")
+		for _, l := range m.taskfileErrorLines() {
+			content.WriteString(truncateStringToWidth(l, innerWidth) + "\n")
+		}
+		if m.taskfileErr != nil {
+			content.WriteString(help.Width(innerWidth).Render("Press F4 to fix it in $EDITOR; tasks are reloaded when the editor exits.") + "\n")
+		} else {
+			content.WriteString(help.Width(innerWidth).Render("Press ^T to add a task with a form, or write a Taskfile.yml by hand, e.g:\nversion: '3'\ntasks:\n  hello:\n    desc: Say hello\n    cmds:\n      - echo 'Hello from Task'") + "\n")
+		}
 	}
 
 	// Command list window with vertical scrolling
-	listHeight := m.visibleListHeight()
-	if listHeight < 1 {
-		listHeight = 1
-	}
 	// clamp listOffset in case of data shrink
-	maxOffset := max(0, len(m.filteredTasks)-listHeight)
-	if m.listOffset > maxOffset {
+	cols := m.gridColumns()
+	if maxOffset := m.maxListRow() * cols; m.listOffset > maxOffset {
 		m.listOffset = maxOffset
 	}
-	end := min(len(m.filteredTasks), m.listOffset+listHeight)
-	for i := m.listOffset; i < end; i++ {
-		t := m.filteredTasks[i]
-		// Multi-line format: [indicator] task-name - description
-		//                    [indent] [command1 | command2 | ...]
-		var prefix string
-		var taskStyle lipgloss.Style
-		if i == m.selected {
-			bar := m.theme.Highlight.Render("▎")
-			dot := m.theme.Highlight.Render("•")
-			prefix = fmt.Sprintf("%s %s", bar, dot)
-			taskStyle = m.theme.Highlight
-		} else {
-			// Two spaces replace the bar + following space (bar + space == width 2)
-			dot := m.theme.Accent.Render("•")
-			prefix = fmt.Sprintf("  %s", dot)
-			taskStyle = m.theme.TaskName
-		}
-
-		// Format: task-name - description (if available)
-		taskText := taskStyle.Render(t.Name)
-		if t.Desc != "" && t.Desc != "-" {
-			// Do NOT accent the description when selected; only the name gets highlight.
-			descStyle := m.theme.Command
-			taskText += " - " + descStyle.Render(t.Desc)
-		}
-
-		// First line: task name and description
-		line := fmt.Sprintf("%s %s", prefix, taskText)
-
-		// Second line: commands (indented)
-		var cmdLine string
-		if len(t.Cmds) > 0 {
-			// Create indented prefix for commands
-			var cmdPrefix string
-			if i == m.selected {
-				cmdPrefix = "    " // 4 spaces to align under the task text
-			} else {
-				cmdPrefix = "    " // 4 spaces to align under the task text
-			}
-
-			// Format commands with separators. Keep same style whether selected or not so only task name pops.
-			cmdStyle := m.theme.Description
-
-			// Join commands with " | " separator and wrap in brackets
-			cmdText := "[" + strings.Join(t.Cmds, " | ") + "]"
-			cmdLine = cmdPrefix + cmdStyle.Render(cmdText)
-		}
-
-		// Combine both lines
-		var fullContent string
-		if cmdLine != "" {
-			fullContent = line + "\n" + cmdLine
-		} else {
-			fullContent = line
+	listHeight := m.visibleListHeight()
+	if cols > 1 {
+		content.WriteString(m.renderGrid(innerWidth, listHeight))
+	} else {
+		end := min(len(m.filteredTasks), m.listOffset+listHeight)
+		for i := m.listOffset; i < end; i++ {
+			content.WriteString(m.listRow(i, innerWidth) + "\n")
 		}
+	}
 
-		style := m.theme.CommandBox
-		if i == m.selected {
-			style = m.theme.SelectedWire
-		}
-		box := style.Copy()
-		content.WriteString(box.Width(innerWidth).Render(fullContent) + "\n")
+	if m.showDetail {
+		content.WriteString(m.renderDetail(innerWidth) + "\n")
 	}
 
 	// After changing spacing we must recompute itemHeight if theme changed sizes.
@@ -1029,14 +1945,37 @@ func (m TaskModel) renderList() string {
 		m.itemHeight = m.measureItemHeight()
 	}
 
-	// Status line (always reserve a line to avoid layout jump)
-	statusText := ""
-	if time.Now().Before(m.statusTimeout) && m.statusMessage != "" {
-		statusText = m.statusMessage
+	// Status toasts (always reserve a line to avoid layout jump)
+	content.WriteString(m.renderToasts(innerWidth))
+	if !m.hideFooter {
+		content.WriteString("\n" + m.renderFooter(innerWidth))
 	}
-	status := m.theme.Status.Copy()
-	content.WriteString(status.Width(innerWidth).Render(statusText) + "\n")
 
+	// Final app container: set width then render
+	finalRender := m.theme.AppContainer.Copy().Width(termWidth).Render(content.String())
+
+	// Ensure we never emit more lines than the terminal height. This keeps
+	// the header at the top of the viewport and prevents the terminal from
+	// scrolling the header out of view when the item list grows large or when
+	// switching tabs which can change the rendered height.
+	// If m.height is not known (0) or too small, fall back to returning the
+	// whole render so Bubble Tea can manage it, but prefer trimming when
+	// possible.
+	if m.height > 0 {
+		lines := strings.Split(finalRender, "\n")
+		// If rendered lines exceed terminal height, keep only the top lines
+		// so the header remains visible.
+		if len(lines) > m.height {
+			lines = lines[:m.height]
+			finalRender = strings.Join(lines, "\n")
+		}
+	}
+
+	return finalRender
+}
+
+// renderFooter renders the key hints below the list, wrapped to width.
+func (m TaskModel) renderFooter(innerWidth int) string {
 	// Build footer parts with consistent layout
 	var parts []string
 	if m.modalMode {
@@ -1052,22 +1991,58 @@ func (m TaskModel) renderList() string {
 		}
 
 		parts = append(parts, "↑↓ move")
-		if len(m.tabs) > 1 {
+		if m.tree {
+			parts = append(parts, "←→ fold")
+		} else if len(m.tabs) > 1 {
 			parts = append(parts, "←→/Tab switch")
 		}
+		if m.tabsOverflow() {
+			parts = append(parts, "M-T all tabs")
+		}
 		parts = append(parts, m.theme.Highlight.Render("Enter run"))
+		parts = append(parts, "^O run here")
+		if t := detectTarget(); t != "" && m.runIn == "" {
+			parts = append(parts, "M-Enter "+strings.Fields(spawnNoun(t))[0])
+		}
+		if len(m.containers) > 0 {
+			parts = append(parts, "M-C container")
+		}
+		if n := len(m.marked); n > 0 {
+			parts = append(parts, m.theme.Highlight.Render(fmt.Sprintf("%d marked (^O runs all)", n)))
+		} else {
+			parts = append(parts, "Space mark")
+		}
 		parts = append(parts, "/ search")
-		parts = append(parts, "r/^R refresh")
+		parts = append(parts, "' go to")
+		if m.typeStartsSearch('r') {
+			parts = append(parts, "^R refresh")
+		} else {
+			parts = append(parts, "r/^R refresh")
+		}
+		parts = append(parts, "^E env")
+		parts = append(parts, "^F flags")
+		parts = append(parts, "^D details")
+		parts = append(parts, "^P projects")
+		parts = append(parts, "^K actions")
 
 		var sortIndicator string
 		if m.sortMode == "alpha" {
 			sortIndicator = "Sort: A→Z (^S)"
+		} else if m.sortMode == "smart" {
+			sortIndicator = "Sort: Smart (^S)"
 		} else {
 			sortIndicator = "Sort: Original (^S)"
 		}
 		parts = append(parts, sortIndicator)
+		if m.neverRun {
+			parts = append(parts, "Never run only (^K)")
+		}
 
-		parts = append(parts, "q quit")
+		if m.typeStartsSearch('q') {
+			parts = append(parts, "^Q quit")
+		} else {
+			parts = append(parts, "q quit")
+		}
 	}
 
 	// Flexible footer layout that wraps
@@ -1102,31 +2077,24 @@ func (m TaskModel) renderList() string {
 
 	footerContent := strings.Join(lines, "\n")
 
-	footerBox := m.theme.FooterBox.Copy()
-	footer := footerBox.Width(innerWidth).Render(footerContent)
-	content.WriteString(footer)
-
-	// Final app container: set width then render
-	finalRender := m.theme.AppContainer.Copy().Width(termWidth).Render(content.String())
+	return m.theme.FooterBox.Copy().Width(innerWidth).Render(footerContent)
+}
 
-	// Ensure we never emit more lines than the terminal height. This keeps
-	// the header at the top of the viewport and prevents the terminal from
-	// scrolling the header out of view when the item list grows large or when
-	// switching tabs which can change the rendered height.
-	// If m.height is not known (0) or too small, fall back to returning the
-	// whole render so Bubble Tea can manage it, but prefer trimming when
-	// possible.
-	if m.height > 0 {
-		lines := strings.Split(finalRender, "\n")
-		// If rendered lines exceed terminal height, keep only the top lines
-		// so the header remains visible.
-		if len(lines) > m.height {
-			lines = lines[:m.height]
-			finalRender = strings.Join(lines, "\n")
-		}
+// renderTab renders the label of tab in the tab strip.
+func (m TaskModel) renderTab(tab string) string {
+	if tab == m.activeTab {
+		// Add vertical bar highlight for active tab
+		highlightBar := m.theme.Highlight.Render(m.selectionBar())
+		return m.theme.TabActive.Render(highlightBar + " " + m.tabTitle(tab) + m.tabBadges(tab))
 	}
+	// Add spaces to align with active tab (bar + space == 2 chars)
+	return m.theme.TabInactive.Render("  " + m.tabTitle(tab) + m.tabBadges(tab))
+}
 
-	return finalRender
+// tabStripWidth is the room the tabs get in a tab strip width cells wide,
+// leaving a small margin for the arrows and borders.
+func tabStripWidth(width int) int {
+	return max(20, width-11)
 }
 
 func (m TaskModel) renderTabs(width int) string {
@@ -1142,32 +2110,12 @@ func (m TaskModel) renderTabs(width int) string {
 	// the arrows are always visible and tabs never wrap to multiple lines.
 
 	// Calculate available width for tabs and reserve for borders/padding
-	availableWidth := width - 11 // small margin for arrows/borders
-	if availableWidth < 20 {
-		availableWidth = 20
-	}
+	availableWidth := tabStripWidth(width)
 
 	// Render tab parts (no arrows yet)
 	var renderedTabs []string
 	for i := m.tabOffset; i < len(m.tabs); i++ {
-		tab := m.tabs[i]
-		tabName := tab
-		if tab == "main" {
-			tabName = "Main"
-		} else {
-			tabName = m.titleCase(tab)
-		}
-
-		if tab == m.activeTab {
-			// Add vertical bar highlight for active tab
-			highlightBar := m.theme.Highlight.Render("▎")
-			tabContent := highlightBar + " " + tabName
-			renderedTabs = append(renderedTabs, m.theme.TabActive.Render(tabContent))
-		} else {
-			// Add spaces to align with active tab (bar + space == 2 chars)
-			tabContent := "  " + tabName
-			renderedTabs = append(renderedTabs, m.theme.TabInactive.Render(tabContent))
-		}
+		renderedTabs = append(renderedTabs, m.renderTab(m.tabs[i]))
 	}
 
 	// Join without arrows to measure width
@@ -1229,34 +2177,6 @@ func (m *TaskModel) titleCase(s string) string {
 	if len(s) == 0 {
 		return s
 	}
-	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
-}
-
-// truncateStringToWidth cuts s so its visible width (measured by lipgloss.Width)
-// does not exceed maxW. If truncation is required we append a single right
-// ellipsis character to indicate truncation. This is a small helper because
-// older lipgloss versions may not provide a Truncate helper.
-func truncateStringToWidth(s string, maxW int) string {
-	if maxW <= 0 {
-		return ""
-	}
-	if lipgloss.Width(s) <= maxW {
-		return s
-	}
-	// Reserve 1 char for ellipsis
-	// Walk runes until adding one more would exceed maxW-1
-	runes := []rune(s)
-	var b strings.Builder
-	for i := 0; i < len(runes); i++ {
-		b.WriteRune(runes[i])
-		if lipgloss.Width(b.String()) >= maxW-1 {
-			break
-		}
-	}
-	res := b.String()
-	// If we\'re still too long, trim last rune(s)
-	for lipgloss.Width(res) > maxW-1 {
-		res = string([]rune(res)[:len([]rune(res))-1])
-	}
-	return res + "…"
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + strings.ToLower(s[size:])
 }
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+)
+
+// In ASCII mode (--ascii, ascii: true in the config file, or a terminal
+// known to lack the glyphs) everything taskg draws is spelled in ASCII:
+// borders, arrows, bullets, badges and the logo. Each glyph is swapped for
+// as many ASCII characters as it is wide, so the layout computed for the
+// glyphs still holds.
+
+// asciiGlyphs maps the glyphs taskg draws to their ASCII stand-ins.
+var asciiGlyphs = strings.NewReplacer(
+	// Borders, as drawn by lipgloss.NormalBorder, RoundedBorder and
+	// ThickBorder.
+	"─", "-", "│", "|", "┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"━", "-", "┃", "|", "┏", "+", "┓", "+", "┗", "+", "┛", "+",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+",
+	// Arrows and markers.
+	"←", "<", "→", ">", "↑", "^", "↓", "v", "⇧", "^", "⇣", "v",
+	"◀", "<", "▶", ">", "▸", ">", "▾", "v", "▎", "|",
+	"•", "*", "◆", "*", "▪", "-", "●", "*", "○", "o", "■", "#",
+	"·", ".", "…", "~", "×", "x",
+	// Badges.
+	"✓", "+", "✗", "x", "⚠", "!", "⚑", "!", "✎", "*", "↻", "@", "★", "*",
+	// Progress bars and the logo.
+	"█", "#", "░", ".", "▀", "'", "▄", "_",
+	"🔍", "/:",
+	// The footer keeps its items together with no-break spaces.
+	"\u00a0", " ",
+)
+
+// limitedTerms are terminals that cannot draw taskg's glyphs.
+var limitedTerms = map[string]bool{
+	"linux": true, "vt100": true, "vt102": true, "vt220": true,
+	"ansi": true, "cons25": true, "dumb": true,
+}
+
+// SetASCII draws with ASCII characters only.
+func (m *TaskModel) SetASCII(enabled bool) {
+	m.ascii = enabled
+	m.spinner.Spinner = m.spinnerKind()
+}
+
+// spinnerKind is the spinner shown while tasks are discovered.
+func (m TaskModel) spinnerKind() spinner.Spinner {
+	if m.ascii {
+		return spinner.Line
+	}
+	return spinner.Dot
+}
+
+// LimitedTerminal reports whether the terminal described by env (e.g.
+// os.Getenv) lacks taskg's glyphs: the Linux console and other terminals of
+// limitedTerms, and locales that are not UTF-8.
+func LimitedTerminal(env func(string) string) bool {
+	if limitedTerms[env("TERM")] {
+		return true
+	}
+	locale := env("LC_ALL")
+	if locale == "" {
+		locale = env("LC_CTYPE")
+	}
+	if locale == "" {
+		locale = env("LANG")
+	}
+	// An unset or C locale says little: containers often run without one.
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return false
+	}
+	locale = strings.ToLower(locale)
+	return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+}
+
+// View renders the current screen, in ASCII if asked to.
+func (m TaskModel) View() string {
+	if m.ascii {
+		return asciiGlyphs.Replace(m.view())
+	}
+	return m.view()
+}
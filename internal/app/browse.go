@@ -0,0 +1,28 @@
+package app
+
+import "fmt"
+
+// In browse mode taskg shows Taskfiles fetched from a repository (see
+// `taskg browse`) without running anything from them: tasks cannot be run,
+// marked or edited, and neither `task --status` nor `task --summary` is
+// called, since both may evaluate the Taskfile's dynamic variables.
+
+// SetBrowseRoot shows the Taskfiles fetched into root from source read-only.
+// Unlike SetProjectRoot, root is neither remembered as a recent project nor
+// given a state file.
+func (m *TaskModel) SetBrowseRoot(root, source string) {
+	m.projectRoot = root
+	m.browseSource = source
+}
+
+// browsing reports whether the tasks are shown read-only.
+func (m TaskModel) browsing() bool { return m.browseSource != "" }
+
+// refuseReadOnly warns and returns true when browsing.
+func (m *TaskModel) refuseReadOnly() bool {
+	if !m.browsing() {
+		return false
+	}
+	m.setWarning(fmt.Sprintf("Browsing %s read-only: clone it to run or change tasks", m.browseSource))
+	return true
+}
@@ -0,0 +1,69 @@
+package app
+
+import "strings"
+
+// Variables with declared choices (the taskg_choices var or an enum: of a
+// required var) are a select in the pre-run form instead of a text input,
+// so only an allowed value can be passed.
+
+// isChoice reports whether field i of the form is a select.
+func (m TaskModel) isChoice(i int) bool { return len(m.modalVariables[i].Choices) > 0 }
+
+// hasChoices reports whether the form has a select.
+func (m TaskModel) hasChoices() bool {
+	for i := range m.modalVariables {
+		if m.isChoice(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleChoiceKey changes the value of select i: left and right (or h, l
+// and space) step through the choices, and other letters jump to the next
+// choice starting with them.
+func (m *TaskModel) handleChoiceKey(i int, key string) {
+	v := &m.modalVariables[i]
+	n := len(v.Choices)
+	switch key {
+	case "left", "h":
+		v.Choice = (v.Choice + n - 1) % n
+	case "right", "l", " ":
+		v.Choice = (v.Choice + 1) % n
+	default:
+		if len([]rune(key)) != 1 {
+			return
+		}
+		for step := 1; step <= n; step++ {
+			j := (v.Choice + step) % n
+			if strings.HasPrefix(strings.ToLower(v.Choices[j]), strings.ToLower(key)) {
+				v.Choice = j
+				return
+			}
+		}
+	}
+}
+
+// modalValue returns the value of field i.
+func (m TaskModel) modalValue(i int) string {
+	if m.isChoice(i) {
+		v := m.modalVariables[i]
+		return v.Choices[v.Choice]
+	}
+	return m.modalInputs[i].Value()
+}
+
+// renderChoice renders select i as its variable name and the choices, the
+// chosen one highlighted.
+func (m TaskModel) renderChoice(i int) string {
+	v := m.modalVariables[i]
+	parts := make([]string, len(v.Choices))
+	for j, c := range v.Choices {
+		if j == v.Choice {
+			parts[j] = m.theme.Highlight.Render("● " + c)
+		} else {
+			parts[j] = m.theme.Help.Render("○ " + c)
+		}
+	}
+	return m.theme.Highlight.Render("▪ ") + m.theme.Help.Render(v.Name+": ") + strings.Join(parts, "  ")
+}
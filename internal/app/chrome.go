@@ -0,0 +1,114 @@
+package app
+
+import "github.com/charmbracelet/lipgloss"
+
+// Alt+H, Alt+F and Alt+B hide or show the header, the footer and the borders
+// framing the screen, to give the list back their rows on small panes such
+// as a 12-line tmux split; hide_header, hide_footer and borderless in the
+// config file start with them hidden. Toasts still show without the footer.
+
+// SetChrome sets whether the header, the footer and the borders framing
+// the screen are hidden.
+func (m *TaskModel) SetChrome(hideHeader, hideFooter, borderless bool) {
+	m.hideHeader = hideHeader
+	m.hideFooter = hideFooter
+	m.borderless = borderless
+	m.setTheme(m.themeName)
+}
+
+// toggleHeader hides or shows the two header lines.
+func (m *TaskModel) toggleHeader() {
+	m.hideHeader = !m.hideHeader
+	m.ensureSelectionVisible()
+	if m.hideHeader {
+		m.setStatus("Hiding the header (M-H shows it)")
+	} else {
+		m.setStatus("Showing the header")
+	}
+}
+
+// toggleFooter hides or shows the footer.
+func (m *TaskModel) toggleFooter() {
+	m.hideFooter = !m.hideFooter
+	m.ensureSelectionVisible()
+	if m.hideFooter {
+		m.setStatus("Hiding the footer (M-F shows it)")
+	} else {
+		m.setStatus("Showing the footer")
+	}
+}
+
+// toggleBorders hides or shows the borders framing the screen.
+func (m *TaskModel) toggleBorders() {
+	m.borderless = !m.borderless
+	m.setTheme(m.themeName)
+	m.ensureSelectionVisible()
+	if m.borderless {
+		m.setStatus("Hiding the borders (M-B shows them)")
+	} else {
+		m.setStatus("Showing the borders")
+	}
+}
+
+// headerRows is the height of the header.
+func (m TaskModel) headerRows() int {
+	if m.hideHeader {
+		return 0
+	}
+	return 2
+}
+
+// tabsRows is the height of the tab strip, with its border and margin.
+func (m TaskModel) tabsRows() int {
+	if !m.showTabStrip() {
+		return 0
+	}
+	return 1 + m.theme.TabsContainer.GetVerticalFrameSize()
+}
+
+// searchRows is the height of the search box, with its border and margin.
+// Go-to mode shows its query in the same box.
+func (m TaskModel) searchRows() int {
+	if !m.searchMode && m.searchQuery == "" && !m.gotoMode {
+		return 0
+	}
+	return 1 + m.theme.SearchBox.GetVerticalFrameSize()
+}
+
+// footerRows is the height of the footer, which wraps on narrow screens.
+func (m TaskModel) footerRows() int {
+	if m.hideFooter {
+		return 0
+	}
+	return lipgloss.Height(m.renderFooter(m.listWidth()))
+}
+
+// tabsTop is the screen row of the tab strip.
+func (m TaskModel) tabsTop() int {
+	return m.theme.AppContainer.GetBorderTopSize() + m.theme.AppContainer.GetPaddingTop() + m.headerRows()
+}
+
+// listTop is the screen row of the first line of the task list.
+func (m TaskModel) listTop() int {
+	return m.tabsTop() + m.tabsRows() + m.searchRows()
+}
+
+// listIndexAt returns the index of the task shown at screen column x and row
+// y, or -1 when there is none.
+func (m TaskModel) listIndexAt(x, y int) int {
+	row := y - m.listTop()
+	if row < 0 {
+		return -1
+	}
+	if cols := m.gridColumns(); cols > 1 {
+		return m.listOffset + m.gridIndexAt(x, row/max(1, m.itemHeight))
+	}
+	for i := m.listOffset; i < len(m.filteredTasks); i++ {
+		h := lipgloss.Height(m.listRow(i, m.listWidth()))
+		if row < h {
+			return i
+		}
+		row -= h
+	}
+	return -1
+}
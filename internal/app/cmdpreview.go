@@ -0,0 +1,23 @@
+package app
+
+// Alt+P hides or shows the [cmd | cmd] line under each task name, so the
+// list fits about twice as many tasks; hide_commands: in the config file
+// starts with it hidden. The detail pane still shows the commands.
+
+// SetHideCommands sets whether the list starts without command lines.
+func (m *TaskModel) SetHideCommands(hide bool) {
+	m.hideCmds = hide
+	m.invalidateRows()
+	m.itemHeight = 0
+}
+
+// toggleCommandLines hides or shows the command line of every row.
+func (m *TaskModel) toggleCommandLines() {
+	m.SetHideCommands(!m.hideCmds)
+	m.ensureSelectionVisible()
+	if m.hideCmds {
+		m.setStatus("Hiding command lines")
+	} else {
+		m.setStatus("Showing command lines")
+	}
+}
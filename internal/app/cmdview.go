@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Command lines longer than their box are cut short. Shift+←/→ (or Alt+←/→)
+// scroll the command line of the selected task sideways, and Alt+X (or x with
+// type_to_search off) expands its box to list each command on a line of its
+// own. Both last until the selection moves to another task.
+
+// cmdScrollStep is how many columns Shift+←/→ scroll the command line by.
+const cmdScrollStep = 8
+
+// cmdIndent lines command lines up under the task text.
+const cmdIndent = "    "
+
+// cmdView returns how the commands of t are shown: scrolled by some columns,
+// or expanded. Only the selected task has its commands scrolled or expanded.
+func (m TaskModel) cmdView(t taskmeta.Task, selected bool) (scroll int, expanded bool) {
+	if !selected || m.cmdViewTask == "" || taskKey(t) != m.cmdViewTask {
+		return 0, false
+	}
+	return m.cmdScroll, m.cmdExpanded
+}
+
+// resetCmdView puts the commands back on one line once the selection moved
+// away from the task they were scrolled or expanded on.
+func (m *TaskModel) resetCmdView() {
+	if m.cmdViewTask == "" {
+		return
+	}
+	if t, ok := m.selectedTask(); !ok || taskKey(t) != m.cmdViewTask {
+		m.cmdViewTask, m.cmdScroll, m.cmdExpanded = "", 0, false
+	}
+}
+
+// cmdViewOn makes the selected task the one whose commands are scrolled or
+// expanded. It reports false when the task has no commands to show.
+func (m *TaskModel) cmdViewOn() (taskmeta.Task, bool) {
+	t, ok := m.selectedTask()
+	if !ok || len(t.Cmds) == 0 {
+		return t, false
+	}
+	if key := taskKey(t); m.cmdViewTask != key {
+		m.cmdViewTask, m.cmdScroll, m.cmdExpanded = key, 0, false
+	}
+	return t, true
+}
+
+// scrollCmds scrolls the command line of the selected task by step columns,
+// no further than its end.
+func (m *TaskModel) scrollCmds(step int) {
+	t, ok := m.cmdViewOn()
+	if !ok || m.hideCmds || m.cmdExpanded {
+		return
+	}
+	width := ansi.StringWidth(m.cmdText(t))
+	// Past the start, … takes the first column.
+	end := max(0, width-m.cmdWidth()+1)
+	m.cmdScroll = max(0, min(m.cmdScroll+step, end))
+}
+
+// toggleExpandCmds lists the commands of the selected task one per line, or
+// puts them back on one line.
+func (m *TaskModel) toggleExpandCmds() {
+	t, ok := m.cmdViewOn()
+	if !ok {
+		if _, task := m.selectedTask(); task {
+			m.setStatus("The task has no commands")
+		}
+		return
+	}
+	m.cmdExpanded = !m.cmdExpanded
+	m.cmdScroll = 0
+	m.ensureSelectionVisible()
+	if m.cmdExpanded {
+		m.setStatus(fmt.Sprintf("Showing all %d commands of %s (Alt+X again folds them)", len(t.Cmds), t.Name))
+	}
+}
+
+// cmdText is the one-line [cmd | cmd] preview of the commands of t. The
+// lines of multi-line commands are joined with "; ".
+func (m TaskModel) cmdText(t taskmeta.Task) string {
+	cmds := make([]string, len(t.Cmds))
+	for i, c := range t.Cmds {
+		cmds[i] = strings.ReplaceAll(strings.TrimSpace(c), "\n", "; ")
+	}
+	return m.masker.mask("[" + strings.Join(cmds, " | ") + "]")
+}
+
+// cmdWidth is the number of columns the command line of the selected row
+// shows.
+func (m TaskModel) cmdWidth() int {
+	width := m.listWidth()
+	if cols := m.gridColumns(); cols > 1 {
+		width = cellWidth(width, cols, m.selected%cols)
+	}
+	return width - m.theme.CommandBox.GetHorizontalPadding() - len(cmdIndent)
+}
+
+// renderCmdLine renders the command line of t within width columns,
+// scrolled by scroll columns.
+func (m TaskModel) renderCmdLine(t taskmeta.Task, scroll, width int) string {
+	// Keep same style whether selected or not so only task name pops.
+	text := m.theme.Description.Render(m.cmdText(t))
+	if scroll > 0 {
+		text = ansi.TruncateLeft(text, scroll, "…")
+	}
+	return ansi.Truncate(cmdIndent+text, width, "…")
+}
+
+// renderExpandedCmds renders each command of t on lines of its own within
+// width columns, the lines of long commands wrapped under their start.
+func (m TaskModel) renderExpandedCmds(t taskmeta.Task, width int) string {
+	textWidth := width - len(cmdIndent) - 2
+	var lines []string
+	for _, c := range t.Cmds {
+		for i, l := range strings.Split(m.masker.mask(strings.TrimSpace(c)), "\n") {
+			lead := "$ "
+			if i > 0 {
+				lead = "  "
+			}
+			wrapped := strings.Split(ansi.Wrap(l, max(1, textWidth), ""), "\n")
+			for j, w := range wrapped {
+				if j > 0 {
+					lead = "  "
+				}
+				lines = append(lines, cmdIndent+m.theme.Help.Render(lead)+m.theme.Description.Render(w))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// compiledEntry caches one task compiled by the go-task library.
+type compiledEntry struct {
+	task    *taskmeta.Compiled
+	err     error
+	loading bool
+}
+
+// compiledMsg delivers an asynchronously compiled task.
+type compiledMsg struct {
+	key  string
+	task *taskmeta.Compiled
+	err  error
+}
+
+// compileCmd compiles the selected task when the detail pane is open, like
+// summaryCmd, for its env and rendered commands. Results are cached until
+// the next refresh.
+func (m *TaskModel) compileCmd() tea.Cmd {
+	t, ok := m.selectedTask()
+	if !m.showDetail || !ok || m.projectRoot == "" || t.Backend != taskmeta.BackendTask {
+		return nil
+	}
+	key := taskKey(t)
+	if _, ok := m.compiled[key]; ok {
+		return nil
+	}
+	m.compiled[key] = compiledEntry{loading: true}
+	root, name := t.WorkDir(m.projectRoot), t.Name
+	return func() tea.Msg {
+		c, err := taskmeta.CompileTask(root, name)
+		return compiledMsg{key: key, task: c, err: err}
+	}
+}
+
+// envLines renders the environment t will see on top of taskg's own: the
+// Taskfile's env and dotenv values plus the direnv variables, the picked env
+// file and the overrides set with Ctrl+E, with secret-like values masked.
+func (m TaskModel) envLines(t taskmeta.Task) []string {
+	c := m.compiled[taskKey(t)].task
+	if c == nil {
+		return nil
+	}
+	vars := append([]taskmeta.EnvVar(nil), c.Env...)
+	for _, kv := range m.envFor(t) {
+		name, value, _ := strings.Cut(kv, "=")
+		i := 0
+		for i < len(vars) && vars[i].Name != name {
+			i++
+		}
+		if i == len(vars) {
+			vars = append(vars, taskmeta.EnvVar{})
+		}
+		vars[i] = taskmeta.EnvVar{Name: name, Value: value}
+	}
+	if len(vars) == 0 && len(c.Dotenv) == 0 {
+		return nil
+	}
+
+	header := "env:"
+	if len(c.Dotenv) > 0 {
+		header += m.theme.Help.Render(" (dotenv: " + strings.Join(c.Dotenv, ", ") + ")")
+	}
+	lines := []string{header}
+	overridden := m.envOverrides[t.Name]
+	fromFile := map[string]bool{}
+	for _, kv := range m.envFileVars(t) {
+		name, _, _ := strings.Cut(kv, "=")
+		fromFile[name] = true
+	}
+	fromDirenv := map[string]bool{}
+	for _, kv := range m.direnvVars() {
+		name, _, _ := strings.Cut(kv, "=")
+		fromDirenv[name] = true
+	}
+	for _, v := range vars {
+		line := " " + v.Name + "=" + v.Value
+		if v.Dynamic {
+			line = " " + v.Name + "=$(" + v.Value + ")"
+		}
+		line = m.theme.Command.Render(m.masker.mask(line))
+		switch _, set := overridden[v.Name]; {
+		case set:
+			line += m.theme.Help.Render(" (override)")
+		case fromFile[v.Name]:
+			line += m.theme.Help.Render(" (from " + m.envFileFor(t) + ")")
+		case fromDirenv[v.Name]:
+			line += m.theme.Help.Render(" (from direnv)")
+		case v.Shell:
+			line += m.theme.Help.Render(" (from shell)")
+		case v.Dynamic:
+			line += m.theme.Help.Render(" (sh)")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// toggleRenderedCmds switches the detail pane between the commands as
+// written and with their templates rendered.
+func (m *TaskModel) toggleRenderedCmds() {
+	m.renderedCmds = !m.renderedCmds
+	if m.renderedCmds {
+		m.showDetail = true
+		m.ensureSelectionVisible()
+		m.setStatus("Showing rendered commands")
+	} else {
+		m.setStatus("Showing commands as written")
+	}
+}
+
+// cmdLines renders the commands block of the detail pane: t's commands as
+// written, or rendered once the task is compiled when renderedCmds is on.
+func (m TaskModel) cmdLines(t taskmeta.Task) []string {
+	if !m.renderedCmds {
+		if len(t.Cmds) == 0 {
+			return nil
+		}
+		lines := []string{"commands:"}
+		for _, c := range t.Cmds {
+			lines = append(lines, m.theme.Command.Render(" - "+m.masker.mask(c)))
+		}
+		return lines
+	}
+	entry := m.compiled[taskKey(t)]
+	switch {
+	case t.Backend != taskmeta.BackendTask:
+		return []string{m.theme.Help.Render("Rendered commands are only available for Taskfile tasks")}
+	case entry.loading:
+		return []string{m.theme.Help.Render("Rendering commands…")}
+	case entry.err != nil:
+		return []string{m.theme.Error.Render("Cannot render commands: " + entry.err.Error())}
+	case entry.task == nil || len(entry.task.Cmds) == 0:
+		return nil
+	}
+	lines := []string{"commands " + m.theme.Help.Render("(rendered, F3 shows them as written)") + ":"}
+	for _, c := range entry.task.Cmds {
+		lines = append(lines, m.theme.Command.Render(" - "+m.masker.mask(c)))
+	}
+	return lines
+}
+
+// loopLines shows what the for: loops of t expand into, so it is clear what
+// a single run fans out to.
+func (m TaskModel) loopLines(t taskmeta.Task) []string {
+	c := m.compiled[taskKey(t)].task
+	if c == nil || len(c.Loops) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, l := range c.Loops {
+		kind, runs := "for", fmt.Sprintf("%d runs", len(l.Runs))
+		if l.Deps {
+			kind, runs = "deps for", fmt.Sprintf("%d parallel runs", len(l.Runs))
+		}
+		lines = append(lines, kind+": "+m.theme.Command.Render(m.masker.mask(l.Cmd))+
+			m.theme.Help.Render(" · "+l.Over+" · "+runs))
+		for _, r := range l.Runs {
+			lines = append(lines, m.theme.Command.Render("   "+m.masker.mask(r)))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pendingRun is a run waiting for the user to confirm it.
+type pendingRun struct {
+	task   taskmeta.Task
+	args   []string
+	inline bool
+	spawn  string // run_in target to open the task in (see spawn.go)
+	prompt bool   // taskg asks the task's own prompt (see prompt.go)
+	jobs   []*job // a parallel run waiting for the prompts of some jobs
+	trust  string // remote Taskfile URL to trust first (see remote.go)
+	plan   bool   // asked only to show the plan (see plan.go): Enter runs too
+	// parallel are the tasks --parallel runs next to task (see runopts.go)
+	parallel []taskmeta.Task
+	// container runs the task in its project's container (see container.go)
+	container bool
+}
+
+// SetConfirmPatterns sets the task name patterns (path.Match syntax, matched
+// case-insensitively) that ask for confirmation before running.
+func (m *TaskModel) SetConfirmPatterns(patterns []string) { m.confirmPatterns = patterns }
+
+// needsConfirm reports whether t is marked dangerous by the taskg_confirm var
+// or one of the configured patterns, including those of the remote policy.
+func (m TaskModel) needsConfirm(t taskmeta.Task) bool {
+	return t.Confirm || config.MatchAny(m.confirmPatterns, t.Name) || (m.policy != nil && config.MatchAny(m.policy.Confirm, t.Name))
+}
+
+// handleConfirmKeys runs the pending task on "y", or Enter when only its plan
+// was shown, and drops it on any other key.
+func (m *TaskModel) handleConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pendingRun
+	m.pendingRun = nil
+	if msg.String() == "y" || msg.String() == "Y" || (p.plan && msg.String() == "enter") {
+		m.runInline = p.inline
+		m.runSpawn = p.spawn
+		m.runContainer = p.container
+		switch {
+		case p.trust != "":
+			m.trust(p.task)
+			// Go on with the usual confirmation and prompt checks.
+			return m, m.execute(p.task, p.args)
+		case p.jobs != nil:
+			return m, m.startAnswered(p.jobs)
+		case p.prompt && p.inline:
+			return m, m.startAnswered([]*job{{task: p.task, args: p.args, container: p.container}})
+		}
+		return m, m.executeConfirmed(p.task, p.args)
+	}
+	if p.jobs != nil {
+		m.setStatus(fmt.Sprintf("Cancelled the run of %d tasks", len(p.jobs)))
+		return m, nil
+	}
+	m.setStatus(fmt.Sprintf("Cancelled %s", p.task.Name))
+	return m, nil
+}
+
+func (m TaskModel) renderConfirm() string {
+	p := m.pendingRun
+	title := "Confirm run"
+	if p.plan {
+		title = "Execution plan"
+	}
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render(title)
+	var sections []string
+	if p.trust != "" {
+		header = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.theme.HighlightColor).
+			Render("Trust remote Taskfile")
+		sections = []string{header, "",
+			"Run " + m.theme.Warning.Render(p.task.Name) + " from a remote Taskfile?",
+			m.theme.Help.Render(p.trust), "",
+			"Its commands were fetched over the network.",
+			"Trusting the source lets all of its tasks run in this project."}
+		helperText := fmt.Sprintf("%s trust & run  %s cancel",
+			m.theme.Highlight.Render("y"),
+			m.theme.Highlight.Render("N"))
+		sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+		return m.renderDialog(sections)
+	}
+	if p.jobs != nil {
+		sections = []string{header, "", fmt.Sprintf("Run %s?", m.theme.Warning.Render(fmt.Sprintf("%d tasks", len(p.jobs))))}
+		for _, j := range m.promptedJobs(p.jobs) {
+			sections = append(sections, m.theme.Help.Render(j.task.Name+": ")+j.task.Prompt)
+		}
+	} else {
+		line := p.task.Name
+		if len(p.args) > 0 {
+			line += " -- " + strings.Join(p.args, " ")
+		}
+		sections = []string{header, "", "Run " + m.theme.Warning.Render(line) + "?"}
+		if p.prompt {
+			sections = append(sections, p.task.Prompt)
+		} else if p.task.Desc != "" {
+			sections = append(sections, m.theme.Help.Render(p.task.Desc))
+		}
+		if len(p.parallel) > 0 {
+			sections = append(sections, "At once with "+m.theme.Warning.Render(taskNames(p.parallel)))
+		}
+		for _, t := range append([]taskmeta.Task{p.task}, p.parallel...) {
+			if steps := m.plan(t); steps != nil {
+				sections = append(sections, "")
+				if len(p.parallel) > 0 {
+					sections = append(sections, m.theme.Help.Render(t.Name+":"))
+				}
+				sections = append(sections, m.renderPlan(steps)...)
+			}
+		}
+	}
+	runKey := "y"
+	if p.plan {
+		runKey = "y/ENTER"
+	}
+	helperText := fmt.Sprintf("%s run  %s cancel",
+		m.theme.Highlight.Render(runKey),
+		m.theme.Highlight.Render("N"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Alt+C runs the selected task with `docker run` (or podman) in the
+// container configured for its project under containers: in the config
+// file. The project root is mounted at the container's workdir, and env
+// overrides are passed through by name.
+
+// SetContainers sets the containers configured per project root.
+func (m *TaskModel) SetContainers(containers map[string]config.Container) {
+	m.containers = containers
+}
+
+// containerFor returns the container configured for t's project.
+func (m TaskModel) containerFor(t taskmeta.Task) (config.Container, string, bool) {
+	return config.ContainerFor(m.containers, t.WorkDir(m.projectRoot))
+}
+
+// markForContainer runs the selected task in its project's container.
+func (m *TaskModel) markForContainer() tea.Cmd {
+	if len(m.filteredTasks) == 0 {
+		return nil
+	}
+	if _, _, ok := m.containerFor(m.filteredTasks[m.selected]); !ok {
+		m.setWarning("No container is configured for this project (see containers: in the config file)")
+		return nil
+	}
+	m.containerRequested = true
+	cmd := m.markForExecution(false)
+	m.containerRequested = false
+	return cmd
+}
+
+// containerInvocation wraps the command line bin args running t so it runs
+// in t's container. The variables of env are passed by name, so the engine
+// takes their values from its own environment. tty allocates a terminal,
+// which needs one on taskg's side too.
+func (m *TaskModel) containerInvocation(t taskmeta.Task, env []string, tty bool, bin string, args []string) (string, []string) {
+	c, root, ok := m.containerFor(t)
+	if !ok {
+		return bin, args
+	}
+	workdir := c.Workdir
+	if workdir == "" {
+		workdir = filepath.ToSlash(root)
+	}
+	dir := workdir
+	if rel, err := filepath.Rel(root, t.WorkDir(m.projectRoot)); err == nil && rel != "." {
+		dir = path.Join(workdir, filepath.ToSlash(rel))
+	}
+	out := []string{"run", "--rm"}
+	if tty {
+		out = append(out, "-it")
+	}
+	out = append(out, "-v", root+":"+workdir, "-w", dir)
+	for _, mount := range c.Mounts {
+		out = append(out, "-v", mount)
+	}
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		out = append(out, "-e", name)
+	}
+	out = append(out, c.Args...)
+	out = append(out, c.Image, bin)
+	return c.Engine, append(out, args...)
+}
+
+// jobInvocation is j's command line, in its container when it asks for one.
+func (m *TaskModel) jobInvocation(j *job) (string, []string) {
+	bin, args := j.invocation()
+	if !j.container {
+		return bin, args
+	}
+	tty := m.usePTY && len(m.jobs) == 1
+	return m.containerInvocation(j.task, append(m.envFor(j.task), m.experimentEnv(j.task)...), tty, bin, args)
+}
+
+// RunInvocation returns the command line of the task chosen for execution,
+// which runs in its container when it was started with Alt+C.
+func (m *TaskModel) RunInvocation() (string, []string) {
+	bin, args := m.runTarget.RunInvocation(m.lastCommand[1:])
+	if !m.runContainer {
+		return bin, args
+	}
+	return m.containerInvocation(m.runTarget, m.runEnv, true, bin, args)
+}
+
+// RunsInContainer reports whether the task chosen for execution runs in a
+// container.
+func (m TaskModel) RunsInContainer() bool { return m.runContainer }
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// A right click on a task, or m, opens a menu of what can be done with the
+// task, so running it with arguments or as a dry run, copying its command
+// and the like are one selection away instead of behind keys to remember.
+
+// contextAction is an entry of the context menu.
+type contextAction struct {
+	title string
+	key   string // dedicated shortcut, if any, shown as a hint
+	run   func(m *TaskModel, t taskmeta.Task) tea.Cmd
+}
+
+// contextActions lists the context menu entries in display order.
+func contextActions() []contextAction {
+	return []contextAction{
+		{"Run", "Enter", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			return m.markForExecution(false)
+		}},
+		{"Run with arguments…", "", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			return m.openArgsInput(t)
+		}},
+		{"Dry run: show the commands only", "", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			if !m.taskFlagsApply(t) {
+				return nil
+			}
+			return m.markForExecutionWith(true, []string{"--dry"})
+		}},
+		{"Copy command", "", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			m.copyCommand(t)
+			return nil
+		}},
+		{"Open in editor", "", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			return m.openInEditor(t)
+		}},
+		{"Favorite / unfavorite", "", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			m.toggleFavorite(t)
+			return nil
+		}},
+		{"View deps", "^D", func(m *TaskModel, t taskmeta.Task) tea.Cmd {
+			m.viewDeps(t)
+			return nil
+		}},
+	}
+}
+
+// openContextMenu opens the context menu of the selected task.
+func (m *TaskModel) openContextMenu() {
+	if _, ok := m.selectedTask(); !ok {
+		return
+	}
+	m.ctxMenu = true
+	m.ctxSelected = 0
+	m.ctxArgsMode = false
+}
+
+func (m *TaskModel) handleContextKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t, ok := m.selectedTask()
+	if !ok {
+		m.ctxMenu = false
+		return m, nil
+	}
+	if m.ctxArgsMode {
+		switch msg.String() {
+		case "esc":
+			m.ctxArgsMode = false
+			return m, nil
+		case "enter":
+			m.ctxMenu = false
+			return m, m.markForExecutionWith(false, append([]string{"--"}, strings.Fields(m.ctxArgs.Value())...))
+		}
+		var cmd tea.Cmd
+		m.ctxArgs, cmd = m.ctxArgs.Update(msg)
+		return m, cmd
+	}
+	actions := contextActions()
+	switch msg.String() {
+	case "esc", "q", "m":
+		m.ctxMenu = false
+	case "up", "k":
+		if m.ctxSelected > 0 {
+			m.ctxSelected--
+		}
+	case "down", "j":
+		if m.ctxSelected < len(actions)-1 {
+			m.ctxSelected++
+		}
+	case "enter":
+		m.ctxMenu = false
+		return m, actions[m.ctxSelected].run(m, t)
+	}
+	return m, nil
+}
+
+// taskFlagsApply reports whether flags of the task binary apply to t, and
+// warns when they do not.
+func (m *TaskModel) taskFlagsApply(t taskmeta.Task) bool {
+	if t.Backend != taskmeta.BackendTask || t.Script != "" {
+		m.setWarning(fmt.Sprintf("%s is not a Taskfile task, so Task's options do not apply to it", t.Name))
+		return false
+	}
+	return true
+}
+
+// openArgsInput asks for the arguments to run t with, which Task passes to
+// it as CLI_ARGS.
+func (m *TaskModel) openArgsInput(t taskmeta.Task) tea.Cmd {
+	if !m.taskFlagsApply(t) {
+		return nil
+	}
+	ti := textinput.New()
+	ti.Placeholder = "arguments, split on spaces"
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Prompt = "> "
+	ti.Focus()
+	m.ctxArgs = ti
+	m.ctxArgsMode = true
+	m.ctxMenu = true
+	return textinput.Blink
+}
+
+// copyCommand copies the command running t to the clipboard of the
+// terminal, which works over SSH and in tmux with set-clipboard on.
+func (m *TaskModel) copyCommand(t taskmeta.Task) {
+	bin, args := t.Invocation(nil)
+	line := taskmeta.CommandLine(bin, args)
+	termenv.Copy(line)
+	m.setStatus("Copied: " + line)
+}
+
+// openInEditor suspends the TUI and opens the Taskfile defining t in
+// $VISUAL/$EDITOR at the task. Tasks are rediscovered afterwards.
+func (m *TaskModel) openInEditor(t taskmeta.Task) tea.Cmd {
+	if m.refuseReadOnly() {
+		return nil
+	}
+	if t.Backend != taskmeta.BackendTask {
+		m.setWarning(fmt.Sprintf("Only Taskfile tasks can be opened, %s comes from %s", t.Name, t.Backend))
+		return nil
+	}
+	path, name, ok := taskmeta.TaskfileOf(t, m.projectRoot)
+	if !ok {
+		m.setWarning(fmt.Sprintf("Could not find the Taskfile defining %s", t.Name))
+		return nil
+	}
+	line, _ := taskmeta.TaskLine(path, name) // the top of the file will do
+	return tea.ExecProcess(editorCommandAt(path, line, 1), func(err error) tea.Msg {
+		return taskfileEditedMsg{err: err}
+	})
+}
+
+// toggleFavorite stars or unstars t. The smart sort lists favorites first.
+func (m *TaskModel) toggleFavorite(t taskmeta.Task) {
+	key := taskKey(t)
+	if m.favorites[key] {
+		delete(m.favorites, key)
+		m.setStatus(fmt.Sprintf("Removed %s from favorites", t.Name))
+	} else {
+		m.favorites[key] = true
+		m.setStatus(fmt.Sprintf("Added %s to favorites", t.Name))
+	}
+	if m.state != nil {
+		m.state.Favorites = slices.DeleteFunc(m.state.Favorites, func(k string) bool { return k == key })
+		if m.favorites[key] {
+			m.state.Favorites = append(m.state.Favorites, key)
+		}
+		if err := m.state.Save(); err != nil {
+			m.setWarning(fmt.Sprintf("Could not save favorites: %v", err))
+		}
+	}
+	m.invalidateRows()
+	if m.sortMode == "smart" {
+		m.buildTabs()
+		m.updateFilter()
+	}
+}
+
+// viewDeps shows the deps of t in the detail pane.
+func (m *TaskModel) viewDeps(t taskmeta.Task) {
+	if len(t.Deps) == 0 {
+		m.setStatus(fmt.Sprintf("%s has no deps", t.Name))
+		return
+	}
+	m.showDetail = true
+	m.ensureSelectionVisible()
+	m.setStatus(fmt.Sprintf("%s runs %s first", t.Name, strings.Join(t.Deps, ", ")))
+}
+
+func (m TaskModel) renderContextMenu() string {
+	t, _ := m.selectedTask()
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render(t.Name)
+	sections := []string{header, ""}
+
+	if m.ctxArgsMode {
+		sections = append(sections, "Run with arguments (CLI_ARGS):", m.ctxArgs.View())
+		helperText := fmt.Sprintf("%s run  %s back",
+			m.theme.Highlight.Render("ENTER"),
+			m.theme.Highlight.Render("ESC"))
+		sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+		return m.renderDialog(sections)
+	}
+
+	for i, a := range contextActions() {
+		hint := ""
+		if a.key != "" {
+			hint = "  " + m.theme.Help.Render(a.key)
+		}
+		if i == m.ctxSelected {
+			sections = append(sections, m.theme.Highlight.Render("▶ "+a.title)+hint)
+		} else {
+			sections = append(sections, "  "+a.title+hint)
+		}
+	}
+
+	helperText := fmt.Sprintf("%s select  %s move  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("↑↓"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+
+	return m.renderDialog(sections)
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Fixed rows of the task creation form; command rows follow them.
+const (
+	createName = iota
+	createDesc
+	createDir
+	createFirstCmd
+)
+
+// SetStartDir sets the directory a Taskfile.yml is created in when no
+// Taskfile was found, so the creation form works from the empty state.
+func (m *TaskModel) SetStartDir(dir string) { m.startDir = dir }
+
+// openCreateForm shows the form adding a new task to the project's Taskfile.
+func (m *TaskModel) openCreateForm() tea.Cmd {
+	if m.projectRoot == "" && m.startDir == "" || m.refuseReadOnly() || m.refuseGlobal("create tasks") {
+		return nil
+	}
+	m.createMode = true
+	m.createError = ""
+	m.createFocused = 0
+	m.createInputs = []textinput.Model{
+		newCreateInput("name, e.g. build"),
+		newCreateInput("description (optional)"),
+		newCreateInput("working directory (optional)"),
+		newCreateInput("command"),
+	}
+	m.createInputs[0].Focus()
+	return textinput.Blink
+}
+
+func newCreateInput(placeholder string) textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.CharLimit = 512
+	ti.Width = 50
+	return ti
+}
+
+func (m *TaskModel) handleCreateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.createMode = false
+		return m, nil
+	case "enter":
+		return m, m.submitCreateForm()
+	case "tab", "down":
+		m.focusCreateInput(m.createFocused + 1)
+		return m, textinput.Blink
+	case "shift+tab", "up":
+		m.focusCreateInput(m.createFocused - 1)
+		return m, textinput.Blink
+	case "ctrl+n":
+		m.createInputs = append(m.createInputs, newCreateInput("command"))
+		m.focusCreateInput(len(m.createInputs) - 1)
+		return m, textinput.Blink
+	case "ctrl+x":
+		if m.createFocused >= createFirstCmd && len(m.createInputs) > createFirstCmd+1 {
+			m.createInputs = append(m.createInputs[:m.createFocused], m.createInputs[m.createFocused+1:]...)
+			m.focusCreateInput(min(m.createFocused, len(m.createInputs)-1))
+		}
+		return m, textinput.Blink
+	}
+
+	var cmd tea.Cmd
+	m.createInputs[m.createFocused], cmd = m.createInputs[m.createFocused].Update(msg)
+	return m, cmd
+}
+
+func (m *TaskModel) focusCreateInput(i int) {
+	n := len(m.createInputs)
+	m.createInputs[m.createFocused].Blur()
+	m.createFocused = (i%n + n) % n
+	m.createInputs[m.createFocused].Focus()
+}
+
+// submitCreateForm appends the task to the Taskfile and rediscovers tasks,
+// selecting the new one. Errors keep the form open.
+func (m *TaskModel) submitCreateForm() tea.Cmd {
+	value := func(i int) string { return strings.TrimSpace(m.createInputs[i].Value()) }
+	t := taskmeta.NewTask{Name: value(createName), Desc: value(createDesc), Dir: value(createDir)}
+	for i := createFirstCmd; i < len(m.createInputs); i++ {
+		if c := value(i); c != "" {
+			t.Cmds = append(t.Cmds, c)
+		}
+	}
+	root := m.projectRoot
+	if root == "" {
+		root = m.startDir
+	}
+	path, err := taskmeta.AppendTask(root, t)
+	if err != nil {
+		m.createError = err.Error()
+		return nil
+	}
+	m.createMode = false
+	if m.projectRoot == "" {
+		m.SetProjectRoot(root)
+		m.projectName = filepath.Base(root)
+	}
+	m.pendingUI = &state.UIState{Selected: taskKey(taskmeta.Task{Name: t.Name, Backend: taskmeta.BackendTask})}
+	m.setStatus(fmt.Sprintf("Added %s to %s", t.Name, filepath.Base(path)))
+	return m.refreshCmd()
+}
+
+func (m TaskModel) renderCreateForm() string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("New task")
+	sections := []string{header}
+	labels := []string{"name", "desc", "dir"}
+	for i := range m.createInputs {
+		label := "cmd"
+		if i < len(labels) {
+			label = labels[i]
+		}
+		m.createInputs[i].Prompt = fmt.Sprintf("%-5s ", label)
+		m.createInputs[i].PromptStyle = m.theme.Highlight
+		sections = append(sections, m.createInputs[i].View())
+	}
+	if m.createError != "" {
+		sections = append(sections, m.theme.Error.Render(m.createError))
+	}
+	helperText := fmt.Sprintf("%s create  %s add command  %s remove command  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("^N"),
+		m.theme.Highlight.Render("^X"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// detailHeight is the number of rows the detail pane occupies, borders included.
+const detailHeight = 10
+
+// summaryEntry caches `task --summary` output for one task.
+type summaryEntry struct {
+	text    string
+	err     error
+	loading bool
+}
+
+// summaryMsg delivers an asynchronously fetched summary.
+type summaryMsg struct {
+	key  string
+	text string
+	err  error
+}
+
+// selectedTask returns the highlighted task, if any.
+func (m *TaskModel) selectedTask() (taskmeta.Task, bool) {
+	if m.selected < 0 || m.selected >= len(m.filteredTasks) || isSection(m.filteredTasks[m.selected]) {
+		return taskmeta.Task{}, false
+	}
+	return m.filteredTasks[m.selected], true
+}
+
+// summaryCmd fetches the summary of the selected task when the detail pane is
+// open and it is not cached yet. Results are cached until the next refresh.
+func (m *TaskModel) summaryCmd() tea.Cmd {
+	t, ok := m.selectedTask()
+	if !m.showDetail || !ok || m.projectRoot == "" || m.browsing() || t.Backend != taskmeta.BackendTask || t.Script != "" {
+		return nil
+	}
+	key := taskKey(t)
+	if _, ok := m.summaries[key]; ok {
+		return nil
+	}
+	m.summaries[key] = summaryEntry{loading: true}
+	root, name := t.WorkDir(m.projectRoot), t.Name
+	return func() tea.Msg {
+		text, err := taskmeta.Summary(root, name)
+		return summaryMsg{key: key, text: text, err: err}
+	}
+}
+
+// detailLines returns the detail pane content for t: Task's own summary when
+// available, otherwise what discovery parsed from the Taskfile, or its YAML
+// after Alt+Y.
+func (m TaskModel) detailLines(t taskmeta.Task) []string {
+	if m.showYAML {
+		return m.yamlLines(t)
+	}
+	entry := m.summaries[taskKey(t)]
+	summary := strings.Split(m.masker.mask(entry.text), "\n")
+	// Platform restrictions and notes go first so the pane's height never
+	// cuts them off.
+	var head []string
+	for _, block := range [][]string{m.platformLines(t), m.remoteLines(t), m.promptLines(t), m.noteLines(t)} {
+		if block != nil {
+			head = append(append(head, block...), "")
+		}
+	}
+	// Loop expansions and the env come last; Shift+↓ scrolls to them.
+	var tail []string
+	for _, block := range [][]string{m.loopLines(t), m.envLines(t)} {
+		if block != nil {
+			tail = append(append(tail, ""), block...)
+		}
+	}
+	if entry.text != "" {
+		if m.renderedCmds {
+			// The summary lists the commands as written.
+			if cmds := m.cmdLines(t); cmds != nil {
+				head = append(append(head, cmds...), "")
+			}
+		}
+		return append(append(head, summary...), tail...)
+	}
+
+	lines := []string{m.theme.TaskName.Render(t.Name)}
+	if t.Desc != "" {
+		lines = append(lines, m.theme.Description.Render(t.Desc))
+	}
+	if platform := m.platformLines(t); platform != nil {
+		lines = append(append(lines, ""), platform...)
+	}
+	if remote := m.remoteLines(t); remote != nil {
+		lines = append(append(lines, ""), remote...)
+	}
+	if prompt := m.promptLines(t); prompt != nil {
+		lines = append(append(lines, ""), prompt...)
+	}
+	if note := m.noteLines(t); note != nil {
+		lines = append(append(lines, ""), note...)
+	}
+	if len(t.Deps) > 0 {
+		lines = append(lines, "", "dependencies:")
+		for _, d := range t.Deps {
+			lines = append(lines, m.theme.Command.Render(" - "+d))
+		}
+	}
+	if cmds := m.cmdLines(t); cmds != nil {
+		lines = append(append(lines, ""), cmds...)
+	}
+	lines = append(lines, tail...)
+	if entry.loading {
+		lines = append(lines, "", m.theme.Help.Render("Loading task --summary…"))
+	}
+	return lines
+}
+
+// scrollDetail moves the detail pane of the selected task by one line.
+// Switching tasks starts the pane at the top again.
+func (m *TaskModel) scrollDetail(down bool) {
+	t, ok := m.selectedTask()
+	if !ok {
+		return
+	}
+	if key := taskKey(t); m.detailTask != key {
+		m.detailTask, m.detailOffset = key, 0
+	}
+	if down {
+		m.detailOffset = min(m.detailOffset+1, max(0, len(m.detailLines(t))-(detailHeight-2)))
+	} else if m.detailOffset > 0 {
+		m.detailOffset--
+	}
+}
+
+// noteLines renders t's local note for the detail pane.
+func (m TaskModel) noteLines(t taskmeta.Task) []string {
+	note := m.noteFor(t)
+	if note == "" {
+		return nil
+	}
+	lines := []string{m.theme.Accent.Render("✎ note:")}
+	for _, l := range strings.Split(note, "\n") {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+// renderDetail renders the detail pane for the selected task at the given width.
+func (m TaskModel) renderDetail(width int) string {
+	t, ok := m.selectedTask()
+	var lines []string
+	if ok {
+		lines = m.detailLines(t)
+	}
+	contentHeight := detailHeight - 2
+	if ok && m.detailTask == taskKey(t) && m.detailOffset > 0 {
+		lines = lines[min(m.detailOffset, len(lines)):]
+	}
+	if len(lines) > contentHeight {
+		lines = append(lines[:contentHeight-1], m.theme.Help.Render("… Shift+↓ for more"))
+	}
+	for i, l := range lines {
+		lines[i] = truncateStringToWidth(l, width-4)
+	}
+	for len(lines) < contentHeight {
+		lines = append(lines, "")
+	}
+	return m.theme.CommandBox.Copy().Width(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// When the project root has an .envrc and direnv is installed, taskg asks
+// direnv for the environment the project's shell would have and adds it to
+// every task it runs, below env files and overrides. The header shows
+// "direnv" while it applies.
+
+// direnvMsg carries the variables direnv exported for root.
+type direnvMsg struct {
+	root string
+	env  []string
+	err  error
+}
+
+// direnvCmd evaluates the project's .envrc in the background, or returns
+// nil when there is none or direnv is not installed.
+func (m *TaskModel) direnvCmd() tea.Cmd {
+	root := m.projectRoot
+	if root == "" || m.browsing() {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(root, ".envrc")); err != nil {
+		return nil
+	}
+	if _, err := exec.LookPath("direnv"); err != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		env, err := direnvExport(root)
+		return direnvMsg{root: root, env: env, err: err}
+	}
+}
+
+// direnvExport runs `direnv export json` in dir and returns the variables it
+// sets as sorted KEY=value pairs. direnv's own DIRENV_* bookkeeping is left
+// out, and so are unset variables, which only occur when taskg was started
+// from a shell that already loaded another .envrc.
+func direnvExport(dir string) ([]string, error) {
+	cmd := exec.Command("direnv", "export", "json")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// direnv explains e.g. a blocked .envrc on its last stderr line.
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if msg := strings.TrimPrefix(lines[len(lines)-1], "direnv: "); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		// Nothing differs from taskg's own environment.
+		return nil, nil
+	}
+	var vars map[string]*string
+	if err := json.Unmarshal(out, &vars); err != nil {
+		return nil, fmt.Errorf("reading direnv export: %w", err)
+	}
+	var env []string
+	for k, v := range vars {
+		if v == nil || strings.HasPrefix(k, "DIRENV_") {
+			continue
+		}
+		env = append(env, k+"="+*v)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+func (m *TaskModel) handleDirenv(msg direnvMsg) {
+	if msg.root != m.projectRoot {
+		return // the user switched projects meanwhile
+	}
+	if msg.err != nil {
+		m.direnvRoot, m.direnvEnv = "", nil
+		m.setWarning(fmt.Sprintf("direnv: %v", msg.err))
+		return
+	}
+	m.direnvRoot, m.direnvEnv = msg.root, msg.env
+}
+
+// direnvVars returns the variables direnv exported for the current project.
+func (m TaskModel) direnvVars() []string {
+	if m.direnvRoot == "" || m.direnvRoot != m.projectRoot {
+		return nil
+	}
+	return m.direnvEnv
+}
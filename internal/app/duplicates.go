@@ -0,0 +1,28 @@
+package app
+
+import "taskg/internal/taskmeta"
+
+// duplicateKeys returns the keys shared by more than one task. Discovery
+// qualifies colliding names where it can; what is left is shown with the
+// Taskfile each entry comes from.
+func duplicateKeys(tasks []taskmeta.Task) map[string]bool {
+	seen := make(map[string]int, len(tasks))
+	for _, t := range tasks {
+		seen[taskKey(t)]++
+	}
+	dups := make(map[string]bool)
+	for key, n := range seen {
+		if n > 1 {
+			dups[key] = true
+		}
+	}
+	return dups
+}
+
+// sourceLabel names the Taskfile t comes from, relative to the project.
+func (m TaskModel) sourceLabel(t taskmeta.Task) string {
+	if t.Taskfile == "" {
+		return "duplicate"
+	}
+	return m.relPath(t.Taskfile)
+}
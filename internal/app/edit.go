@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openTaskEditor loads the YAML of the selected task into the edit overlay.
+func (m *TaskModel) openTaskEditor() tea.Cmd {
+	t, ok := m.selectedTask()
+	if !ok || m.refuseReadOnly() {
+		return nil
+	}
+	if t.Backend != taskmeta.BackendTask {
+		m.setWarning(fmt.Sprintf("Only Taskfile tasks can be edited, %s comes from %s", t.Name, t.Backend))
+		return nil
+	}
+	path, name, ok := taskmeta.TaskfileOf(t, m.projectRoot)
+	if !ok {
+		m.setWarning(fmt.Sprintf("Could not find the Taskfile defining %s", t.Name))
+		return nil
+	}
+	body, err := taskmeta.TaskYAML(path, name)
+	if err != nil {
+		m.setError(fmt.Sprintf("Cannot edit %s: %v", t.Name, err))
+		return nil
+	}
+	ta := textarea.New()
+	ta.ShowLineNumbers = true
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(max(40, min(m.width-12, 90)))
+	ta.SetHeight(max(6, min(m.height-14, 20)))
+	ta.SetValue(body)
+	ta.Focus()
+	m.editMode = true
+	m.editTask = t
+	m.editPath = path
+	m.editName = name
+	m.editError = ""
+	m.editInput = ta
+	return textarea.Blink
+}
+
+func (m *TaskModel) handleEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editMode = false
+		return m, nil
+	case "ctrl+s":
+		if err := taskmeta.ReplaceTaskYAML(m.editPath, m.editName, m.editInput.Value()); err != nil {
+			m.editError = err.Error()
+			return m, nil
+		}
+		m.editMode = false
+		m.setStatus(fmt.Sprintf("Saved %s in %s", m.editTask.Name, filepath.Base(m.editPath)))
+		return m, m.refreshCmd()
+	case "tab":
+		// YAML is indented with spaces
+		m.editInput.InsertString("  ")
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.editInput, cmd = m.editInput.Update(msg)
+	return m, cmd
+}
+
+func (m TaskModel) renderTaskEditor() string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Edit " + m.editTask.Name)
+	sections := []string{
+		header,
+		m.theme.Help.Render(m.relPath(m.editPath)),
+		"",
+		m.editInput.View(),
+	}
+	if m.editError != "" {
+		sections = append(sections, "", m.theme.Error.Render(m.editError))
+	}
+	helperText := fmt.Sprintf("%s save  %s cancel",
+		m.theme.Highlight.Render("^S"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
+
+// relPath shows path relative to the project root when it lies inside it.
+func (m TaskModel) relPath(path string) string {
+	if m.projectRoot == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(m.projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
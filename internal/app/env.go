@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// envKeyRe validates environment variable names typed in the editor.
+var envKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// openEnvEditor shows the env override overlay for the selected task,
+// pre-filled with the overrides currently set for it.
+func (m *TaskModel) openEnvEditor() tea.Cmd {
+	if len(m.filteredTasks) == 0 || m.refuseReadOnly() {
+		return nil
+	}
+	name := m.filteredTasks[m.selected].Name
+	m.envMode = true
+	m.envTask = name
+	m.envError = ""
+	m.envFocused = 0
+	m.envInputs = nil
+
+	overrides := m.envOverrides[name]
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		m.envInputs = append(m.envInputs, newEnvInput(k+"="+overrides[k]))
+	}
+	if len(m.envInputs) == 0 {
+		m.envInputs = append(m.envInputs, newEnvInput(""))
+	}
+	_, m.envRemember = m.state.Env[name]
+	m.envInputs[0].Focus()
+	return textinput.Blink
+}
+
+func newEnvInput(value string) textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "KEY=value"
+	ti.CharLimit = 512
+	ti.Width = 50
+	ti.SetValue(value)
+	return ti
+}
+
+func (m *TaskModel) handleEnvKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.envMode = false
+		return m, nil
+	case "enter":
+		overrides, err := m.parseEnvInputs()
+		if err != nil {
+			m.envError = err.Error()
+			return m, nil
+		}
+		m.applyEnvOverrides(overrides)
+		m.envMode = false
+		return m, nil
+	case "tab", "down":
+		m.focusEnvInput(m.envFocused + 1)
+		return m, textinput.Blink
+	case "shift+tab", "up":
+		m.focusEnvInput(m.envFocused - 1)
+		return m, textinput.Blink
+	case "ctrl+n":
+		m.envInputs = append(m.envInputs, newEnvInput(""))
+		m.focusEnvInput(len(m.envInputs) - 1)
+		return m, textinput.Blink
+	case "ctrl+x":
+		m.envInputs = append(m.envInputs[:m.envFocused], m.envInputs[m.envFocused+1:]...)
+		if len(m.envInputs) == 0 {
+			m.envInputs = append(m.envInputs, newEnvInput(""))
+		}
+		m.focusEnvInput(min(m.envFocused, len(m.envInputs)-1))
+		return m, textinput.Blink
+	case "ctrl+s":
+		m.envRemember = !m.envRemember
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.envInputs[m.envFocused], cmd = m.envInputs[m.envFocused].Update(msg)
+	return m, cmd
+}
+
+func (m *TaskModel) focusEnvInput(i int) {
+	n := len(m.envInputs)
+	m.envInputs[m.envFocused].Blur()
+	m.envFocused = (i%n + n) % n
+	m.envInputs[m.envFocused].Focus()
+}
+
+// parseEnvInputs converts the KEY=value rows into a map, skipping blank rows.
+func (m *TaskModel) parseEnvInputs() (map[string]string, error) {
+	out := make(map[string]string)
+	for i, in := range m.envInputs {
+		raw := strings.TrimSpace(in.Value())
+		if raw == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(raw, "=")
+		if !ok || !envKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("row %d: expected KEY=value", i+1)
+		}
+		out[key] = value
+	}
+	return out, nil
+}
+
+// applyEnvOverrides stores overrides for the edited task for this session and,
+// when "remember" is on, in the project state.
+func (m *TaskModel) applyEnvOverrides(overrides map[string]string) {
+	name := m.envTask
+	if len(overrides) == 0 {
+		delete(m.envOverrides, name)
+	} else {
+		m.envOverrides[name] = overrides
+	}
+
+	_, wasRemembered := m.state.Env[name]
+	if m.envRemember && len(overrides) > 0 {
+		if m.state.Env == nil {
+			m.state.Env = make(map[string]map[string]string)
+		}
+		m.state.Env[name] = overrides
+	} else {
+		delete(m.state.Env, name)
+	}
+	if m.envRemember || wasRemembered {
+		if err := m.state.Save(); err != nil {
+			m.setWarning(fmt.Sprintf("Could not save state: %v", err))
+			return
+		}
+	}
+	m.setStatus(fmt.Sprintf("%d env override(s) set for %s", len(overrides), name))
+}
+
+// envFor returns the project's direnv variables, those of the task's env
+// file and its overrides, later ones winning, as KEY=value pairs ready for
+// exec.Cmd.Env.
+func (m *TaskModel) envFor(t taskmeta.Task) []string {
+	overrides := m.envOverrides[t.Name]
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := append(slices.Clone(m.direnvVars()), m.envFileVars(t)...)
+	for _, k := range keys {
+		env = append(env, k+"="+overrides[k])
+	}
+	return env
+}
+
+func (m TaskModel) renderEnvEditor() string {
+	sections := []string{}
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Environment for " + m.envTask)
+	sections = append(sections, header)
+
+	for i := range m.envInputs {
+		m.envInputs[i].Prompt = "▪ "
+		m.envInputs[i].PromptStyle = m.theme.Highlight
+		sections = append(sections, m.envInputs[i].View())
+	}
+
+	remember := "[ ]"
+	if m.envRemember {
+		remember = "[x]"
+	}
+	sections = append(sections, m.theme.Accent.Render(remember+" remember for this task"))
+
+	if m.envError != "" {
+		sections = append(sections, m.theme.Error.Render(m.envError))
+	}
+
+	helperText := fmt.Sprintf("%s apply  %s add  %s remove  %s remember  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("^N"),
+		m.theme.Highlight.Render("^X"),
+		m.theme.Highlight.Render("^S"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, m.theme.Help.Copy().Italic(true).Render(helperText))
+
+	dialogBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.HighlightColor).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBox,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
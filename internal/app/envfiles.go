@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// When the directory a task runs in has more than one env file (.env,
+// .env.staging, .env.prod, ...), running it first asks which one to load
+// into the task's environment. The choice is remembered per task in
+// .taskg/state.json and preselected next time; env overrides (Ctrl+E) win
+// over the file.
+
+// envFiles lists the env files in dir: .env and .env.<profile>, leaving
+// out templates such as .env.example.
+func envFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || (name != ".env" && !strings.HasPrefix(name, ".env.")) {
+			continue
+		}
+		switch strings.TrimPrefix(filepath.Ext(name), ".") {
+		case "example", "sample", "template", "dist", "bak", "swp":
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// readEnvFile reads KEY=value lines from a dotenv file. Blank lines, #
+// comments and an "export " prefix are allowed, and quoted values are
+// unquoted.
+func readEnvFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var env []string
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", filepath.Base(path), n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			if v, err := strconv.Unquote(value); err == nil {
+				value = v
+			}
+		case strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2:
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, sc.Err()
+}
+
+// envFileFor returns the env file picked for t, or "" for none.
+func (m TaskModel) envFileFor(t taskmeta.Task) string {
+	if m.state == nil {
+		return ""
+	}
+	return m.state.EnvFiles[taskKey(t)]
+}
+
+// envFileVars returns the variables of the env file picked for t.
+func (m *TaskModel) envFileVars(t taskmeta.Task) []string {
+	name := m.envFileFor(t)
+	if name == "" {
+		return nil
+	}
+	env, err := readEnvFile(filepath.Join(t.WorkDir(m.projectRoot), name))
+	if err != nil {
+		m.setWarning(fmt.Sprintf("Could not load %s: %v", name, err))
+		return nil
+	}
+	return env
+}
+
+// openEnvFilePicker asks which env file to run t with, when there is more
+// than one to choose from.
+func (m *TaskModel) openEnvFilePicker(t taskmeta.Task) bool {
+	files := envFiles(t.WorkDir(m.projectRoot))
+	if len(files) < 2 || m.state == nil {
+		return false
+	}
+	m.envFileMode = true
+	m.envFileList = files
+	// The last entry runs without an env file.
+	m.envFileSelected = len(files)
+	if name, ok := m.state.EnvFiles[taskKey(t)]; !ok {
+		m.envFileSelected = 0
+	} else if i := slices.Index(files, name); i >= 0 {
+		m.envFileSelected = i
+	}
+	return true
+}
+
+func (m *TaskModel) handleEnvFileKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(m.envFileList) + 1
+	switch msg.String() {
+	case "esc", "q":
+		m.envFileMode = false
+		m.runFlags = nil
+		m.setStatus("Cancelled")
+	case "up", "k":
+		m.envFileSelected = (m.envFileSelected + n - 1) % n
+	case "down", "j":
+		m.envFileSelected = (m.envFileSelected + 1) % n
+	case "enter":
+		m.envFileMode = false
+		task := m.filteredTasks[m.selected]
+		name := ""
+		if m.envFileSelected < len(m.envFileList) {
+			name = m.envFileList[m.envFileSelected]
+		}
+		if m.state.EnvFiles == nil {
+			m.state.EnvFiles = make(map[string]string)
+		}
+		if old, ok := m.state.EnvFiles[taskKey(task)]; !ok || old != name {
+			m.state.EnvFiles[taskKey(task)] = name
+			if err := m.state.Save(); err != nil {
+				m.setWarning(fmt.Sprintf("Could not save state: %v", err))
+			}
+		}
+		return m, m.prepareRun(task)
+	}
+	return m, nil
+}
+
+func (m TaskModel) renderEnvFilePicker() string {
+	task := m.filteredTasks[m.selected]
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Env file for " + task.Name)
+	sections := []string{header, ""}
+	for i := 0; i <= len(m.envFileList); i++ {
+		line := m.theme.Accent.Render("No env file")
+		if i < len(m.envFileList) {
+			line = m.envFileList[i]
+		}
+		prefix := "  "
+		if i == m.envFileSelected {
+			prefix = m.theme.Highlight.Render("▶ ")
+		}
+		sections = append(sections, prefix+line)
+	}
+	helperText := fmt.Sprintf("%s run  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
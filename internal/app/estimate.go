@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"taskg/internal/taskmeta"
+)
+
+// Successful runs record how long they took in the project state. The median
+// of the recent ones is shown next to the task, and drives the progress bar
+// of in-TUI runs.
+
+// progressWidth is the width of the progress bar in cells.
+const progressWidth = 12
+
+// estimate returns the median duration of t's recent successful runs.
+func (m TaskModel) estimate(t taskmeta.Task) (time.Duration, bool) {
+	if m.state == nil {
+		return 0, false
+	}
+	return m.state.Estimate(taskKey(t))
+}
+
+// formatEstimate renders d compactly, e.g. "42s" or "3m5s".
+func formatEstimate(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.Round(time.Second).String()
+}
+
+// approx renders an estimated duration, e.g. "~42s".
+func approx(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return "~" + formatEstimate(d)
+}
+
+// estimateBadge renders the typical duration of t for the task list.
+func (m TaskModel) estimateBadge(t taskmeta.Task) string {
+	d, ok := m.estimate(t)
+	if !ok {
+		return ""
+	}
+	return m.theme.Help.Render(approx(d))
+}
+
+// renderProgress renders a progress bar and the time left for the running
+// job j, based on its estimate. Runs that take longer than usual show how
+// far over they are instead.
+func (m TaskModel) renderProgress(j *job) string {
+	d, ok := m.estimate(j.task)
+	if !ok || d <= 0 {
+		return ""
+	}
+	elapsed := time.Since(j.start)
+	if elapsed > d {
+		return m.theme.Warning.Render(fmt.Sprintf("%s over the usual %s", formatEstimate(elapsed-d), approx(d)))
+	}
+	filled := int(float64(progressWidth) * float64(elapsed) / float64(d))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressWidth-filled)
+	return m.theme.Highlight.Render(bar) + m.theme.Help.Render(fmt.Sprintf(" %d%% · %s left", int(100*elapsed/d), approx(d-elapsed)))
+}
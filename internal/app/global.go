@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+)
+
+// In global mode (`taskg --global`) the tasks of every registered project
+// are listed together, grouped by project as in recursive mode, and each one
+// runs from its own project root. Run history, notes and logs are kept in
+// state.GlobalDir, as they belong to no single project.
+
+// SetGlobal lists the tasks of projects, keeping the local state in root.
+// Unlike SetProjectRoot, root is not remembered as a recent project. Search
+// starts out across all projects.
+func (m *TaskModel) SetGlobal(root string, projects []state.Project) {
+	m.projectRoot = root
+	m.global = projects
+	m.searchGlobal = true
+	m.loadState(root)
+}
+
+// globalMode reports whether the registered projects are listed.
+func (m TaskModel) globalMode() bool { return m.global != nil }
+
+// discoverGlobal discovers the tasks of the registered projects.
+func (m TaskModel) discoverGlobal(discover func(string) ([]taskmeta.Task, error)) ([]taskmeta.Task, error) {
+	roots := make([]string, len(m.global))
+	names := make([]string, len(m.global))
+	for i, p := range m.global {
+		roots[i], names[i] = p.Root, p.Name
+	}
+	return taskmeta.DiscoverProjects(roots, names, discover)
+}
+
+// refuseGlobal warns and returns true in global mode, for actions that need
+// a single project.
+func (m *TaskModel) refuseGlobal(action string) bool {
+	if !m.globalMode() {
+		return false
+	}
+	m.setWarning(fmt.Sprintf("Cannot %s in global mode: open the project itself", action))
+	return true
+}
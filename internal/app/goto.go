@@ -0,0 +1,77 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ' or ; starts go-to mode: letters typed next jump the selection to the
+// first task whose name starts with them, like type-ahead in file managers,
+// but the list is not filtered. ↑/↓ move between the tasks that match,
+// Enter runs the selected one and Esc leaves the selection where it is.
+
+// openGoto starts go-to mode.
+func (m *TaskModel) openGoto() {
+	if len(m.filteredTasks) == 0 {
+		return
+	}
+	m.gotoMode = true
+	m.gotoQuery = ""
+	m.gotoMiss = false
+}
+
+func (m *TaskModel) handleGotoKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.gotoMode = false
+	case "enter":
+		m.gotoMode = false
+		return m, m.markForExecution(false)
+	case "down", "tab":
+		m.gotoMatch(m.selected+1, 1)
+	case "up", "shift+tab":
+		m.gotoMatch(m.selected-1, -1)
+	case "backspace":
+		if r := []rune(m.gotoQuery); len(r) > 0 {
+			m.gotoQuery = string(r[:len(r)-1])
+			m.gotoMiss = m.gotoQuery != "" && !m.gotoMatch(0, 1)
+		}
+	default:
+		if msg.Type != tea.KeyRunes {
+			// Any other key ends go-to mode and does what it does in the list.
+			m.gotoMode = false
+			return m.handleKeys(msg)
+		}
+		m.gotoQuery += string(msg.Runes)
+		m.gotoMiss = !m.gotoMatch(0, 1)
+	}
+	return m, nil
+}
+
+// gotoMatch selects the first task from index from on, going by step and
+// wrapping around, whose name starts with the go-to query. It reports false
+// when there is none.
+func (m *TaskModel) gotoMatch(from, step int) bool {
+	q := strings.ToLower(m.gotoQuery)
+	n := len(m.filteredTasks)
+	for k := 0; k < n; k++ {
+		i := ((from+k*step)%n + n) % n
+		t := m.filteredTasks[i]
+		if !isSection(t) && strings.HasPrefix(strings.ToLower(t.Name), q) {
+			m.selected = i
+			m.ensureSelectionVisible()
+			return true
+		}
+	}
+	return false
+}
+
+// gotoView renders the go-to query in place of the search box.
+func (m TaskModel) gotoView() string {
+	query := m.gotoQuery
+	if m.gotoMiss {
+		query = m.theme.Error.Render(query) + m.theme.Help.Render("  no task starts with it")
+	}
+	return "→ go to: " + query + m.theme.Help.Render("  ( ↑↓ next match  enter run  esc done )")
+}
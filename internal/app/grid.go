@@ -0,0 +1,139 @@
+package app
+
+import (
+	"strings"
+
+	"taskg/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// On terminals wide enough for several columns of grid_column_width cells,
+// the task list is laid out as a grid of up to grid_columns boxes per row.
+// Tasks fill the grid row by row, so up/down move a whole row and left/right
+// move within it; at the ends of a row left/right switch tabs as before.
+
+// gridGap is the space between two grid columns.
+const gridGap = 1
+
+// SetGrid sets the most columns the task list is split into and the
+// narrowest a column may get. Zero values keep the defaults.
+func (m *TaskModel) SetGrid(columns, minWidth int) {
+	m.gridColumnsMax = columns
+	m.gridColumnWidth = minWidth
+	m.invalidateRows()
+}
+
+// listWidth is the width rows are rendered in, inside the app container.
+func (m TaskModel) listWidth() int {
+	termWidth := int(float64(m.width) * 0.98)
+	if termWidth <= 0 {
+		termWidth = 98
+	}
+	appFrameW, _ := m.theme.AppContainer.GetFrameSize()
+	return max(40, termWidth-appFrameW)
+}
+
+// gridColumns returns the number of columns the task list is shown in.
+func (m TaskModel) gridColumns() int {
+	limit, minWidth := m.gridColumnsMax, m.gridColumnWidth
+	if limit <= 0 {
+		limit = config.DefaultGridColumns
+	}
+	if minWidth <= 0 {
+		minWidth = config.DefaultGridColumnWidth
+	}
+	if m.width <= 0 || m.tree || m.accessible {
+		return 1
+	}
+	return max(1, min(limit, (m.listWidth()+gridGap)/(minWidth+gridGap)))
+}
+
+// visibleTaskCount is the number of tasks that fit on screen.
+func (m *TaskModel) visibleTaskCount() int {
+	return m.visibleListHeight() * m.gridColumns()
+}
+
+// cellWidth returns the box width of column col of cols columns sharing
+// width, so that the bordered boxes and the gaps between them span the same
+// cells as a single full-width box. The first columns take up the remainder.
+func cellWidth(width, cols, col int) int {
+	if cols <= 1 {
+		return width
+	}
+	frame := lipgloss.NormalBorder().GetLeftSize() + lipgloss.NormalBorder().GetRightSize()
+	total := width + frame - (cols-1)*gridGap
+	w := total/cols - frame
+	if col < total%cols {
+		w++
+	}
+	return w
+}
+
+// moveInRow moves the selection by delta within its grid row. It reports
+// false when there is no task that way, e.g. at the end of the row.
+func (m *TaskModel) moveInRow(delta int) bool {
+	cols := m.gridColumns()
+	if cols <= 1 {
+		return false
+	}
+	next := m.selected + delta
+	if next < 0 || next >= len(m.filteredTasks) || next/cols != m.selected/cols {
+		return false
+	}
+	m.selected = next
+	m.ensureSelectionVisible()
+	return true
+}
+
+// moveRow moves the selection by delta grid rows, keeping its column where
+// the target row has one.
+func (m *TaskModel) moveRow(delta int) {
+	next := m.selected + delta*m.gridColumns()
+	if next < 0 {
+		return
+	}
+	if next >= len(m.filteredTasks) {
+		// The last row may be short; land on its last task instead.
+		if (len(m.filteredTasks)-1)/m.gridColumns() == m.selected/m.gridColumns() {
+			return
+		}
+		next = len(m.filteredTasks) - 1
+	}
+	m.selected = next
+	m.ensureSelectionVisible()
+}
+
+// renderGrid renders rows grid rows of tasks starting at listOffset.
+func (m TaskModel) renderGrid(width, rows int) string {
+	cols := m.gridColumns()
+	gap := strings.Repeat(" ", gridGap)
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		first := m.listOffset + r*cols
+		if first >= len(m.filteredTasks) {
+			break
+		}
+		var cells []string
+		for i := first; i < min(first+cols, len(m.filteredTasks)); i++ {
+			if i > first {
+				cells = append(cells, gap)
+			}
+			cells = append(cells, m.cachedRow(m.filteredTasks[i], i == m.selected, m.hotkeyFor(i), cellWidth(width, cols, i-first)))
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cells...) + "\n")
+	}
+	return b.String()
+}
+
+// gridIndexAt maps a click at column x on what the list takes for row to the
+// index of the task under it.
+func (m TaskModel) gridIndexAt(x, row int) int {
+	cols := m.gridColumns()
+	if cols <= 1 {
+		return row
+	}
+	frameW, _ := m.theme.AppContainer.GetFrameSize()
+	col := (x - frameW/2) / (cellWidth(m.listWidth(), cols, 0) + 2 + gridGap)
+	return row*cols + max(0, min(cols-1, col))
+}
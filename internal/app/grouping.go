@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+)
+
+// flatTab is the only tab of the flat grouping strategy.
+const flatTab = "all"
+
+// SetGroupBy selects the tab grouping strategy, one of config.GroupStrategies.
+func (m *TaskModel) SetGroupBy(strategy string) {
+	m.groupBy = strategy
+	m.buildTabs()
+	m.updateFilter()
+}
+
+// cycleGrouping switches to the next grouping strategy.
+func (m *TaskModel) cycleGrouping() {
+	next := config.GroupStrategies[0]
+	for i, g := range config.GroupStrategies {
+		if g == m.groupBy {
+			next = config.GroupStrategies[(i+1)%len(config.GroupStrategies)]
+			break
+		}
+	}
+	m.SetGroupBy(next)
+	m.setStatus(fmt.Sprintf("Tabs grouped by %s", next))
+}
+
+// tabGrouper returns the function mapping a task to the tabs it is listed
+// under for the current strategy.
+func (m *TaskModel) tabGrouper(tasks []taskmeta.Task) func(taskmeta.Task) []string {
+	switch m.groupBy {
+	case config.GroupNamespace:
+		return groupByNamespace
+	case config.GroupFile:
+		return m.groupByFile
+	case config.GroupTag:
+		return tagTabs
+	case config.GroupFlat:
+		return func(taskmeta.Task) []string { return []string{flatTab} }
+	}
+
+	// When tasks come from more than one backend, group by backend instead of
+	// name prefix so e.g. Makefile targets and npm scripts get their own tabs.
+	byBackend := hasMultipleBackends(tasks)
+	// In recursive mode with the tabs layout each subproject gets a tab.
+	byProject := m.recursive && m.projectLayout == config.LayoutTabs
+	return func(t taskmeta.Task) []string {
+		switch {
+		case byProject && t.Project != "":
+			return []string{t.Project}
+		case byProject:
+			return []string{"main"}
+		case byBackend:
+			return []string{t.Backend}
+		}
+		if prefix, _, ok := strings.Cut(t.Name, "-"); ok {
+			return []string{prefix}
+		}
+		return []string{"main"}
+	}
+}
+
+// groupByNamespace uses the include namespace: "docs:site:build" goes to
+// "docs:site", tasks of the root Taskfile to main.
+func groupByNamespace(t taskmeta.Task) []string {
+	if i := strings.LastIndex(t.Name, ":"); i > 0 {
+		return []string{t.Name[:i]}
+	}
+	return []string{"main"}
+}
+
+// groupByFile uses the Taskfile defining the task, relative to its project.
+// Tasks of the root Taskfile, or from discovery that does not report the
+// file, go to main.
+func (m *TaskModel) groupByFile(t taskmeta.Task) []string {
+	if t.Taskfile == "" {
+		return []string{"main"}
+	}
+	dir := t.WorkDir(m.projectRoot)
+	rel, err := filepath.Rel(dir, t.Taskfile)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = t.Taskfile
+	}
+	if filepath.Dir(rel) == "." {
+		return []string{"main"}
+	}
+	return []string{filepath.ToSlash(rel)}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The two header lines are Go templates, set by header and subheader in the
+// config file. They see the project's name and root, its git branch and the
+// version of the task binary, which are looked up in the background. The
+// subheader gives way to warnings, e.g. about the built-in runner.
+
+// headerData is what the header templates see.
+type headerData struct {
+	Project     string // base name of the project root
+	Root        string // absolute project root
+	Branch      string // current git branch, or short commit when detached
+	TaskVersion string // e.g. v3.40.0, empty when unknown
+}
+
+// headerMsg delivers the git branch and task version of root.
+type headerMsg struct {
+	root    string
+	branch  string
+	version string
+}
+
+// SetHeader sets the templates of the two header lines; empty ones keep the
+// defaults. The config file validated them already, so a template that does
+// not parse simply keeps its default too.
+func (m *TaskModel) SetHeader(header, subheader string) {
+	m.headerTmpl = parseHeader(header, config.DefaultHeader)
+	m.subheaderTmpl = parseHeader(subheader, config.DefaultSubheader)
+}
+
+func parseHeader(text, fallback string) *template.Template {
+	if t, err := template.New("header").Parse(text); text != "" && err == nil {
+		return t
+	}
+	return template.Must(template.New("header").Parse(fallback))
+}
+
+// headerCmd looks up the git branch and task version of the project.
+func (m *TaskModel) headerCmd() tea.Cmd {
+	root := m.projectRoot
+	if root == "" || m.browsing() {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := headerMsg{root: root, branch: gitBranch(root)}
+		if v, ok := taskmeta.TaskVersion(); ok {
+			msg.version = v.String()
+		}
+		return msg
+	}
+}
+
+// handleHeader keeps the looked up details if they are still about the
+// current project.
+func (m *TaskModel) handleHeader(msg headerMsg) {
+	if msg.root != m.projectRoot {
+		return
+	}
+	m.gitBranch = msg.branch
+	m.taskVersion = msg.version
+}
+
+// headerLine renders tmpl for the current project, collapsing runs of
+// spaces so that empty fields leave no gaps. Template errors are shown in
+// its place.
+func (m TaskModel) headerLine(tmpl *template.Template) string {
+	data := headerData{
+		Project:     cmp.Or(m.projectName, "(no Taskfile)"),
+		Root:        m.projectRoot,
+		Branch:      m.gitBranch,
+		TaskVersion: m.taskVersion,
+	}
+	if m.browsing() {
+		data.Root = m.browseSource
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return err.Error()
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// gitBranch returns the branch checked out in the git work tree holding
+// dir, the short commit when HEAD is detached, or "" outside of git.
+func gitBranch(dir string) string {
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				// Worktrees and submodules point to their git directory.
+				data, err := os.ReadFile(gitDir)
+				if err != nil {
+					return ""
+				}
+				gitDir = strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(dir, gitDir)
+				}
+			}
+			head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			if len(ref) > 7 {
+				ref = ref[:7]
+			}
+			return ref
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
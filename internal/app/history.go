@@ -0,0 +1,24 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"taskg/internal/taskmeta"
+)
+
+// recordRuns adds the given tasks to the project's run history, which feeds
+// the "smart" (frecency) sort mode.
+func (m *TaskModel) recordRuns(tasks ...taskmeta.Task) {
+	if m.projectRoot == "" {
+		return
+	}
+	now := time.Now()
+	for _, t := range tasks {
+		m.state.RecordRun(taskKey(t), now)
+	}
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save run history: %v", err))
+	}
+	m.invalidateRows()
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"taskg/internal/config"
+	"taskg/internal/hooks"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hookMsg reports the outcome of the webhooks called after a run.
+type hookMsg struct{ err error }
+
+// SetHooks sets the webhooks called after each in-TUI run. Runs started
+// after the TUI exits are reported by main.
+func (m *TaskModel) SetHooks(h []config.Hook) { m.hooks = h }
+
+// notifyHooks calls the hooks for the finished job j. Cancelled runs are
+// not reported.
+func (m *TaskModel) notifyHooks(j *job) tea.Cmd {
+	if j.canceled {
+		return nil
+	}
+	dir := j.task.WorkDir(m.projectRoot)
+	r := hooks.Run{
+		Task:     j.task.Name,
+		Args:     j.args,
+		Project:  filepath.Base(dir),
+		Dir:      dir,
+		Started:  j.start,
+		Duration: j.end.Sub(j.start),
+		ExitCode: j.exitCode,
+	}
+	if !hooks.Wanted(m.hooks, r) {
+		return nil
+	}
+	list := m.hooks
+	return func() tea.Msg {
+		return hookMsg{err: hooks.Notify(context.Background(), list, r)}
+	}
+}
+
+func (m *TaskModel) handleHook(msg hookMsg) {
+	if msg.err != nil {
+		m.setWarning(fmt.Sprintf("Webhook failed: %v", msg.err))
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHotkeys is the number of visible tasks that get a digit hotkey.
+const maxHotkeys = 9
+
+// hotkeyFor returns the digit (1-9) running the task at index i of the
+// filtered list, or 0 when it is not among the first visible rows or is a
+// section header.
+func (m TaskModel) hotkeyFor(i int) int {
+	n := i - m.listOffset + 1
+	if n < 1 || n > maxHotkeys || n > m.visibleTaskCount() || isSection(m.filteredTasks[i]) {
+		return 0
+	}
+	return n
+}
+
+// runHotkey selects and runs the visible task labelled with digit n, like
+// pressing Enter on it.
+func (m *TaskModel) runHotkey(n int) tea.Cmd {
+	i := m.listOffset + n - 1
+	if i >= len(m.filteredTasks) || m.hotkeyFor(i) != n {
+		return nil
+	}
+	m.selected = i
+	return m.markForExecution(false)
+}
+
+// renderHotkey renders the digit badge in front of a task name. Rows without
+// a hotkey get blank padding so names stay aligned.
+func (m TaskModel) renderHotkey(n int) string {
+	if n == 0 {
+		return "  "
+	}
+	return m.theme.Help.Render(fmt.Sprint(n)) + " "
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// With icons: true in the config file, tasks are prefixed with a Nerd Font
+// icon for their category, inferred from the words of their name, namespace
+// first: docker:build gets the Docker whale, test-unit the flask. icon_map
+// sets the icon of a category or of any other word, or clears it with "".
+// ASCII mode draws no icons, since they are glyphs of a patched font.
+
+// defaultIcons are the Nerd Font icons of the known categories.
+var defaultIcons = map[string]string{
+	"build":   "\uf0ad",     // nf-fa-wrench
+	"clean":   "\uf1f8",     // nf-fa-trash
+	"db":      "\uf1c0",     // nf-fa-database
+	"deploy":  "\uf135",     // nf-fa-rocket
+	"deps":    "\uf019",     // nf-fa-download
+	"docker":  "\uf308",     // nf-linux-docker
+	"docs":    "\uf02d",     // nf-fa-book
+	"format":  "\uf036",     // nf-fa-align_left
+	"gen":     "\uf0d0",     // nf-fa-magic
+	"git":     "\ue702",     // nf-dev-git
+	"k8s":     "\U000f10fe", // nf-md-kubernetes
+	"lint":    "\uf046",     // nf-fa-check_square_o
+	"release": "\uf02b",     // nf-fa-tag
+	"run":     "\uf04b",     // nf-fa-play
+	"test":    "\uf0c3",     // nf-fa-flask
+}
+
+// iconAliases maps words of task names to the category they stand for.
+var iconAliases = map[string]string{
+	"compile": "build", "make": "build", "dist": "build",
+	"clear": "clean", "purge": "clean",
+	"database": "db", "migrate": "db", "migration": "db", "migrations": "db", "sql": "db", "seed": "db",
+	"ship": "deploy", "publish": "deploy", "provision": "deploy",
+	"install": "deps", "vendor": "deps", "mod": "deps", "setup": "deps",
+	"compose": "docker", "container": "docker", "image": "docker",
+	"doc": "docs", "godoc": "docs",
+	"fmt": "format", "prettier": "format",
+	"generate": "gen", "codegen": "gen", "proto": "gen",
+	"kube": "k8s", "kubernetes": "k8s", "helm": "k8s", "kubectl": "k8s",
+	"vet": "lint", "check": "lint", "staticcheck": "lint",
+	"tag": "release", "version": "release", "bump": "release",
+	"dev": "run", "serve": "run", "server": "run", "start": "run", "watch": "run",
+	"tests": "test", "spec": "test", "e2e": "test", "bench": "test", "coverage": "test", "cover": "test",
+}
+
+// SetIcons turns the category icons on or off. overrides maps categories
+// or words of task names to icons, "" dropping one.
+func (m *TaskModel) SetIcons(enabled bool, overrides map[string]string) {
+	m.icons = nil
+	if enabled {
+		m.icons = make(map[string]string, len(defaultIcons)+len(overrides))
+		for k, v := range defaultIcons {
+			m.icons[k] = v
+		}
+		for k, v := range overrides {
+			m.icons[strings.ToLower(k)] = v
+		}
+	}
+	m.invalidateRows()
+}
+
+// taskIcon returns the icon of t followed by a space, or "" when icons are
+// off or t fits no category.
+func (m TaskModel) taskIcon(t taskmeta.Task) string {
+	if m.icons == nil || m.ascii {
+		return ""
+	}
+	words := strings.FieldsFunc(strings.ToLower(t.Name), func(r rune) bool {
+		return strings.ContainsRune(":-_./ ", r)
+	})
+	for _, w := range words {
+		icon, ok := m.icons[w]
+		if !ok {
+			icon = m.icons[iconAliases[w]]
+		}
+		if icon != "" {
+			return m.theme.Accent.Render(icon) + " "
+		}
+	}
+	return ""
+}
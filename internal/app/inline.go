@@ -0,0 +1,54 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// minInlineHeight keeps an inline picker usable on short terminals.
+const minInlineHeight = 10
+
+// SetInlineHeight limits the UI to part of the terminal for inline (no
+// alt-screen) operation. spec is a line count ("15") or a percentage of the
+// terminal height ("40%"); an empty spec means full screen.
+func (m *TaskModel) SetInlineHeight(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	pct := strings.HasSuffix(spec, "%")
+	n, err := strconv.Atoi(strings.TrimSuffix(spec, "%"))
+	if err != nil || n <= 0 || (pct && n > 100) {
+		return fmt.Errorf("invalid height %q: use a line count like 15 or a percentage like 40%%", spec)
+	}
+	m.inline = true
+	if pct {
+		m.heightPercent = n
+	} else {
+		m.heightLines = n
+	}
+	return nil
+}
+
+// limitHeight applies the inline height limit to a terminal height.
+func (m TaskModel) limitHeight(termHeight int) int {
+	if !m.inline {
+		return termHeight
+	}
+	h := m.heightLines
+	if m.heightPercent > 0 {
+		h = termHeight * m.heightPercent / 100
+	}
+	h = max(h, minInlineHeight)
+	return min(h, termHeight)
+}
+
+// quit ends the program. Inline mode then renders nothing, so the picker
+// disappears and the shell scrollback above it is left as it was.
+func (m *TaskModel) quit() tea.Cmd {
+	m.quitting = true
+	m.saveUIState()
+	return tea.Quit
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+)
+
+// Tasks that have been run before show how their last run ended, how often
+// they ran and how long ago the last run started, e.g. "✓ 12× · 2h ago", from
+// the project's run history. Runs taskg did not see end, such as those in a
+// terminal pane, show no result. The palette can also narrow the list to the
+// tasks that never ran here, to dig up forgotten ones in big Taskfiles.
+
+// recordResult remembers how the finished job j ended, and how long it took
+// when it succeeded: failed and cancelled runs often stop early. Cancelled
+// runs leave no result.
+func (m *TaskModel) recordResult(j *job) {
+	if j.canceled || m.projectRoot == "" || m.state == nil {
+		return
+	}
+	m.saveResult(j.task, j.exitCode == 0, j.end.Sub(j.start))
+}
+
+// RecordRunResult remembers how the task run after the TUI exited ended and
+// how long it took; main calls it once the task has exited.
+func (m *TaskModel) RecordRunResult(d time.Duration, ok bool) {
+	if m.projectRoot == "" || m.state == nil {
+		return
+	}
+	m.saveResult(m.runTarget, ok, d)
+}
+
+func (m *TaskModel) saveResult(t taskmeta.Task, ok bool, d time.Duration) {
+	key := taskKey(t)
+	m.state.RecordResult(key, ok)
+	if ok {
+		m.state.RecordDuration(key, d)
+	}
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save run history: %v", err))
+	}
+	m.invalidateRows()
+}
+
+// lastRunAge renders how long ago t last ran, or "" when it never did.
+func (m TaskModel) lastRunAge(t taskmeta.Task) string {
+	if m.state == nil {
+		return ""
+	}
+	st := m.state.Runs[taskKey(t)]
+	if st == nil || st.Last.IsZero() {
+		return ""
+	}
+	return relativeTime(time.Since(st.Last), st.Last)
+}
+
+// relativeTime renders age compactly, e.g. "5m ago" or "3d ago". Runs older
+// than a month show their date instead.
+func relativeTime(age time.Duration, at time.Time) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age/(24*time.Hour)))
+	case at.Year() == time.Now().Year():
+		return at.Format("Jan 2")
+	default:
+		return at.Format("Jan 2 2006")
+	}
+}
+
+// lastRunBadge renders the result of t's last run, its run count and the
+// age of the last run for the task list.
+func (m TaskModel) lastRunBadge(t taskmeta.Task) string {
+	age := m.lastRunAge(t)
+	if age == "" {
+		return ""
+	}
+	st := m.state.Runs[taskKey(t)]
+	badge := m.theme.Help.Render(fmt.Sprintf("%d× · %s", st.Count, age))
+	switch st.Result {
+	case state.ResultOK:
+		return m.theme.Status.Render("✓") + " " + badge
+	case state.ResultFailed:
+		return m.theme.Error.Render("✗") + " " + badge
+	}
+	return badge
+}
+
+// ranBefore reports whether t has been run in this project.
+func (m TaskModel) ranBefore(t taskmeta.Task) bool {
+	if m.state == nil {
+		return false
+	}
+	st := m.state.Runs[taskKey(t)]
+	return st != nil && st.Count > 0
+}
+
+// toggleNeverRun narrows the list to the tasks that never ran, or back.
+func (m *TaskModel) toggleNeverRun() {
+	m.neverRun = !m.neverRun
+	m.updateFilter()
+	if !m.neverRun {
+		m.setStatus("Showing all tasks")
+		return
+	}
+	n := 0
+	for _, t := range m.tasks {
+		if !m.ranBefore(t) {
+			n++
+		}
+	}
+	m.setStatus(fmt.Sprintf("Showing the %d tasks never run here", n))
+}
+
+// keepNeverRun drops the tasks that ran before from tasks when the list is
+// narrowed to never-run ones. Section headers are kept.
+func (m TaskModel) keepNeverRun(tasks []taskmeta.Task) []taskmeta.Task {
+	if !m.neverRun {
+		return tasks
+	}
+	res := []taskmeta.Task{}
+	for _, t := range tasks {
+		if isSection(t) || !m.ranBefore(t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
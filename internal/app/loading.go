@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// LoadAsync makes Init discover the tasks of the project root in the
+// background, so the TUI paints immediately and shows a spinner until the
+// (possibly slow) discovery finishes.
+func (m *TaskModel) LoadAsync() {
+	m.loading = true
+	m.spinner = spinner.New(spinner.WithSpinner(m.spinnerKind()), spinner.WithStyle(m.theme.Highlight))
+}
+
+// loadingCmds starts the initial discovery and the spinner animation, unless
+// the disk cache still matches the project's Taskfiles.
+func (m *TaskModel) loadingCmds() tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	if m.browsing() {
+		return tea.Batch(m.refreshCmd(), m.spinnerTick())
+	}
+	if tasks, ok := taskmeta.LoadCache(m.projectRoot, m.discoveryMode(), m.projectDirs()); ok {
+		m.loading = false
+		m.setTasks(tasks)
+		return nil
+	}
+	return tea.Batch(m.refreshCmd(), m.spinnerTick())
+}
+
+// projectDirs lists the project directories discovery covers, for the
+// cache to notice new ones: the subprojects in recursive mode and the
+// registered projects in global mode. It is nil otherwise.
+func (m TaskModel) projectDirs() []string {
+	switch {
+	case m.globalMode():
+		dirs := make([]string, len(m.global))
+		for i, p := range m.global {
+			dirs[i] = p.Root
+		}
+		return dirs
+	case m.recursive:
+		dirs, err := taskmeta.FindTaskfileDirs(m.projectRoot)
+		if err != nil {
+			return nil
+		}
+		return dirs
+	}
+	return nil
+}
+
+// spinnerTick starts the spinner animation, unless motion is reduced.
+func (m TaskModel) spinnerTick() tea.Cmd {
+	if m.reducedMotion {
+		return nil
+	}
+	return m.spinner.Tick
+}
+
+// discoveryMode identifies the settings that change what discovery returns,
+// so each combination is cached separately.
+func (m TaskModel) discoveryMode() string {
+	return fmt.Sprintf("mixed=%t recursive=%t global=%t task=%t", m.mixedBackends, m.recursive, m.globalMode(), taskmeta.TaskBinaryAvailable())
+}
+
+// updateSpinner advances the spinner while discovery is running; once it
+// finished the tick chain simply stops.
+func (m *TaskModel) updateSpinner(msg spinner.TickMsg) tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return cmd
+}
+
+func (m TaskModel) renderLoading(width int) string {
+	where := m.projectRoot
+	if m.browsing() {
+		where = m.browseSource
+	}
+	text := fmt.Sprintf("%s Discovering tasks in %s…", m.spinner.View(), where)
+	if m.reducedMotion {
+		text = fmt.Sprintf("Discovering tasks in %s…", where)
+	}
+	return m.theme.Help.Copy().Width(width).Render(text)
+}
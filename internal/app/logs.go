@@ -0,0 +1,205 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/runlog"
+	"taskg/internal/textdiff"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Every in-TUI run writes its output, masked like the output pane, to a log
+// file under .taskg/logs. The run history opens the logs, or the diff of the
+// last two, in a pager.
+
+// pagerClosedMsg reports that the pager exited; tmp is a file written for
+// it, to be removed.
+type pagerClosedMsg struct {
+	err error
+	tmp string
+}
+
+// SetLogs sets whether in-TUI runs keep log files and how long.
+func (m *TaskModel) SetLogs(logs config.Logs) { m.logs = logs }
+
+// logsEnabled reports whether runs get a log file.
+func (m TaskModel) logsEnabled() bool {
+	return !m.logs.Disabled && m.projectRoot != "" && !m.browsing()
+}
+
+// pruneLogs removes the logs the retention settings no longer keep.
+func (m *TaskModel) pruneLogs() {
+	if !m.logsEnabled() {
+		return
+	}
+	r := runlog.Retention{MaxAge: m.logs.MaxAge, MaxSize: int64(m.logs.MaxSizeMB) << 20}
+	if err := runlog.Prune(m.projectRoot, r, time.Now()); err != nil {
+		m.setWarning(fmt.Sprintf("Could not prune run logs: %v", err))
+	}
+}
+
+// openLog starts the log file of the job j.
+func (m *TaskModel) openLog(j *job) {
+	if !m.logsEnabled() {
+		return
+	}
+	w, err := runlog.Create(m.projectRoot, taskKey(j.task), j.title, j.start)
+	if err != nil {
+		m.setWarning(fmt.Sprintf("Could not write run log: %v", err))
+		return
+	}
+	j.log = w
+}
+
+// closeLog finishes the log of the job j once it has ended.
+func (m *TaskModel) closeLog(j *job) {
+	if j.log == nil {
+		return
+	}
+	if err := j.log.Close(j.exitCode, j.end); err != nil {
+		m.setWarning(fmt.Sprintf("Could not write run log: %v", err))
+	}
+	j.log = nil
+}
+
+// historyKeys returns the keys of the recorded tasks, most recently run
+// first, as the run history lists them.
+func (m TaskModel) historyKeys() []string {
+	keys := make([]string, 0, len(m.state.Runs))
+	for k := range m.state.Runs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m.state.Runs[keys[i]].Last.After(m.state.Runs[keys[j]].Last)
+	})
+	return keys
+}
+
+// openHistory shows the run history with the most recent task selected.
+func (m *TaskModel) openHistory() {
+	m.historyMode = true
+	m.historySelected = 0
+}
+
+// historyLogs returns the key of the selected history entry and its logs,
+// newest first. It warns when there are fewer than want.
+func (m *TaskModel) historyLogs(want int) (string, []runlog.Entry) {
+	keys := m.historyKeys()
+	if len(keys) == 0 {
+		return "", nil
+	}
+	key := keys[min(m.historySelected, len(keys)-1)]
+	logs, err := runlog.List(m.projectRoot, key)
+	switch {
+	case err != nil:
+		m.setError(fmt.Sprintf("Could not read run logs: %v", err))
+		return key, nil
+	case len(logs) < want && want == 1:
+		m.setWarning(fmt.Sprintf("No log of %s: only runs inside taskg (Ctrl+O) are logged", historyLabel(key)))
+		return key, nil
+	case len(logs) < want:
+		m.setWarning(fmt.Sprintf("%s has %d logged runs, the diff needs %d", historyLabel(key), len(logs), want))
+		return key, nil
+	}
+	return key, logs
+}
+
+// openHistoryLog opens the newest log of the selected history entry in the
+// pager.
+func (m *TaskModel) openHistoryLog() tea.Cmd {
+	_, logs := m.historyLogs(1)
+	if logs == nil {
+		return nil
+	}
+	return tea.ExecProcess(pagerCommand(logs[0].Path), func(err error) tea.Msg {
+		return pagerClosedMsg{err: err}
+	})
+}
+
+// openHistoryDiff shows the colored unified diff between the output of the
+// last two logged runs of the selected history entry in the pager.
+func (m *TaskModel) openHistoryDiff() tea.Cmd {
+	key, logs := m.historyLogs(2)
+	if logs == nil {
+		return nil
+	}
+	newer, errNew := runlog.Output(logs[0].Path)
+	older, errOld := runlog.Output(logs[1].Path)
+	if err := errors.Join(errNew, errOld); err != nil {
+		m.setError(fmt.Sprintf("Could not read run logs: %v", err))
+		return nil
+	}
+	name := func(e runlog.Entry) string {
+		return fmt.Sprintf("%s (run %s)", historyLabel(key), e.Started.Local().Format("2006-01-02 15:04:05"))
+	}
+	diff := textdiff.Unified(name(logs[1]), name(logs[0]), older, newer, 3)
+	if diff == "" {
+		m.setStatus(fmt.Sprintf("The last two runs of %s printed the same output", historyLabel(key)))
+		return nil
+	}
+	f, err := os.CreateTemp("", "taskg-diff-*.diff")
+	if err == nil {
+		_, err = f.WriteString(m.colorDiff(diff))
+		err = errors.Join(err, f.Close())
+	}
+	if err != nil {
+		m.setError(fmt.Sprintf("Could not write the diff: %v", err))
+		return nil
+	}
+	return tea.ExecProcess(pagerCommand(f.Name()), func(err error) tea.Msg {
+		return pagerClosedMsg{err: err, tmp: f.Name()}
+	})
+}
+
+// colorDiff colors the removed, added and hunk header lines of diff.
+func (m TaskModel) colorDiff(diff string) string {
+	removed := lipgloss.NewStyle().Foreground(m.theme.Error.GetForeground())
+	added := lipgloss.NewStyle().Foreground(m.theme.Status.GetForeground())
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "+++"):
+			lines[i] = lipgloss.NewStyle().Bold(true).Render(l)
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = m.theme.Highlight.Render(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = removed.Render(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = added.Render(l)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (m *TaskModel) handlePagerClosed(msg pagerClosedMsg) {
+	if msg.tmp != "" {
+		os.Remove(msg.tmp)
+	}
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Pager failed: %v", msg.err))
+	}
+}
+
+// pagerCommand builds the command showing path in $PAGER, or less.
+func pagerCommand(path string) *exec.Cmd {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+		if runtime.GOOS == "windows" {
+			pager = "more"
+		}
+	}
+	// $PAGER may carry arguments, e.g. "less -RS".
+	parts := strings.Fields(pager)
+	return exec.Command(parts[0], append(parts[1:], path)...)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maskText replaces secret values in command previews and task output.
+const maskText = "****"
+
+// defaultMaskPatterns catch common secrets. When a pattern has a capture
+// group only the group is masked, so "TOKEN=abc" becomes "TOKEN=****".
+var defaultMaskPatterns = []string{
+	// KEY=value and KEY: value where the key names a secret
+	`(?i)\b[A-Z0-9_]*(?:TOKEN|PASSWORD|PASSWD|SECRET|API_?KEY|PRIVATE_KEY|ACCESS_KEY)[A-Z0-9_]*\s*[=:]\s*("[^"]*"|'[^']*'|[^\s"']+)`,
+	// --password value, --token=value
+	`(?i)--(?:password|passwd|token|secret)[ =]("[^"]*"|'[^']*'|[^\s"']+)`,
+	// Authorization: Bearer value
+	`(?i)\bBearer\s+([A-Za-z0-9._~+/-]+=*)`,
+	// AWS access key IDs
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+}
+
+// masker redacts secrets matching its patterns.
+type masker struct {
+	patterns []*regexp.Regexp
+}
+
+// newMasker compiles the default patterns plus extra ones from the config.
+func newMasker(extra []string) (*masker, error) {
+	k := &masker{}
+	for _, p := range append(append([]string{}, defaultMaskPatterns...), extra...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		k.patterns = append(k.patterns, re)
+	}
+	return k, nil
+}
+
+// SetMaskPatterns adds regular expressions whose matches (or first capture
+// group) are masked on top of the built-in secret patterns.
+func (m *TaskModel) SetMaskPatterns(patterns []string) error {
+	k, err := newMasker(patterns)
+	if err != nil {
+		return err
+	}
+	m.masker = k
+	m.invalidateRows()
+	return nil
+}
+
+// mask returns s with every secret replaced by maskText. Values that are
+// only references, such as $TOKEN or {{.TOKEN}}, are left alone.
+func (k *masker) mask(s string) string {
+	if k == nil {
+		return s
+	}
+	for _, re := range k.patterns {
+		s = maskMatches(re, s)
+	}
+	return s
+}
+
+func maskMatches(re *regexp.Regexp, s string) string {
+	idx := re.FindAllStringSubmatchIndex(s, -1)
+	if idx == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range idx {
+		start, end := loc[0], loc[1]
+		if len(loc) >= 4 && loc[2] >= 0 {
+			start, end = loc[2], loc[3]
+		}
+		value := strings.Trim(s[start:end], `"'`)
+		if value == "" || strings.HasPrefix(value, "$") || strings.HasPrefix(value, "{{") {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(maskText)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openNoteEditor shows the note overlay for the selected task, pre-filled
+// with its current note.
+func (m *TaskModel) openNoteEditor() tea.Cmd {
+	t, ok := m.selectedTask()
+	if !ok || m.refuseReadOnly() {
+		return nil
+	}
+	if m.projectRoot == "" {
+		m.setWarning("Notes need a project directory to be saved in")
+		return nil
+	}
+	ta := textarea.New()
+	ta.Placeholder = "Gotchas, required setup, who to ask…"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 4096
+	ta.SetWidth(60)
+	ta.SetHeight(8)
+	ta.SetValue(m.noteFor(t))
+	ta.Focus()
+	m.noteMode = true
+	m.noteTask = t
+	m.noteInput = ta
+	return textarea.Blink
+}
+
+func (m *TaskModel) handleNoteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.noteMode = false
+		return m, nil
+	case "ctrl+s":
+		m.noteMode = false
+		m.saveNote(m.noteTask, m.noteInput.Value())
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+// noteFor returns the local note attached to t, if any.
+func (m TaskModel) noteFor(t taskmeta.Task) string {
+	return m.state.Notes[taskKey(t)]
+}
+
+// saveNote stores note for t in the project state; a blank note removes it.
+func (m *TaskModel) saveNote(t taskmeta.Task, note string) {
+	note = strings.TrimSpace(note)
+	key := taskKey(t)
+	if note == m.state.Notes[key] {
+		return
+	}
+	if note == "" {
+		delete(m.state.Notes, key)
+	} else {
+		if m.state.Notes == nil {
+			m.state.Notes = make(map[string]string)
+		}
+		m.state.Notes[key] = note
+	}
+	m.invalidateRows() // rows show a marker for tasks with notes
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save note: %v", err))
+		return
+	}
+	if note == "" {
+		m.setStatus(fmt.Sprintf("Removed the note of %s", t.Name))
+	} else {
+		m.setStatus(fmt.Sprintf("Saved the note of %s", t.Name))
+	}
+}
+
+func (m TaskModel) renderNoteEditor() string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Note for " + m.noteTask.Name)
+	helperText := fmt.Sprintf("%s save  %s cancel",
+		m.theme.Highlight.Render("^S"),
+		m.theme.Highlight.Render("ESC"))
+	return m.renderDialog([]string{
+		header,
+		m.theme.Help.Render("Kept in .taskg/state.json, not in the Taskfile"),
+		"",
+		m.noteInput.View(),
+		"",
+		m.theme.Help.Copy().Italic(true).Render(helperText),
+	})
+}
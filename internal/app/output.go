@@ -0,0 +1,832 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"taskg/internal/runlog"
+	"taskg/internal/runner"
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// job is a task started inside the TUI whose output is streamed to the output pane.
+type job struct {
+	task     taskmeta.Task
+	args     []string
+	run      *runner.Run // nil while queued
+	title    string
+	prefix   string // output line prefix when several jobs share the pane
+	color    lipgloss.Color
+	start    time.Time
+	end      time.Time
+	running  bool
+	finished bool
+	canceled bool
+	exitCode int
+	err      error
+	yes      bool // the prompt was answered in taskg, see prompt.go
+	log      *runlog.Writer
+	retry    taskmeta.Retry // see retry.go
+	attempt  int            // 1 for the first run
+	retryAt  time.Time      // when the next attempt starts, while waiting
+	// container runs the job in its project's container, see container.go
+	container bool
+	// steps holds when each task of the run started, see telemetry.go
+	steps map[string]time.Time
+}
+
+// invocation returns the command line running j, passing --yes to Task when
+// taskg already asked the task's prompt.
+func (j *job) invocation() (string, []string) {
+	bin, args := j.task.RunInvocation(j.args)
+	if j.yes && bin == "task" {
+		args = append([]string{"--yes"}, args...)
+	}
+	return bin, args
+}
+
+// outputPane holds the captured output of the current job plus scroll and search state.
+type outputPane struct {
+	buf       *runner.Buffer
+	offset    int  // index of the first visible retained line
+	follow    bool // keep the view pinned to the newest line
+	searching bool
+	search    textinput.Model
+	query     string
+	matches   []int // absolute line indexes (Buffer.Dropped()+i) containing query
+	match     int   // position of the current match in matches
+	input     bool  // keystrokes are forwarded to the running task
+	// partial maps each job with an unterminated line to the absolute
+	// index of that line, which later output of other jobs may follow
+	partial map[*job]int
+}
+
+// runEventsMsg carries a batch of events read from a running job.
+type runEventsMsg struct {
+	run    *runner.Run
+	events []runner.Event
+}
+
+// maxEventBatch bounds how many lines are applied per update so chatty tasks
+// do not trigger one re-render per line.
+const maxEventBatch = 512
+
+func waitForRun(r *runner.Run) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-r.Events()
+		if !ok {
+			return nil
+		}
+		evs := []runner.Event{ev}
+	drain:
+		for len(evs) < maxEventBatch && !ev.Done {
+			select {
+			case ev, ok = <-r.Events():
+				if !ok {
+					break drain
+				}
+				evs = append(evs, ev)
+			default:
+				break drain
+			}
+		}
+		return runEventsMsg{run: r, events: evs}
+	}
+}
+
+// SetScrollback sets how many output lines the output pane keeps.
+func (m *TaskModel) SetScrollback(lines int) { m.scrollback = lines }
+
+// startRun launches task inside the TUI and switches to the output pane.
+func (m *TaskModel) startRun(task taskmeta.Task, args []string) tea.Cmd {
+	return m.startRuns([]*job{{task: task, args: args, container: m.runContainer}})
+}
+
+// startRuns resets the output pane and runs jobs concurrently, at most
+// maxJobs at a time; the rest are queued and started as others finish. With
+// more than one job, output lines are prefixed with the task name. While
+// jobs of the last run are still going, it is refused: replacing them would
+// leave their output undrained and their processes out of CancelJobs' reach.
+func (m *TaskModel) startRuns(jobs []*job) tea.Cmd {
+	if m.runningJobs() > 0 {
+		m.setWarning("Tasks are still running: stop them first (^L to view them)")
+		return nil
+	}
+	ran := make([]taskmeta.Task, len(jobs))
+	for i, j := range jobs {
+		ran[i] = j.task
+	}
+	m.recordRuns(ran...)
+	m.pruneLogs()
+	m.stopRepeat()
+	m.jobs = jobs
+	m.out.buf = runner.NewBuffer(m.scrollback)
+	m.out.offset = 0
+	m.out.follow = true
+	m.out.matches = nil
+	m.out.match = 0
+	m.out.partial = make(map[*job]int)
+	m.out.input = false
+	m.outputMode = true
+
+	width := 0
+	for _, j := range jobs {
+		width = max(width, lipgloss.Width(jobName(j.task)))
+	}
+	for i, j := range jobs {
+		j.retry = m.retryPolicy(j.task)
+		j.attempt = 1
+		bin, runArgs := m.jobInvocation(j)
+		j.title = strings.Join(append([]string{bin}, runArgs...), " ")
+		if j.task.Script != "" {
+			// The built-in runner's script is too long for a title line.
+			j.title = strings.Join(append([]string{"built-in:", j.task.Name}, j.args...), " ")
+		}
+		if j.task.Project != "" {
+			j.title += "  (in " + j.task.Project + ")"
+		}
+		if len(jobs) > 1 {
+			j.prefix = padRight(jobName(j.task), width) + " │ "
+			j.color = jobColors[i%len(jobColors)]
+		}
+	}
+
+	var cmds []tea.Cmd
+	for _, j := range jobs {
+		if m.runningJobs() >= m.jobLimit() {
+			break
+		}
+		cmds = append(cmds, m.launch(j))
+	}
+	return tea.Batch(cmds...)
+}
+
+// jobColors are used for per-task output prefixes, docker-compose style.
+var jobColors = []lipgloss.Color{"#38BDF8", "#F472B6", "#A3E635", "#FBBF24", "#C084FC", "#2DD4BF", "#FB923C", "#F87171"}
+
+// SetPTY enables running single in-TUI tasks attached to a pseudo-terminal.
+func (m *TaskModel) SetPTY(enabled bool) { m.usePTY = enabled && runner.PTYSupported }
+
+// SetInlineOnly makes Enter, the digit hotkeys and clicks run tasks inside
+// the TUI like Ctrl+O, for sessions such as `taskg ssh-serve` where nothing
+// can run after the TUI exits.
+func (m *TaskModel) SetInlineOnly(enabled bool) { m.inlineOnly = enabled }
+
+// SetMaxJobs sets how many in-TUI runs may execute concurrently.
+func (m *TaskModel) SetMaxJobs(n int) { m.maxJobs = n }
+
+func (m *TaskModel) jobLimit() int {
+	if m.maxJobs <= 0 {
+		return 1
+	}
+	return m.maxJobs
+}
+
+func (m *TaskModel) runningJobs() int {
+	n := 0
+	for _, j := range m.jobs {
+		// A job waiting to be retried keeps its slot.
+		if j.running || !j.retryAt.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+// jobName labels a job in prefixes; subproject tasks include their project.
+func jobName(t taskmeta.Task) string {
+	if t.Project != "" {
+		return t.Project + "/" + t.Name
+	}
+	return t.Name
+}
+
+// launch starts j's process and returns the command streaming its output.
+func (m *TaskModel) launch(j *job) tea.Cmd {
+	bin, runArgs := m.jobInvocation(j)
+	var env []string
+	if overrides := append(m.envFor(j.task), m.experimentEnv(j.task)...); len(overrides) > 0 {
+		env = append(os.Environ(), overrides...)
+	}
+	j.start = time.Now()
+	j.steps = nil
+	var r *runner.Run
+	var err error
+	if m.usePTY && len(m.jobs) == 1 {
+		// A single run gets a real terminal so prompts and progress bars
+		// work; parallel runs keep pipes since input could not be routed.
+		r, err = runner.StartPTY(j.task.WorkDir(m.projectRoot), bin, runArgs, env, m.outputLineWidth(), m.visibleOutputHeight())
+	} else {
+		r, err = runner.Start(j.task.WorkDir(m.projectRoot), bin, runArgs, env)
+	}
+	if err != nil {
+		j.finished = true
+		j.end = j.start
+		j.exitCode = -1
+		j.err = err
+		m.appendOutput(j, j.prefix+fmt.Sprintf("failed to start: %v", err), false)
+		return m.launchNext()
+	}
+	j.run = r
+	j.running = true
+	m.openLog(j)
+	return waitForRun(r)
+}
+
+// launchNext starts the next queued job if the concurrency limit allows.
+func (m *TaskModel) launchNext() tea.Cmd {
+	if m.runningJobs() >= m.jobLimit() {
+		return nil
+	}
+	for _, j := range m.jobs {
+		if j.run == nil && !j.finished {
+			return m.launch(j)
+		}
+	}
+	return nil
+}
+
+func (m *TaskModel) jobForRun(r *runner.Run) *job {
+	for _, j := range m.jobs {
+		if j.run == r {
+			return j
+		}
+	}
+	return nil
+}
+
+func (m *TaskModel) handleRunEvents(msg runEventsMsg) tea.Cmd {
+	j := m.jobForRun(msg.run)
+	if j == nil {
+		return nil
+	}
+	done := false
+	var notify, retry tea.Cmd
+	for _, ev := range msg.events {
+		if ev.Done {
+			done = true
+			j.running = false
+			j.end = time.Now()
+			j.exitCode = ev.ExitCode
+			j.err = ev.Err
+			// Running a task usually changes its up-to-date state.
+			delete(m.taskStatus, taskKey(j.task))
+			m.closeLog(j)
+			if retry = m.retryJob(j); retry != nil {
+				continue
+			}
+			j.finished = true
+			m.recordResult(j)
+			notify = tea.Batch(m.notifyHooks(j), m.exportTrace(j))
+			continue
+		}
+		m.appendOutput(j, j.prefix+ev.Line, ev.Partial)
+		if !ev.Partial {
+			m.noteStep(j, ev.Line)
+		}
+		if j.log != nil && !ev.Partial {
+			j.log.Line(m.masker.mask(ev.Line))
+		}
+	}
+	if done && m.out.input && !m.anyJobRunning() {
+		m.out.input = false
+	}
+	if m.out.follow {
+		m.scrollOutputToEnd()
+	}
+	if !done {
+		return waitForRun(j.run)
+	}
+	if retry != nil {
+		// The job keeps its slot while it waits for the next attempt.
+		return retry
+	}
+	if m.jobsFinished() {
+		if m.anyJobFailed() {
+			m.setError(m.jobsSummary())
+		} else {
+			m.setStatus(m.jobsSummary())
+		}
+		return tea.Batch(notify, m.scheduleRepeat())
+	}
+	return tea.Batch(notify, m.launchNext())
+}
+
+// CancelJobs stops every running job (killing its process group) and drops
+// queued ones. It reports whether anything was running or queued.
+func (m *TaskModel) CancelJobs() bool {
+	found := false
+	for _, j := range m.jobs {
+		switch {
+		case j.running:
+			j.canceled = true
+			j.run.Cancel()
+			found = true
+		case !j.finished:
+			// Queued, or waiting to be retried.
+			j.canceled = true
+			j.finished = true
+			j.retryAt = time.Time{}
+			found = true
+		}
+	}
+	return found
+}
+
+// WaitJobs waits for the jobs stopped by CancelJobs to end, for at most
+// runner.StopWait, so those ignoring SIGTERM are killed before taskg exits.
+func (m *TaskModel) WaitJobs() {
+	deadline := time.Now().Add(runner.StopWait)
+	for _, j := range m.jobs {
+		if j.running {
+			j.run.Wait(deadline)
+		}
+	}
+}
+
+func (m *TaskModel) jobsFinished() bool {
+	for _, j := range m.jobs {
+		if !j.finished {
+			return false
+		}
+	}
+	return true
+}
+
+// jobsSummary describes the outcome of the current run group.
+// anyJobFailed reports whether a finished job exited non-zero.
+func (m *TaskModel) anyJobFailed() bool {
+	for _, j := range m.jobs {
+		if j.finished && !j.canceled && j.exitCode != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *TaskModel) jobsSummary() string {
+	if len(m.jobs) == 1 {
+		j := m.jobs[0]
+		if j.canceled {
+			return "Cancelled"
+		}
+		if j.exitCode == 0 {
+			return fmt.Sprintf("Finished in %s", j.end.Sub(j.start).Round(time.Millisecond)) + j.attemptNote()
+		}
+		return fmt.Sprintf("Exited with code %d", j.exitCode) + j.attemptNote()
+	}
+	failed, canceled := 0, 0
+	for _, j := range m.jobs {
+		switch {
+		case j.canceled:
+			canceled++
+		case j.exitCode != 0:
+			failed++
+		}
+	}
+	if canceled > 0 {
+		return fmt.Sprintf("%d tasks finished, %d failed, %d cancelled", len(m.jobs)-canceled, failed, canceled)
+	}
+	return fmt.Sprintf("%d tasks finished, %d failed", len(m.jobs), failed)
+}
+
+// appendOutput adds a line from j. If j's previous line was partial it is
+// replaced instead, so prompts and progress updates don't pile up.
+func (m *TaskModel) appendOutput(j *job, line string, partial bool) {
+	line = m.masker.mask(line)
+	abs, ok := m.out.partial[j]
+	if ok && j != nil && m.out.buf.Replace(abs, line) {
+		if i, found := slices.BinarySearch(m.out.matches, abs); found {
+			m.out.matches = slices.Delete(m.out.matches, i, i+1)
+		}
+	} else {
+		m.out.buf.Append(line)
+		abs = m.out.buf.Dropped() + m.out.buf.Len() - 1
+	}
+	delete(m.out.partial, j)
+	if partial && j != nil {
+		m.out.partial[j] = abs
+	}
+	if m.out.query == "" {
+		return
+	}
+	if strings.Contains(strings.ToLower(line), strings.ToLower(m.out.query)) {
+		// A partial line of a job may be updated after lines of others.
+		i, _ := slices.BinarySearch(m.out.matches, abs)
+		m.out.matches = slices.Insert(m.out.matches, i, abs)
+	}
+	m.out.match = min(m.out.match, max(0, len(m.out.matches)-1))
+	// Forget matches whose lines were evicted from the ring buffer.
+	dropped := m.out.buf.Dropped()
+	trim := 0
+	for trim < len(m.out.matches) && m.out.matches[trim] < dropped {
+		trim++
+	}
+	if trim > 0 {
+		m.out.matches = m.out.matches[trim:]
+		m.out.match = max(0, m.out.match-trim)
+	}
+}
+
+func (m *TaskModel) recomputeOutputMatches() {
+	m.out.matches = nil
+	m.out.match = 0
+	if m.out.query == "" || m.out.buf == nil {
+		return
+	}
+	q := strings.ToLower(m.out.query)
+	for i := 0; i < m.out.buf.Len(); i++ {
+		if strings.Contains(strings.ToLower(m.out.buf.Line(i)), q) {
+			m.out.matches = append(m.out.matches, m.out.buf.Dropped()+i)
+		}
+	}
+}
+
+// jumpToMatch moves the current match by delta (wrapping) and scrolls to it.
+func (m *TaskModel) jumpToMatch(delta int) {
+	n := len(m.out.matches)
+	if n == 0 {
+		return
+	}
+	m.out.match = ((m.out.match+delta)%n + n) % n
+	line := m.out.matches[m.out.match] - m.out.buf.Dropped()
+	m.out.follow = false
+	m.out.offset = line - m.visibleOutputHeight()/2
+	m.clampOutputOffset()
+}
+
+// jumpToFirstMatchFromView selects the first match at or below the current viewport.
+func (m *TaskModel) jumpToFirstMatchFromView() {
+	if len(m.out.matches) == 0 {
+		return
+	}
+	top := m.out.buf.Dropped() + m.out.offset
+	m.out.match = 0
+	for i, abs := range m.out.matches {
+		if abs >= top {
+			m.out.match = i
+			break
+		}
+	}
+	m.jumpToMatch(0)
+}
+
+func (m *TaskModel) handleOutputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.out.input {
+		if msg.String() == "ctrl+]" {
+			m.out.input = false
+			return m, nil
+		}
+		if j := m.interactiveJob(); j != nil {
+			_, _ = j.run.Write(keyBytes(msg))
+		}
+		return m, nil
+	}
+	if m.repeat.editing {
+		return m.handleRepeatKeys(msg)
+	}
+	if m.out.searching {
+		switch msg.String() {
+		case "esc":
+			m.out.searching = false
+			m.out.search.Blur()
+			m.out.query = ""
+			m.recomputeOutputMatches()
+			return m, nil
+		case "enter":
+			m.out.searching = false
+			m.out.search.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.out.search, cmd = m.out.search.Update(msg)
+		m.out.query = m.out.search.Value()
+		m.recomputeOutputMatches()
+		m.jumpToFirstMatchFromView()
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		// Cancel running jobs and repeating first; only quit once nothing
+		// is running.
+		repeating := m.stopRepeat()
+		if m.CancelJobs() {
+			m.setStatus("Cancelling…")
+			if m.jobsFinished() {
+				// Nothing was running, e.g. a job waiting to be retried.
+				m.setError(m.jobsSummary())
+			}
+			return m, nil
+		}
+		if repeating {
+			m.setStatus("Stopped repeating")
+			return m, nil
+		}
+		return m, m.quit()
+	case "R":
+		return m, m.startRepeatEdit()
+	case "esc", "q":
+		if m.out.query != "" {
+			m.out.query = ""
+			m.recomputeOutputMatches()
+			return m, nil
+		}
+		m.outputMode = false
+	case "/":
+		m.out.searching = true
+		m.out.search.SetValue("")
+		m.out.search.Focus()
+		return m, textinput.Blink
+	case "i":
+		if m.interactiveJob() != nil {
+			m.out.input = true
+			m.out.follow = true
+		}
+	case "n":
+		m.jumpToMatch(1)
+	case "N":
+		m.jumpToMatch(-1)
+	case "up", "k":
+		m.out.follow = false
+		m.out.offset--
+	case "down", "j":
+		m.out.offset++
+	case "pgup":
+		m.out.follow = false
+		m.out.offset -= m.visibleOutputHeight()
+	case "pgdown":
+		m.out.offset += m.visibleOutputHeight()
+	case "home", "g":
+		m.out.follow = false
+		m.out.offset = 0
+	case "end", "G":
+		m.out.follow = true
+	}
+	m.clampOutputOffset()
+	if m.out.follow {
+		m.scrollOutputToEnd()
+	} else if m.out.offset >= m.maxOutputOffset() {
+		// Scrolling back to the bottom resumes following new output.
+		m.out.follow = true
+	}
+	return m, nil
+}
+
+func (m *TaskModel) anyJobRunning() bool { return m.runningJobs() > 0 }
+
+// interactiveJob returns the running job attached to a terminal, if any.
+func (m *TaskModel) interactiveJob() *job {
+	for _, j := range m.jobs {
+		if j.running && j.run.Interactive() {
+			return j
+		}
+	}
+	return nil
+}
+
+// resizeJobs keeps the pseudo-terminal of interactive runs in sync with the pane.
+func (m *TaskModel) resizeJobs() {
+	if j := m.interactiveJob(); j != nil {
+		j.run.Resize(m.outputLineWidth(), m.visibleOutputHeight())
+	}
+}
+
+// keyBytes converts a key press into the bytes a terminal would send.
+func keyBytes(msg tea.KeyMsg) []byte {
+	switch msg.Type {
+	case tea.KeyRunes:
+		return []byte(string(msg.Runes))
+	case tea.KeySpace:
+		return []byte(" ")
+	case tea.KeyEnter:
+		return []byte("\r")
+	case tea.KeyBackspace:
+		return []byte{0x7f}
+	case tea.KeyTab:
+		return []byte("\t")
+	case tea.KeyEsc:
+		return []byte{0x1b}
+	case tea.KeyUp:
+		return []byte("\x1b[A")
+	case tea.KeyDown:
+		return []byte("\x1b[B")
+	case tea.KeyRight:
+		return []byte("\x1b[C")
+	case tea.KeyLeft:
+		return []byte("\x1b[D")
+	case tea.KeyHome:
+		return []byte("\x1b[H")
+	case tea.KeyEnd:
+		return []byte("\x1b[F")
+	case tea.KeyDelete:
+		return []byte("\x1b[3~")
+	}
+	// Control keys (ctrl+a … ctrl+z, ctrl+c, …) map to their ASCII codes.
+	if msg.Type >= 0 && msg.Type < 0x20 {
+		return []byte{byte(msg.Type)}
+	}
+	return nil
+}
+
+func (m *TaskModel) maxOutputOffset() int {
+	if m.out.buf == nil {
+		return 0
+	}
+	return max(0, m.out.buf.Len()-m.visibleOutputHeight())
+}
+
+func (m *TaskModel) clampOutputOffset() {
+	m.out.offset = max(0, min(m.out.offset, m.maxOutputOffset()))
+}
+
+func (m *TaskModel) scrollOutputToEnd() { m.out.offset = m.maxOutputOffset() }
+
+// outputLineWidth returns the usable text width inside the output box.
+func (m *TaskModel) outputLineWidth() int {
+	termWidth := int(float64(m.width) * 0.98)
+	if termWidth <= 0 {
+		termWidth = 98
+	}
+	appFrameW, _ := m.theme.AppContainer.GetFrameSize()
+	return max(40, termWidth-appFrameW) - 4 // box border + padding
+}
+
+// visibleOutputHeight returns how many output lines fit in the output pane.
+func (m *TaskModel) visibleOutputHeight() int {
+	const (
+		containerOverhead = 4 // AppContainer border + padding vertical
+		headerHeight      = 2
+		boxBorder         = 2
+		searchHeight      = 4 // box + bottom margin
+		footerHeight      = 4 // box + top margin
+	)
+	avail := m.height
+	if avail <= 0 {
+		avail = 24
+	}
+	h := avail - containerOverhead - headerHeight - boxBorder - m.toastHeight() - footerHeight
+	if m.out.searching || m.out.query != "" {
+		h -= searchHeight
+	}
+	return max(3, h)
+}
+
+func (m TaskModel) renderOutput() string {
+	var content strings.Builder
+
+	termWidth := int(float64(m.width) * 0.98)
+	if termWidth <= 0 {
+		termWidth = 98
+	}
+	appFrameW, _ := m.theme.AppContainer.GetFrameSize()
+	innerWidth := termWidth - appFrameW
+	if innerWidth < 40 {
+		innerWidth = 40
+	}
+
+	// Header: command line and run state
+	switch len(m.jobs) {
+	case 0:
+		content.WriteString(m.theme.AppTitle.Render("Output") + "\n\n")
+	case 1:
+		content.WriteString(m.theme.AppTitle.Render("$ "+m.jobs[0].title) + "\n")
+		content.WriteString(m.renderJobState(m.jobs[0]) + m.renderRepeat() + "\n")
+	default:
+		title := fmt.Sprintf("%d tasks in parallel (max %d)", len(m.jobs), m.jobLimit())
+		content.WriteString(m.theme.AppTitle.Render(title) + "\n")
+		var states []string
+		for _, j := range m.jobs {
+			name := lipgloss.NewStyle().Foreground(j.color).Render(j.task.Name)
+			states = append(states, name+" "+m.renderJobState(j))
+		}
+		content.WriteString(truncateStringToWidth(strings.Join(states, "  ")+m.renderRepeat(), innerWidth) + "\n")
+	}
+
+	if m.out.searching {
+		box := m.theme.SearchBox.Copy()
+		content.WriteString(box.Width(innerWidth).Render(m.out.search.View()) + "\n")
+	} else if m.out.query != "" {
+		pos := 0
+		if len(m.out.matches) > 0 {
+			pos = m.out.match + 1
+		}
+		info := fmt.Sprintf("🔍 %s  %d/%d  ( n/N jump  / edit  esc clear )", m.out.query, pos, len(m.out.matches))
+		box := m.theme.SearchBox.Copy()
+		content.WriteString(box.Width(innerWidth).Render(info) + "\n")
+	}
+
+	// Output lines
+	height := m.visibleOutputHeight()
+	lineWidth := m.outputLineWidth()
+	current := -1
+	if len(m.out.matches) > 0 {
+		current = m.out.matches[m.out.match]
+	}
+	var lines []string
+	if m.out.buf != nil {
+		end := min(m.out.buf.Len(), m.out.offset+height)
+		for i := m.out.offset; i < end; i++ {
+			line := truncateStringToWidth(m.out.buf.Line(i), lineWidth)
+			prefix, rest := m.splitJobPrefix(line)
+			lines = append(lines, prefix+m.highlightOutputLine(rest, m.out.buf.Dropped()+i == current))
+		}
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	box := m.theme.CommandBox.Copy()
+	content.WriteString(box.Width(innerWidth).Render(strings.Join(lines, "\n")) + "\n")
+
+	content.WriteString(m.renderToasts(innerWidth) + "\n")
+
+	parts := []string{"↑↓ scroll", "/ search", "n/N match", "End follow", "R repeat", "esc back"}
+	if m.out.input {
+		parts = []string{m.theme.Highlight.Render("typing into task"), "^] leave input"}
+	} else if m.repeat.editing {
+		parts = []string{m.renderRepeatEdit()}
+	} else {
+		if m.interactiveJob() != nil {
+			parts = append(parts, "i input")
+		}
+		if m.anyJobRunning() {
+			parts = append(parts, m.theme.Highlight.Render("^C cancel"))
+		}
+	}
+	footer := m.theme.FooterBox.Copy().Width(innerWidth).Render(strings.Join(parts, "  │  "))
+	content.WriteString(footer)
+
+	return m.theme.AppContainer.Copy().Width(termWidth).Render(content.String())
+}
+
+// renderJobState renders a short running/exit indicator for j.
+func (m TaskModel) renderJobState(j *job) string {
+	switch {
+	case j.running:
+		state := m.theme.Highlight.Render(fmt.Sprintf("running %s", time.Since(j.start).Round(time.Second)))
+		state += m.theme.Help.Render(j.attemptNote())
+		if progress := m.renderProgress(j); progress != "" {
+			state += " " + progress
+		}
+		return state
+	case !j.retryAt.IsZero():
+		wait := time.Until(j.retryAt).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		return m.theme.Error.Render(fmt.Sprintf("✗ exit %d, attempt %d/%d in %s", j.exitCode, j.attempt+1, j.attempts(), wait))
+	case !j.finished:
+		return m.theme.Help.Render("queued")
+	case j.canceled:
+		return m.theme.Error.Render("■ cancelled")
+	case j.exitCode == 0:
+		return m.theme.Status.Render(fmt.Sprintf("✓ exit 0 in %s", j.end.Sub(j.start).Round(time.Millisecond))) + j.attemptNote()
+	default:
+		return m.theme.Error.Render(fmt.Sprintf("✗ exit %d in %s", j.exitCode, j.end.Sub(j.start).Round(time.Millisecond))) + j.attemptNote()
+	}
+}
+
+// splitJobPrefix separates a job's name prefix from an output line and renders
+// it in the job's color. Lines without a known prefix are returned unchanged.
+func (m TaskModel) splitJobPrefix(line string) (string, string) {
+	for _, j := range m.jobs {
+		if j.prefix != "" && strings.HasPrefix(line, j.prefix) {
+			return lipgloss.NewStyle().Foreground(j.color).Render(j.prefix), line[len(j.prefix):]
+		}
+	}
+	return "", line
+}
+
+// highlightOutputLine renders case-insensitive occurrences of the search query.
+// The current match line is additionally rendered in reverse video.
+func (m TaskModel) highlightOutputLine(line string, isCurrent bool) string {
+	q := strings.ToLower(m.out.query)
+	lower := strings.ToLower(line)
+	if q == "" || len(lower) != len(line) {
+		return line
+	}
+	style := m.theme.Highlight
+	if isCurrent {
+		style = style.Copy().Reverse(true)
+	}
+	var b strings.Builder
+	rest, restLower := line, lower
+	for {
+		idx := strings.Index(restLower, q)
+		if idx == -1 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:idx])
+		b.WriteString(style.Render(rest[idx : idx+len(q)]))
+		rest, restLower = rest[idx+len(q):], restLower[idx+len(q):]
+	}
+	return b.String()
+}
@@ -0,0 +1,355 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paletteAction is a non-task command offered by the ctrl+k palette.
+type paletteAction struct {
+	title string
+	key   string // dedicated shortcut, if any, shown as a hint
+	run   func(m *TaskModel) tea.Cmd
+}
+
+// paletteActions lists the palette entries in display order.
+func paletteActions() []paletteAction {
+	return []paletteAction{
+		{"Refresh tasks", "r", func(m *TaskModel) tea.Cmd {
+			m.setStatus("Refreshing tasks...")
+			return m.refreshCmd()
+		}},
+		{"Cycle theme (dark/light/high contrast)", "", func(m *TaskModel) tea.Cmd {
+			m.cycleTheme()
+			return nil
+		}},
+		{"Cycle sort mode", "^S", func(m *TaskModel) tea.Cmd {
+			m.toggleSortMode()
+			m.setStatus(fmt.Sprintf("Sorted by %s", m.sortMode))
+			return nil
+		}},
+		{"Cycle tab grouping", "", func(m *TaskModel) tea.Cmd {
+			m.cycleGrouping()
+			return nil
+		}},
+		{"Toggle tree layout (sections instead of tabs)", "", func(m *TaskModel) tea.Cmd {
+			m.toggleTree()
+			return nil
+		}},
+		{"List all tabs", "M-T", func(m *TaskModel) tea.Cmd {
+			m.openTabMenu()
+			return nil
+		}},
+		{"Pin / unpin current tab", "^⇧↑", func(m *TaskModel) tea.Cmd {
+			m.togglePinTab()
+			return nil
+		}},
+		{"Switch project", "^P", func(m *TaskModel) tea.Cmd {
+			m.openProjectPicker()
+			return nil
+		}},
+		{"Show run history", "", func(m *TaskModel) tea.Cmd {
+			m.openHistory()
+			return nil
+		}},
+		{"Toggle never-run tasks only", "", func(m *TaskModel) tea.Cmd {
+			m.toggleNeverRun()
+			return nil
+		}},
+		{"Open config file", "", func(m *TaskModel) tea.Cmd {
+			return m.openConfigFile()
+		}},
+		{"New task", "^T", func(m *TaskModel) tea.Cmd {
+			return m.openCreateForm()
+		}},
+		{"Edit selected task's YAML", "F2", func(m *TaskModel) tea.Cmd {
+			return m.openTaskEditor()
+		}},
+		{"Edit note of selected task", "^N", func(m *TaskModel) tea.Cmd {
+			return m.openNoteEditor()
+		}},
+		{"Toggle detail pane", "^D", func(m *TaskModel) tea.Cmd {
+			m.showDetail = !m.showDetail
+			m.ensureSelectionVisible()
+			return nil
+		}},
+		{"Toggle rendered commands", "F3", func(m *TaskModel) tea.Cmd {
+			m.toggleRenderedCmds()
+			return nil
+		}},
+		{"Toggle command lines in the list", "M-P", func(m *TaskModel) tea.Cmd {
+			m.toggleCommandLines()
+			return nil
+		}},
+		{"Expand the commands of the selected task", "M-X", func(m *TaskModel) tea.Cmd {
+			m.toggleExpandCmds()
+			return nil
+		}},
+		{"Toggle header", "M-H", func(m *TaskModel) tea.Cmd {
+			m.toggleHeader()
+			return nil
+		}},
+		{"Toggle footer", "M-F", func(m *TaskModel) tea.Cmd {
+			m.toggleFooter()
+			return nil
+		}},
+		{"Toggle borders", "M-B", func(m *TaskModel) tea.Cmd {
+			m.toggleBorders()
+			return nil
+		}},
+		{"Toggle YAML of selected task in detail pane", "M-Y", func(m *TaskModel) tea.Cmd {
+			m.toggleYAML()
+			return nil
+		}},
+		{"Reopen output pane", "^L", func(m *TaskModel) tea.Cmd {
+			if len(m.jobs) > 0 {
+				m.outputMode = true
+			} else {
+				m.setStatus("Nothing has run inside the TUI yet")
+			}
+			return nil
+		}},
+	}
+}
+
+func (m *TaskModel) openPalette() tea.Cmd {
+	pi := textinput.New()
+	pi.Placeholder = "Type to filter actions"
+	pi.CharLimit = 64
+	pi.Width = 40
+	pi.Prompt = "> "
+	pi.Focus()
+	m.paletteInput = pi
+	m.paletteSelected = 0
+	m.paletteMode = true
+	return textinput.Blink
+}
+
+// filteredPaletteActions matches the query like the task search does: a
+// case-insensitive substring of the title.
+func (m TaskModel) filteredPaletteActions() []paletteAction {
+	q := strings.ToLower(strings.TrimSpace(m.paletteInput.Value()))
+	var out []paletteAction
+	for _, a := range paletteActions() {
+		if q == "" || strings.Contains(strings.ToLower(a.title), q) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (m *TaskModel) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	actions := m.filteredPaletteActions()
+	switch msg.String() {
+	case "esc", "ctrl+k":
+		m.paletteMode = false
+		return m, nil
+	case "up", "ctrl+p":
+		if m.paletteSelected > 0 {
+			m.paletteSelected--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.paletteSelected < len(actions)-1 {
+			m.paletteSelected++
+		}
+		return m, nil
+	case "enter":
+		m.paletteMode = false
+		if len(actions) == 0 {
+			return m, nil
+		}
+		return m, actions[m.paletteSelected].run(m)
+	}
+
+	var cmd tea.Cmd
+	m.paletteInput, cmd = m.paletteInput.Update(msg)
+	if n := len(m.filteredPaletteActions()); m.paletteSelected >= n {
+		m.paletteSelected = max(0, n-1)
+	}
+	return m, cmd
+}
+
+// cycleTheme switches to the next of the dark, light and high-contrast
+// themes.
+func (m *TaskModel) cycleTheme() {
+	next := (slices.Index(styles.ThemeNames, m.themeName) + 1) % len(styles.ThemeNames)
+	m.setTheme(styles.ThemeNames[next])
+	m.setStatus(fmt.Sprintf("Theme: %s", m.themeName))
+}
+
+// configEditedMsg reports that the config editor exited.
+type configEditedMsg struct{ err error }
+
+// openConfigFile suspends the TUI and opens the user config in $VISUAL/$EDITOR.
+func (m *TaskModel) openConfigFile() tea.Cmd {
+	path, err := config.Path()
+	if err != nil {
+		m.setError(fmt.Sprintf("No config directory: %v", err))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		m.setError(fmt.Sprintf("Could not create %s: %v", filepath.Dir(path), err))
+		return nil
+	}
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return configEditedMsg{err: err}
+	})
+}
+
+// editorCommand builds the command opening path in the user's editor.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// $EDITOR may carry arguments, e.g. "code --wait".
+	parts := strings.Fields(editor)
+	return exec.Command(parts[0], append(parts[1:], path)...)
+}
+
+func (m *TaskModel) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.historyMode = false
+	case "up", "k":
+		m.historySelected = max(0, m.historySelected-1)
+	case "down", "j":
+		m.historySelected = min(len(m.state.Runs)-1, m.historySelected+1)
+	case "enter", "l":
+		return m, m.openHistoryLog()
+	case "d":
+		return m, m.openHistoryDiff()
+	}
+	return m, nil
+}
+
+func (m TaskModel) renderPalette() string {
+	sections := []string{}
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Actions")
+	sections = append(sections, header, m.paletteInput.View(), "")
+
+	actions := m.filteredPaletteActions()
+	if len(actions) == 0 {
+		sections = append(sections, m.theme.Help.Render("No matching actions"))
+	}
+	for i, a := range actions {
+		hint := ""
+		if a.key != "" {
+			hint = "  " + m.theme.Help.Render(a.key)
+		}
+		if i == m.paletteSelected {
+			sections = append(sections, m.theme.Highlight.Render("▶ "+a.title)+hint)
+		} else {
+			sections = append(sections, "  "+a.title+hint)
+		}
+	}
+
+	helperText := fmt.Sprintf("%s run  %s move  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("↑↓"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+
+	return m.renderDialog(sections)
+}
+
+func (m TaskModel) renderHistory() string {
+	sections := []string{}
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Run history")
+	sections = append(sections, header, "")
+
+	keys := m.historyKeys()
+	if len(keys) == 0 {
+		sections = append(sections, m.theme.Help.Render("No runs recorded for this project yet"))
+	}
+	const maxRows = 15
+	// Scroll so the selection stays in view.
+	first := max(0, m.historySelected-maxRows+1)
+	if first > 0 {
+		sections = append(sections, m.theme.Help.Render(fmt.Sprintf("… %d more", first)))
+	}
+	now := time.Now()
+	for i := first; i < len(keys); i++ {
+		if i == first+maxRows {
+			sections = append(sections, m.theme.Help.Render(fmt.Sprintf("… %d more", len(keys)-i)))
+			break
+		}
+		k := keys[i]
+		st := m.state.Runs[k]
+		label := padRight(historyLabel(k), 30)
+		if i == m.historySelected {
+			label = m.theme.Highlight.Render("▶ " + label)
+		} else {
+			label = "  " + label
+		}
+		sections = append(sections, label+" "+
+			m.theme.Help.Render(fmt.Sprintf("%3d× · %s ago", st.Count, now.Sub(st.Last).Round(time.Second))))
+	}
+
+	helperText := fmt.Sprintf("%s open log  %s diff last two  %s move  %s close",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("d"),
+		m.theme.Highlight.Render("↑↓"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
+
+// historyLabel turns a taskKey ("backend:project:name") back into a readable label.
+func historyLabel(key string) string {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) != 3 {
+		return key
+	}
+	label := parts[2]
+	if parts[1] != "" {
+		label = parts[1] + "/" + label
+	}
+	if parts[0] != "task" {
+		label += " (" + parts[0] + ")"
+	}
+	return label
+}
+
+// renderDialog centers a bordered overlay, the same frame the env editor and
+// project picker use.
+func (m TaskModel) renderDialog(sections []string) string {
+	dialogBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.HighlightColor).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBox,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
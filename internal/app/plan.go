@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// With show_plan set, running a task that has deps or calls other tasks
+// first shows the order its tasks will run in, and runs it on confirmation.
+
+// SetShowPlan sets whether runs of tasks with deps show their plan first.
+func (m *TaskModel) SetShowPlan(show bool) { m.showPlan = show }
+
+// plan returns the tasks running t starts, in order, when it starts any
+// besides itself and show_plan is set.
+func (m TaskModel) plan(t taskmeta.Task) []taskmeta.Step {
+	if !m.showPlan || t.Backend != taskmeta.BackendTask {
+		return nil
+	}
+	steps := taskmeta.Plan(m.tasks, t)
+	if len(steps) < 2 {
+		return nil
+	}
+	return steps
+}
+
+// renderPlan renders the steps as a numbered list for the confirmation
+// dialog, indented by how deep each step is reached.
+func (m TaskModel) renderPlan(steps []taskmeta.Step) []string {
+	lines := []string{m.theme.Help.Render("Runs, deps first (deps of one task run in parallel):")}
+	limit := max(3, m.height-16)
+	for i, s := range steps {
+		if i == limit {
+			lines = append(lines, m.theme.Help.Render(fmt.Sprintf("   … %d more", len(steps)-limit)))
+			break
+		}
+		line := fmt.Sprintf("%3d. %s%s", i+1, strings.Repeat("  ", s.Depth), s.Name)
+		switch {
+		case s.Cycle:
+			line += m.theme.Error.Render("  cycle")
+		case s.Unknown:
+			line += m.theme.Help.Render("  not listed")
+		}
+		if s.Via != "" {
+			line += m.theme.Help.Render("  " + s.Via)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// SetHideUnsupported drops tasks whose platforms: list excludes the current
+// OS/architecture instead of listing them grayed out.
+func (m *TaskModel) SetHideUnsupported(hide bool) { m.hideUnsupported = hide }
+
+// supportedTasks filters out unsupported tasks when they are to be hidden.
+func (m TaskModel) supportedTasks(tasks []taskmeta.Task) []taskmeta.Task {
+	if !m.hideUnsupported {
+		return tasks
+	}
+	out := tasks[:0:0]
+	for _, t := range tasks {
+		if t.Supported() {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// platformBadge marks a task that cannot run here with the platforms it is for.
+func (m TaskModel) platformBadge(t taskmeta.Task) string {
+	if t.Supported() {
+		return ""
+	}
+	return m.theme.Help.Render("(" + strings.Join(t.Platforms, ", ") + " only)")
+}
+
+// platformLines explains in the detail pane why t cannot run here.
+func (m TaskModel) platformLines(t taskmeta.Task) []string {
+	if t.Supported() {
+		return nil
+	}
+	return []string{m.theme.Error.Render(fmt.Sprintf("Not available on %s", taskmeta.Platform())),
+		m.theme.Help.Render("platforms: " + strings.Join(t.Platforms, ", "))}
+}
+
+// unsupportedError is the message shown instead of running t, which Task
+// would skip anyway.
+func unsupportedError(t taskmeta.Task) string {
+	return fmt.Sprintf("%s only runs on %s, not on %s", t.Name, strings.Join(t.Platforms, ", "), taskmeta.Platform())
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"fmt"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+)
+
+// Sessions of taskg ssh-serve follow the remote policy (remote: in the
+// config file): tasks it denies are marked and refused, and those it lists
+// under confirm ask first.
+
+// SetPolicy sets the remote policy of the session.
+func (m *TaskModel) SetPolicy(p config.Policy) { m.policy = &p }
+
+// allowed reports whether the policy, if any, lets t run.
+func (m TaskModel) allowed(t taskmeta.Task) bool {
+	return m.policy == nil || m.policy.Allows(t.Name)
+}
+
+// refusePolicy reports, with an error, whether the policy forbids running t.
+func (m *TaskModel) refusePolicy(t taskmeta.Task) bool {
+	if m.allowed(t) {
+		return false
+	}
+	m.setError(fmt.Sprintf("%s may not be run from this session", t.Name))
+	return true
+}
+
+// policyBadge marks tasks the policy does not allow to run.
+func (m TaskModel) policyBadge(t taskmeta.Task) string {
+	if m.allowed(t) {
+		return ""
+	}
+	return m.theme.Help.Render("(not allowed)")
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Presets are named sets of variable values saved from the variables form
+// with Ctrl+S. Running a task that has presets opens a menu of them first;
+// they are kept per project in .taskg/state.json.
+
+// presets returns the saved presets of t.
+func (m TaskModel) presets(t taskmeta.Task) []state.Preset {
+	if m.state == nil {
+		return nil
+	}
+	return m.state.Presets[taskKey(t)]
+}
+
+// openPresets shows the preset menu for t when it has presets.
+func (m *TaskModel) openPresets(t taskmeta.Task) bool {
+	if len(m.presets(t)) == 0 {
+		return false
+	}
+	m.presetMode = true
+	m.presetSelected = 0
+	return true
+}
+
+// modalArgs returns the values of the variables form as VAR=value args.
+func (m TaskModel) modalArgs() []string {
+	var args []string
+	for i, v := range m.modalVariables {
+		args = append(args, fmt.Sprintf("%s=%s", v.Name, m.modalValue(i)))
+	}
+	return args
+}
+
+// presetValues turns the VAR=value args of a preset into a map.
+func presetValues(args []string) map[string]string {
+	values := map[string]string{}
+	for _, a := range args {
+		if k, v, ok := strings.Cut(a, "="); ok {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+func (m *TaskModel) handlePresetKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	task := m.filteredTasks[m.selected]
+	list := m.presets(task)
+	// The last entry opens the form with the defaults.
+	n := len(list) + 1
+	custom := m.presetSelected == len(list)
+	switch msg.String() {
+	case "esc", "q":
+		m.presetMode = false
+	case "up", "k":
+		m.presetSelected = (m.presetSelected + n - 1) % n
+	case "down", "j":
+		m.presetSelected = (m.presetSelected + 1) % n
+	case "enter":
+		m.presetMode = false
+		if custom {
+			m.presetEditing = ""
+			vars, _ := task.UsageVars()
+			return m, m.openVariables(vars, nil)
+		}
+		return m, m.execute(task, list[m.presetSelected].Args)
+	case "e":
+		if custom {
+			break
+		}
+		m.presetMode = false
+		p := list[m.presetSelected]
+		m.presetEditing = p.Name
+		vars, _ := task.UsageVars()
+		return m, m.openVariables(vars, presetValues(p.Args))
+	case "d":
+		if custom {
+			break
+		}
+		name := list[m.presetSelected].Name
+		m.state.DeletePreset(taskKey(task), name)
+		m.savePresets(fmt.Sprintf("Deleted preset %s of %s", name, task.Name))
+		if len(m.presets(task)) == 0 {
+			m.presetMode = false
+		}
+		m.presetSelected = min(m.presetSelected, len(m.presets(task)))
+	}
+	return m, nil
+}
+
+// startPresetNaming asks for a name to save the form's values under,
+// suggesting the preset the form was opened with.
+func (m *TaskModel) startPresetNaming() tea.Cmd {
+	if m.state == nil || m.projectRoot == "" {
+		m.setWarning("Presets need a project to be saved in")
+		return nil
+	}
+	ti := textinput.New()
+	ti.Placeholder = "name, e.g. prod"
+	ti.CharLimit = 64
+	ti.Width = 30
+	ti.Prompt = ""
+	ti.SetValue(m.presetEditing)
+	m.modalInputs[m.modalFocused].Blur()
+	ti.Focus()
+	m.presetName = ti
+	m.presetNaming = true
+	return textinput.Blink
+}
+
+func (m *TaskModel) handlePresetNameKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.presetNaming = false
+		m.modalInputs[m.modalFocused].Focus()
+		return m, textinput.Blink
+	case "enter":
+		name := strings.TrimSpace(m.presetName.Value())
+		if name == "" {
+			return m, nil
+		}
+		task := m.filteredTasks[m.selected]
+		m.state.SavePreset(taskKey(task), state.Preset{Name: name, Args: m.modalArgs()})
+		m.savePresets(fmt.Sprintf("Saved preset %s of %s", name, task.Name))
+		m.presetEditing = name
+		m.presetNaming = false
+		m.modalInputs[m.modalFocused].Focus()
+		return m, textinput.Blink
+	}
+	var cmd tea.Cmd
+	m.presetName, cmd = m.presetName.Update(msg)
+	return m, cmd
+}
+
+func (m *TaskModel) savePresets(status string) {
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save state: %v", err))
+		return
+	}
+	m.setStatus(status)
+}
+
+func (m TaskModel) renderPresets() string {
+	task := m.filteredTasks[m.selected]
+	list := m.presets(task)
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Run " + task.Name + " with")
+	sections := []string{header, ""}
+	for i := 0; i <= len(list); i++ {
+		line := m.theme.Accent.Render("Other values…")
+		if i < len(list) {
+			line = list[i].Name + "  " + m.theme.Help.Render(strings.Join(list[i].Args, " "))
+		}
+		prefix := "  "
+		if i == m.presetSelected {
+			prefix = m.theme.Highlight.Render("▶ ")
+		}
+		sections = append(sections, prefix+line)
+	}
+	helperText := fmt.Sprintf("%s run  %s edit  %s delete  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("e"),
+		m.theme.Highlight.Render("d"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
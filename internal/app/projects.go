@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rememberProject records root at the front of the recent projects list.
+func rememberProject(root string) error {
+	recent, err := state.LoadRecent()
+	if err != nil {
+		return err
+	}
+	recent.Add(root)
+	return recent.Save()
+}
+
+// openProjectPicker lists recently opened projects that still exist on disk.
+func (m *TaskModel) openProjectPicker() {
+	if m.refuseReadOnly() || m.refuseGlobal("switch projects") {
+		return
+	}
+	recent, err := state.LoadRecent()
+	if err != nil {
+		m.setWarning(fmt.Sprintf("Could not read recent projects: %v", err))
+		return
+	}
+	m.projectChoices = nil
+	for _, p := range recent.Projects {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			m.projectChoices = append(m.projectChoices, p)
+		}
+	}
+	if len(m.projectChoices) == 0 {
+		m.setStatus("No recent projects yet")
+		return
+	}
+	m.projectPicker = true
+	m.projectSelected = 0
+	// Preselect the next project rather than the one already open.
+	if len(m.projectChoices) > 1 && m.projectChoices[0] == m.projectRoot {
+		m.projectSelected = 1
+	}
+}
+
+func (m *TaskModel) handleProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "ctrl+p":
+		m.projectPicker = false
+	case "up", "k":
+		if m.projectSelected > 0 {
+			m.projectSelected--
+		}
+	case "down", "j":
+		if m.projectSelected < len(m.projectChoices)-1 {
+			m.projectSelected++
+		}
+	case "enter":
+		m.projectPicker = false
+		return m, m.switchProject(m.projectChoices[m.projectSelected])
+	}
+	return m, nil
+}
+
+// switchProject points the model at another project root and rediscovers its
+// tasks. In-TUI runs keep their own directory, so switching while they are
+// still running is refused rather than orphaning them.
+func (m *TaskModel) switchProject(root string) tea.Cmd {
+	if root == m.projectRoot {
+		return nil
+	}
+	if m.runningJobs() > 0 {
+		m.setWarning("Cannot switch projects while tasks are running (^L to view them)")
+		return nil
+	}
+	m.saveUIState()
+	m.envOverrides = make(map[string]map[string]string)
+	m.marked = make(map[string]bool)
+	m.jobs = nil
+	m.errorMessage = ""
+	m.tasks = nil
+	m.originalTasks = nil
+	m.index = nil
+	m.selected = 0
+	m.listOffset = 0
+	m.SetProjectRoot(root)
+	m.projectName = filepath.Base(root)
+	m.buildTabs()
+	m.updateFilter()
+	m.setStatus(fmt.Sprintf("Loading %s...", m.projectName))
+	return tea.Batch(m.refreshCmd(), m.direnvCmd(), m.headerCmd())
+}
+
+// renderProjectColumn renders t's subproject padded to the widest one, for the
+// column layout of recursive mode. Root tasks are labelled ".".
+func (m TaskModel) renderProjectColumn(t taskmeta.Task) string {
+	width := 0
+	for _, o := range m.originalTasks {
+		width = max(width, lipgloss.Width(projectLabel(o)))
+	}
+	return m.theme.Accent.Render(padRight(projectLabel(t), width)) + "  "
+}
+
+func projectLabel(t taskmeta.Task) string {
+	if t.Project == "" {
+		return "."
+	}
+	return t.Project
+}
+
+func (m TaskModel) renderProjectPicker() string {
+	sections := []string{}
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Recent projects")
+	sections = append(sections, header, "")
+
+	for i, p := range m.projectChoices {
+		name := filepath.Base(p)
+		if p == m.projectRoot {
+			name += " (current)"
+		}
+		line := fmt.Sprintf("%s  %s", name, m.theme.Help.Render(p))
+		if i == m.projectSelected {
+			line = m.theme.Highlight.Render("▶ "+name) + "  " + m.theme.Help.Render(p)
+		} else {
+			line = "  " + line
+		}
+		sections = append(sections, line)
+	}
+
+	helperText := fmt.Sprintf("%s open  %s move  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("↑↓"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+
+	dialogBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.HighlightColor).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBox,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tasks with a prompt: ask "<prompt> [y/N]" on the terminal before running.
+// That works as is when taskg execs the task after quitting, and for a single
+// in-TUI run on a pseudo-terminal, which starts with keystrokes forwarded so
+// the answer reaches the task. Runs on plain pipes have no terminal, so Task
+// would cancel them; taskg asks the question itself and passes --yes instead.
+// The built-in runner ignores prompts, so its tasks are always asked by taskg.
+
+// promptBadge marks tasks that ask for confirmation themselves.
+func (m TaskModel) promptBadge(t taskmeta.Task) string {
+	if t.Prompt == "" {
+		return ""
+	}
+	return m.theme.Warning.Render("?")
+}
+
+// promptLines shows t's prompt in the detail pane.
+func (m TaskModel) promptLines(t taskmeta.Task) []string {
+	if t.Prompt == "" {
+		return nil
+	}
+	return []string{m.theme.Warning.Render("? prompt: ") + t.Prompt}
+}
+
+// asksViaTaskg reports whether taskg has to ask t's prompt because the run
+// will not have a terminal Task could ask on.
+func (m TaskModel) asksViaTaskg(t taskmeta.Task, inline, parallel bool) bool {
+	if t.Prompt == "" {
+		return false
+	}
+	return t.Script != "" || (inline && (parallel || !m.usePTY))
+}
+
+// promptedJobs returns the jobs whose prompt taskg has to ask before a
+// parallel run.
+func (m TaskModel) promptedJobs(jobs []*job) []*job {
+	var out []*job
+	for _, j := range jobs {
+		if m.asksViaTaskg(j.task, true, len(jobs) > 1) {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+// startAnswered starts jobs whose prompts were answered in taskg's dialog.
+func (m *TaskModel) startAnswered(jobs []*job) tea.Cmd {
+	for _, j := range jobs {
+		j.yes = j.task.Prompt != ""
+	}
+	return m.startRuns(jobs)
+}
+
+// focusPrompt forwards keystrokes to a freshly started run of t, so its
+// prompt can be answered right away.
+func (m *TaskModel) focusPrompt(t taskmeta.Task) {
+	if t.Prompt != "" && m.interactiveJob() != nil {
+		m.out.input = true
+		m.setStatus("Answer the prompt · Ctrl+] stops typing into the task")
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+)
+
+// Tasks from remote Taskfiles run code fetched over the network, so the
+// first run of each remote source asks for trust. Trusted URLs are kept per
+// project in the user config directory (see state.Trust), out of reach of
+// the repository.
+
+// remoteHost shortens a remote Taskfile URL for the list.
+func remoteHost(u string) string {
+	if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return u
+}
+
+// remoteBadge marks tasks that come from a remote Taskfile.
+func (m TaskModel) remoteBadge(t taskmeta.Task) string {
+	src := t.RemoteSource()
+	if src == "" {
+		return ""
+	}
+	return m.theme.Warning.Render("⇣ " + remoteHost(src))
+}
+
+// remoteLines names the remote source of t in the detail pane.
+func (m TaskModel) remoteLines(t taskmeta.Task) []string {
+	src := t.RemoteSource()
+	if src == "" {
+		return nil
+	}
+	trust := "not trusted yet: taskg asks before the first run"
+	if m.trusted(t) {
+		trust = "trusted"
+	}
+	return []string{m.theme.Warning.Render("⇣ remote: ") + src, m.theme.Help.Render("  " + trust)}
+}
+
+// trusted reports whether the remote source of t is trusted in its project.
+func (m TaskModel) trusted(t taskmeta.Task) bool {
+	return m.trustedRemotes.Trusted(t.WorkDir(m.projectRoot), t.RemoteSource())
+}
+
+// trust records the remote source of t as trusted in its project.
+func (m *TaskModel) trust(t taskmeta.Task) {
+	if m.trusted(t) {
+		return
+	}
+	trust, err := state.LoadTrust()
+	if err != nil {
+		m.setError(fmt.Sprintf("Could not read trusted Taskfiles: %v", err))
+		return
+	}
+	trust.Add(t.WorkDir(m.projectRoot), t.RemoteSource())
+	if err := trust.Save(); err != nil {
+		m.setError(fmt.Sprintf("Could not save trust: %v", err))
+		return
+	}
+	m.trustedRemotes = trust
+}
+
+// needsTrust returns the remote source of t when it has not been trusted.
+func (m TaskModel) needsTrust(t taskmeta.Task) string {
+	if src := t.RemoteSource(); src != "" && !m.trusted(t) {
+		return src
+	}
+	return ""
+}
+
+// experimentEnv returns the TASK_X_ variables t's Taskfile needs to load.
+func (m TaskModel) experimentEnv(t taskmeta.Task) []string {
+	if t.Backend != taskmeta.BackendTask || t.Script != "" || m.projectRoot == "" {
+		return nil
+	}
+	return taskmeta.ExperimentEnv(t.WorkDir(m.projectRoot))
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCachedRows bounds the row cache; it is simply emptied when full.
+const maxCachedRows = 2048
+
+// rowKey identifies everything a rendered list row depends on besides the
+// task itself, the theme and the project column width, which reset the cache
+// when they change.
+type rowKey struct {
+	task     string
+	selected bool
+	marked   bool
+	hotkey   int
+	status   upToDate
+	width    int
+	// lastRun is the age of the last run as shown, which goes stale while
+	// the row stays cached otherwise.
+	lastRun string
+	// cmdScroll and cmdExpanded are how the commands of the selected row
+	// are shown.
+	cmdScroll   int
+	cmdExpanded bool
+}
+
+// renderCache memoizes width-bound styles and rendered list rows between
+// frames, so scrolling only renders rows that were not on screen before. It
+// is held by pointer so the value-receiver View can fill it.
+type renderCache struct {
+	width    int
+	box      lipgloss.Style // CommandBox bound to width
+	selected lipgloss.Style // SelectedWire bound to width
+	rows     map[rowKey]string
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{rows: make(map[rowKey]string)}
+}
+
+// invalidateRows drops cached rows and styles, e.g. after a theme change or
+// when the task list is replaced.
+func (m *TaskModel) invalidateRows() {
+	m.render = newRenderCache()
+}
+
+// boxStyles returns the row box styles bound to width, creating them only
+// when the width changed.
+func (m TaskModel) boxStyles(width int) (box, selected lipgloss.Style) {
+	c := m.render
+	if c.width != width {
+		c.width = width
+		c.box = m.theme.CommandBox.Copy().Width(width)
+		c.selected = m.theme.SelectedWire.Copy().Width(width)
+		// Rows of the old width are never shown again.
+		c.rows = make(map[rowKey]string)
+	}
+	return c.box, c.selected
+}
+
+// cachedRow returns the rendered row of t, rendering it on a cache miss.
+func (m TaskModel) cachedRow(t taskmeta.Task, selected bool, hotkey, width int) string {
+	key := taskKey(t)
+	scroll, expanded := m.cmdView(t, selected)
+	k := rowKey{
+		task:     key,
+		selected: selected,
+		marked:   m.marked[key],
+		hotkey:   hotkey,
+		status:   m.taskStatus[key],
+		width:    width,
+		lastRun:  m.lastRunAge(t),
+
+		cmdScroll:   scroll,
+		cmdExpanded: expanded,
+	}
+	if row, ok := m.render.rows[k]; ok {
+		return row
+	}
+	row := m.renderRow(t, selected, hotkey, width)
+	if len(m.render.rows) >= maxCachedRows {
+		m.render.rows = make(map[rowKey]string)
+	}
+	m.render.rows[k] = row
+	return row
+}
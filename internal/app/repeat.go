@@ -0,0 +1,151 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Like watch, R in the output pane re-runs the finished run group every so
+// often. The interval counts from the end of a run, so runs never overlap,
+// and repeating goes on while the task list is shown. Ctrl+C or starting
+// another run stops it.
+
+// repeatState is the re-run schedule of the output pane's run group.
+type repeatState struct {
+	every   time.Duration // zero when not repeating
+	next    time.Time     // when the next run starts, zero while one runs
+	gen     int           // tells ticks of earlier schedules apart
+	editing bool
+	input   textinput.Model
+}
+
+// repeatMsg starts the next run of schedule gen.
+type repeatMsg struct{ gen int }
+
+// defaultRepeat is suggested when repeating is turned on.
+const defaultRepeat = 10 * time.Second
+
+// parseInterval reads an interval such as "30s" or "5m"; a bare number
+// counts seconds.
+func parseInterval(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// startRepeatEdit asks for the interval, suggesting the current one.
+func (m *TaskModel) startRepeatEdit() tea.Cmd {
+	if len(m.jobs) == 0 {
+		return nil
+	}
+	ti := textinput.New()
+	ti.Placeholder = "e.g. 30s or 5m"
+	ti.CharLimit = 16
+	ti.Width = 16
+	ti.Prompt = ""
+	ti.SetValue(cmp.Or(m.repeat.every, defaultRepeat).String())
+	ti.CursorEnd()
+	ti.Focus()
+	m.repeat.input = ti
+	m.repeat.editing = true
+	return textinput.Blink
+}
+
+func (m *TaskModel) handleRepeatKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.repeat.editing = false
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.repeat.input.Value())
+		if value == "" || value == "0" {
+			m.repeat.editing = false
+			if m.stopRepeat() {
+				m.setStatus("Stopped repeating")
+			}
+			return m, nil
+		}
+		every, err := parseInterval(value)
+		if err != nil || every < time.Second {
+			m.setWarning("Repeat every: give at least 1s, e.g. 30s or 5m")
+			return m, nil
+		}
+		m.repeat.editing = false
+		m.stopRepeat()
+		m.repeat.every = every
+		m.setStatus(fmt.Sprintf("Repeating every %s", every))
+		if m.jobsFinished() {
+			return m, m.scheduleRepeat()
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.repeat.input, cmd = m.repeat.input.Update(msg)
+	return m, cmd
+}
+
+// stopRepeat ends repeating and reports whether it was on.
+func (m *TaskModel) stopRepeat() bool {
+	on := m.repeat.every > 0
+	m.repeat.every = 0
+	m.repeat.next = time.Time{}
+	m.repeat.gen++
+	return on
+}
+
+// scheduleRepeat starts the wait for the next run once the run group has
+// finished.
+func (m *TaskModel) scheduleRepeat() tea.Cmd {
+	if m.repeat.every == 0 {
+		return nil
+	}
+	m.repeat.next = time.Now().Add(m.repeat.every)
+	gen := m.repeat.gen
+	return tea.Tick(m.repeat.every, func(time.Time) tea.Msg { return repeatMsg{gen: gen} })
+}
+
+// handleRepeat runs the jobs of the run group again, leaving the list or
+// the output pane shown as it is.
+func (m *TaskModel) handleRepeat(msg repeatMsg) tea.Cmd {
+	if msg.gen != m.repeat.gen || m.repeat.every == 0 || !m.jobsFinished() {
+		return nil
+	}
+	jobs := make([]*job, len(m.jobs))
+	for i, j := range m.jobs {
+		jobs[i] = &job{task: j.task, args: j.args, yes: j.yes}
+	}
+	every, outputMode := m.repeat.every, m.outputMode
+	cmd := m.startRuns(jobs)
+	m.repeat.every, m.outputMode = every, outputMode
+	return cmd
+}
+
+// renderRepeat describes the schedule for the output pane's header, e.g.
+// "↻ every 30s, next in 12s".
+func (m TaskModel) renderRepeat() string {
+	if m.repeat.every == 0 {
+		return ""
+	}
+	text := "↻ every " + m.repeat.every.String()
+	if !m.repeat.next.IsZero() {
+		wait := time.Until(m.repeat.next).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		text += ", next in " + wait.String()
+	}
+	return "  " + m.theme.Accent.Render(text)
+}
+
+// renderRepeatEdit is the footer while the interval is edited.
+func (m TaskModel) renderRepeatEdit() string {
+	return m.theme.Highlight.Render("Repeat every: ") + m.repeat.input.View() + "  " +
+		m.theme.Help.Render("ENTER set  empty to stop  ESC cancel")
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Failed in-TUI runs can be retried a few times, e.g. flaky integration
+// tests. The policy comes from the task's taskg_retry var, else from retry:
+// in the config file; each attempt is announced in the output pane and gets
+// its own run log.
+
+// retryMsg starts the next attempt of a job once its backoff has passed.
+type retryMsg struct{ job *job }
+
+// SetRetry sets the default retry policy of in-TUI runs.
+func (m *TaskModel) SetRetry(r config.Retry) { m.retry = r }
+
+// retryPolicy returns how often a failed run of t is retried.
+func (m TaskModel) retryPolicy(t taskmeta.Task) taskmeta.Retry {
+	if t.Retry != nil {
+		return *t.Retry
+	}
+	if len(m.retry.Tasks) > 0 && !config.MatchAny(m.retry.Tasks, t.Name) {
+		return taskmeta.Retry{}
+	}
+	return taskmeta.Retry{Count: m.retry.Count, Backoff: m.retry.Backoff}
+}
+
+// attempts returns how many attempts j may take in all.
+func (j *job) attempts() int { return j.retry.Count + 1 }
+
+// attemptNote tells which attempt a retried job is on, e.g. " (attempt
+// 2/4)", and is empty for a first attempt.
+func (j *job) attemptNote() string {
+	if j.attempt <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (attempt %d/%d)", j.attempt, j.attempts())
+}
+
+// retryJob schedules another attempt of j, which just ended, if it failed
+// and has retries left.
+func (m *TaskModel) retryJob(j *job) tea.Cmd {
+	if j.canceled || (j.exitCode == 0 && j.err == nil) || j.attempt >= j.attempts() {
+		return nil
+	}
+	delay := j.retry.Delay(j.attempt)
+	j.retryAt = time.Now().Add(delay)
+	line := fmt.Sprintf("↻ attempt %d of %d failed with exit %d, retrying", j.attempt, j.attempts(), j.exitCode)
+	if delay > 0 {
+		line += " in " + delay.String()
+	}
+	m.appendOutput(j, j.prefix+line, false)
+	return tea.Tick(delay, func(time.Time) tea.Msg { return retryMsg{job: j} })
+}
+
+// handleRetry starts the next attempt of a job unless it was cancelled
+// while waiting or belongs to an earlier run group.
+func (m *TaskModel) handleRetry(msg retryMsg) tea.Cmd {
+	j := msg.job
+	if j.finished || m.jobForRun(j.run) != j {
+		return nil
+	}
+	j.retryAt = time.Time{}
+	j.attempt++
+	m.appendOutput(j, j.prefix+fmt.Sprintf("── attempt %d of %d ──", j.attempt, j.attempts()), false)
+	return m.launch(j)
+}
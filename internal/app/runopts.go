@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Ctrl+F opens the run options: Task CLI flags toggled for the next run of
+// the selected task, e.g. --force to rebuild a target that is up to date.
+// The toggles are remembered for the session.
+
+// runFlag is a Task flag offered in the run options.
+type runFlag struct {
+	key  string // toggles the flag directly
+	flag string
+	help string
+}
+
+var runFlags = []runFlag{
+	{"f", "--force", "run even if the task is up to date"},
+	{"s", "--silent", "do not print the commands"},
+	{"v", "--verbose", "tell what Task is doing"},
+	{"p", "--parallel", "also run the marked tasks, all at once"},
+}
+
+// openRunOptions shows the run options for the selected task.
+func (m *TaskModel) openRunOptions() {
+	if len(m.filteredTasks) == 0 || m.refuseReadOnly() {
+		return
+	}
+	t := m.filteredTasks[m.selected]
+	if t.Backend != taskmeta.BackendTask || t.Script != "" {
+		m.setWarning("Run options are flags of the task binary, so they only apply to Taskfile tasks")
+		return
+	}
+	if m.runOpts == nil {
+		m.runOpts = map[string]bool{}
+	}
+	m.runOptsMode = true
+	m.runOptsSelected = 0
+}
+
+// parallelTasks returns the marked tasks --parallel would run next to t:
+// Taskfile tasks of the same project.
+func (m TaskModel) parallelTasks(t taskmeta.Task) []taskmeta.Task {
+	var out []taskmeta.Task
+	for _, o := range m.markedTasks() {
+		if taskKey(o) != taskKey(t) && sameTaskfileProject(o, t) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// sameTaskfileProject reports whether o is a Taskfile task of the project of
+// t, which --parallel can run next to it.
+func sameTaskfileProject(o, t taskmeta.Task) bool {
+	return o.Backend == taskmeta.BackendTask && o.Script == "" && o.ProjectDir == t.ProjectDir
+}
+
+// parallelRun returns the tasks the --parallel flag in args runs next to t,
+// so they go through the same checks as t before the run starts.
+func (m TaskModel) parallelRun(t taskmeta.Task, args []string) []taskmeta.Task {
+	i := slices.Index(args, "--parallel")
+	if i < 0 {
+		return nil
+	}
+	var out []taskmeta.Task
+	for _, name := range args[i+1:] {
+		// The names end where the variables or CLI_ARGS start.
+		if name == "--" || strings.HasPrefix(name, "-") || strings.Contains(name, "=") {
+			break
+		}
+		for _, o := range m.originalTasks {
+			if o.Name == name && sameTaskfileProject(o, t) {
+				out = append(out, o)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// refuseParallel refuses a --parallel run when one of the other tasks may
+// not run from this session, comes from a remote Taskfile not trusted yet, or
+// has a prompt taskg would have to ask: the dialog only asks those for the
+// selected task.
+func (m *TaskModel) refuseParallel(others []taskmeta.Task) bool {
+	for _, o := range others {
+		switch {
+		case m.refusePolicy(o):
+			return true
+		case m.needsTrust(o) != "":
+			m.setError(fmt.Sprintf("Run %s on its own first to trust its remote Taskfile", o.Name))
+			return true
+		case m.asksViaTaskg(o, m.runInline, true):
+			m.setError(fmt.Sprintf("Run %s on its own: its prompt cannot be answered in a parallel run", o.Name))
+			return true
+		}
+	}
+	return false
+}
+
+// chosenRunFlags returns the toggled flags as arguments for running t.
+// --parallel is followed by the names of the other tasks, which Task then
+// runs together with t.
+func (m TaskModel) chosenRunFlags(t taskmeta.Task) []string {
+	var args []string
+	for _, f := range runFlags {
+		if !m.runOpts[f.flag] {
+			continue
+		}
+		if f.flag == "--parallel" {
+			others := m.parallelTasks(t)
+			if len(others) == 0 {
+				continue
+			}
+			args = append(args, f.flag)
+			for _, o := range others {
+				args = append(args, o.Name)
+			}
+			continue
+		}
+		args = append(args, f.flag)
+	}
+	return args
+}
+
+func (m *TaskModel) handleRunOptionsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(runFlags)
+	switch key := msg.String(); key {
+	case "esc", "q":
+		m.runOptsMode = false
+	case "up", "k":
+		m.runOptsSelected = (m.runOptsSelected + n - 1) % n
+	case "down", "j":
+		m.runOptsSelected = (m.runOptsSelected + 1) % n
+	case " ", "x":
+		f := runFlags[m.runOptsSelected].flag
+		m.runOpts[f] = !m.runOpts[f]
+	case "enter", "ctrl+o":
+		m.runOptsMode = false
+		t := m.filteredTasks[m.selected]
+		flags := m.chosenRunFlags(t)
+		if slices.Contains(flags, "--parallel") {
+			m.marked = make(map[string]bool)
+		}
+		return m, m.markForExecutionWith(key == "ctrl+o", flags)
+	default:
+		for i, f := range runFlags {
+			if key == f.key {
+				m.runOptsSelected = i
+				m.runOpts[f.flag] = !m.runOpts[f.flag]
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m TaskModel) renderRunOptions() string {
+	task := m.filteredTasks[m.selected]
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render("Run " + task.Name + " with")
+	sections := []string{header, ""}
+	others := m.parallelTasks(task)
+	for i, f := range runFlags {
+		box := "[ ]"
+		if m.runOpts[f.flag] {
+			box = "[x]"
+		}
+		help := f.help
+		if f.flag == "--parallel" {
+			if len(others) == 0 {
+				help = "mark other tasks with Space to run them at once"
+			} else {
+				help = "also run " + taskNames(others) + ", all at once"
+			}
+		}
+		line := fmt.Sprintf("%s %-10s  %s", box, f.flag, m.theme.Help.Render(help))
+		prefix := "  "
+		if i == m.runOptsSelected {
+			prefix = m.theme.Highlight.Render("▶ ")
+		}
+		sections = append(sections, prefix+line)
+	}
+	preview := taskmeta.CommandLine(task.Invocation(m.chosenRunFlags(task)))
+	sections = append(sections, "", m.theme.Help.Render("$ "+preview))
+	helperText := fmt.Sprintf("%s toggle  %s run  %s run here  %s cancel",
+		m.theme.Highlight.Render("SPACE"),
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("^O"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+	return m.renderDialog(sections)
+}
+
+// taskNames lists the names of tasks, comma-separated.
+func taskNames(tasks []taskmeta.Task) string {
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
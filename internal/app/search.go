@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"taskg/internal/config"
+)
+
+// reservedKeys are the letters that stay keys rather than start a search
+// with type_to_search set to config.TypeToSearchLetters: q quits, j and k move
+// and r refreshes. By default every letter searches, and Ctrl+Q, the arrows
+// and Ctrl+R do those instead. Later single-letter keys, such as m for the context menu and x
+// to expand the commands, only work with type_to_search off; Alt+M and Alt+X
+// work in every mode, so typing "make" or "xcode" still searches.
+const reservedKeys = "qjkr"
+
+// exactPrefix starts a query matching task names exactly, e.g. "!build".
+const exactPrefix = "!"
+
+// exactQuery returns the task name of a "!name" query.
+func exactQuery(q string) (name string, ok bool) {
+	if !strings.HasPrefix(q, exactPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(q, exactPrefix)), true
+}
+
+// exactMatch returns the index of the filtered task named exactly like the
+// query (with or without the "!" prefix), or -1. Enter runs that task rather
+// than whichever one happens to be selected, as dmenu does.
+func (m TaskModel) exactMatch() int {
+	name := strings.TrimSpace(m.searchQuery)
+	if n, ok := exactQuery(name); ok {
+		name = n
+	}
+	for i, t := range m.filteredTasks {
+		if name != "" && t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleSearchScope switches the search between the active tab and all tasks.
+func (m *TaskModel) toggleSearchScope() {
+	m.searchGlobal = !m.searchGlobal
+	m.updateFilter()
+	if m.searchGlobal {
+		m.setStatus("Search scope: all tabs")
+	} else {
+		m.setStatus("Search scope: active tab")
+	}
+}
+
+// searchScope names what the search runs over, shown in the search box.
+func (m TaskModel) searchScope() string {
+	if m.searchGlobal || len(m.tabs) <= 1 {
+		return "all tabs"
+	}
+	if m.activeTab == "main" {
+		return "Main"
+	}
+	return m.titleCase(m.activeTab)
+}
+
+// searchBoxView renders the search input with its scope in the prompt.
+func (m TaskModel) searchBoxView() string {
+	si := m.searchInput
+	si.Prompt = fmt.Sprintf("🔍 [%s] ", m.searchScope())
+	return si.View()
+}
+
+// startSearch opens the search box with query typed in, as typing or pasting
+// in the list does.
+func (m *TaskModel) startSearch(query string) {
+	m.searchMode = true
+	m.searchInput.Focus()
+	m.searchInput.SetValue(strings.TrimSpace(query))
+	m.searchQuery = m.searchInput.Value()
+	m.updateFilter()
+}
+
+// SetTypeToSearch sets what typing a letter in the list does, one of the
+// config.TypeToSearch modes.
+func (m *TaskModel) SetTypeToSearch(mode string) { m.typeToSearch = mode }
+
+// typeStartsSearch reports whether typing r in the list starts a search. /
+// opens an empty search box instead, and ' and ; go-to mode.
+func (m TaskModel) typeStartsSearch(r rune) bool {
+	if strings.ContainsRune("/';", r) || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+		return false
+	}
+	switch m.typeToSearch {
+	case config.TypeToSearchOff:
+		return false
+	case config.TypeToSearchLetters:
+		return !strings.ContainsRune(reservedKeys, r)
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// filterDebounce delays re-filtering while typing in large task lists.
+	filterDebounce = 40 * time.Millisecond
+	// debounceMinTasks is the list size from which filtering is debounced;
+	// smaller lists filter on every keystroke.
+	debounceMinTasks = 500
+)
+
+// searchIndex is a trigram index over the searchable text of every task, so
+// a substring query only verifies the few tasks that contain all of its
+// trigrams instead of scanning thousands.
+type searchIndex struct {
+	keys  []string           // task keys; the position is the document id
+	hay   []string           // lowercased name, desc, cmds and tags
+	grams map[string][]int32 // trigram -> ascending document ids
+}
+
+// searchText is what a query is matched against. Tasks of a subproject or
+// registered project can be found as "project: name" too.
+func searchText(t taskmeta.Task) string {
+	name := t.Name
+	if t.Project != "" {
+		name = t.Project + ": " + t.Name
+	}
+	return strings.ToLower(name + " " + t.Desc + " " + strings.Join(t.Cmds, " ") + " " + strings.Join(t.Tags, " "))
+}
+
+func buildSearchIndex(tasks []taskmeta.Task) *searchIndex {
+	ix := &searchIndex{
+		keys:  make([]string, len(tasks)),
+		hay:   make([]string, len(tasks)),
+		grams: make(map[string][]int32),
+	}
+	for i, t := range tasks {
+		ix.keys[i] = taskKey(t)
+		ix.hay[i] = searchText(t)
+		seen := make(map[string]bool)
+		for j := 0; j+3 <= len(ix.hay[i]); j++ {
+			g := ix.hay[i][j : j+3]
+			if !seen[g] {
+				seen[g] = true
+				ix.grams[g] = append(ix.grams[g], int32(i))
+			}
+		}
+	}
+	return ix
+}
+
+// match returns the keys of the tasks whose text contains q (lowercased).
+func (ix *searchIndex) match(q string) map[string]bool {
+	out := make(map[string]bool)
+	var candidates []int32
+	if len(q) < 3 {
+		// Too short for trigrams: scan the precomputed texts.
+		candidates = make([]int32, len(ix.hay))
+		for i := range candidates {
+			candidates[i] = int32(i)
+		}
+	} else {
+		for j := 0; j+3 <= len(q); j++ {
+			postings := ix.grams[q[j:j+3]]
+			if j == 0 {
+				candidates = postings
+			} else {
+				candidates = intersect(candidates, postings)
+			}
+			if len(candidates) == 0 {
+				return out
+			}
+		}
+	}
+	// Trigrams may match out of order, so confirm the substring.
+	for _, id := range candidates {
+		if strings.Contains(ix.hay[id], q) {
+			out[ix.keys[id]] = true
+		}
+	}
+	return out
+}
+
+// intersect merges two ascending id lists.
+func intersect(a, b []int32) []int32 {
+	var out []int32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// searchIdx returns the index over all tasks, building it after a refresh.
+func (m *TaskModel) searchIdx() *searchIndex {
+	if m.index == nil {
+		m.index = buildSearchIndex(m.tasks)
+	}
+	return m.index
+}
+
+// filterMsg fires once typing paused for filterDebounce.
+type filterMsg struct{ seq int }
+
+// filterSoon re-filters for the current query: right away for small lists,
+// after a short pause in typing for large ones.
+func (m *TaskModel) filterSoon() tea.Cmd {
+	if len(m.tasks) < debounceMinTasks {
+		m.updateFilter()
+		return nil
+	}
+	m.filterSeq++
+	seq := m.filterSeq
+	return tea.Tick(filterDebounce, func(time.Time) tea.Msg { return filterMsg{seq} })
+}
@@ -0,0 +1,33 @@
+package app
+
+import "taskg/internal/taskmeta"
+
+// restoreSelection reselects the task that was selected in prev after the
+// list was refiltered, refreshed or regrouped, instead of whatever landed at
+// the same index. When that task is gone, the nearest former neighbour still
+// listed is selected (looking below first); failing that, the index is
+// clamped to the new list.
+func (m *TaskModel) restoreSelection(prev []taskmeta.Task, prevSelected int) {
+	if prevSelected >= 0 && prevSelected < len(prev) && len(m.filteredTasks) > 0 {
+		pos := make(map[string]int, len(m.filteredTasks))
+		for i, t := range m.filteredTasks {
+			if _, dup := pos[taskKey(t)]; !dup {
+				pos[taskKey(t)] = i
+			}
+		}
+		for d := 0; d < len(prev); d++ {
+			for _, j := range []int{prevSelected + d, prevSelected - d} {
+				if j < 0 || j >= len(prev) {
+					continue
+				}
+				if i, ok := pos[taskKey(prev[j])]; ok {
+					m.selected = i
+					return
+				}
+			}
+		}
+	}
+	if m.selected >= len(m.filteredTasks) {
+		m.selected = max(0, len(m.filteredTasks)-1)
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+
+	"taskg/internal/state"
+)
+
+// List densities saved in the UI state.
+const (
+	densityNormal  = "normal"
+	densityCompact = "compact" // without command lines, see cmdpreview.go
+)
+
+// loadUIState picks up the tab, sort mode, selection and view saved for the
+// current project. The sort mode applies right away; the rest waits for the
+// tasks to load (see restoreUIState), after the config file applied.
+func (m *TaskModel) loadUIState() {
+	m.pendingUI = nil
+	if m.state == nil || m.state.UI == nil {
+		return
+	}
+	ui := *m.state.UI
+	switch ui.SortMode {
+	case "file", "alpha", "smart":
+		m.sortMode = ui.SortMode
+	}
+	m.pendingUI = &ui
+}
+
+// restoreUIState reopens the saved tab, scrolls back and reselects the saved
+// task, once, after the first non-empty task list of the project arrived.
+// Anything that no longer exists is skipped. The saved density and detail
+// pane win over the config file.
+func (m *TaskModel) restoreUIState() {
+	ui := m.pendingUI
+	if ui == nil || len(m.originalTasks) == 0 {
+		return
+	}
+	m.pendingUI = nil
+	switch ui.Density {
+	case densityNormal, densityCompact:
+		m.SetHideCommands(ui.Density == densityCompact)
+		m.showDetail = ui.ShowDetail
+	}
+	if ui.ActiveTab == "" {
+		ui.ActiveTab = m.tabOf(ui.Selected)
+	}
+	if _, ok := m.tabTasks[ui.ActiveTab]; ok && ui.ActiveTab != m.activeTab {
+		m.activeTab = ui.ActiveTab
+		m.selected = 0
+		m.updateFilter()
+	}
+	for i, t := range m.filteredTasks {
+		if taskKey(t) == ui.Selected {
+			m.selected = i
+			break
+		}
+	}
+	cols := m.gridColumns()
+	m.listOffset = max(0, ui.ListOffset) / cols * cols
+	m.ensureSelectionVisible()
+}
+
+// tabOf returns the first tab listing the task with key, or "".
+func (m TaskModel) tabOf(key string) string {
+	for _, tab := range m.tabs {
+		for _, t := range m.tabTasks[tab] {
+			if taskKey(t) == key {
+				return tab
+			}
+		}
+	}
+	return ""
+}
+
+// saveUIState records the current tab, sort mode, selection and view in the
+// project state. It is called on quit and before switching projects.
+func (m *TaskModel) saveUIState() {
+	if m.projectRoot == "" || m.state == nil || len(m.originalTasks) == 0 {
+		return
+	}
+	ui := &state.UIState{ActiveTab: m.activeTab, SortMode: m.sortMode, ListOffset: m.listOffset, Density: densityNormal, ShowDetail: m.showDetail}
+	if m.hideCmds {
+		ui.Density = densityCompact
+	}
+	if t, ok := m.selectedTask(); ok {
+		ui.Selected = taskKey(t)
+	}
+	m.state.UI = ui
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save state: %v", err))
+	}
+}
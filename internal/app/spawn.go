@@ -0,0 +1,176 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// With a run_in target, tasks run in a new pane, window or tab of the
+// terminal multiplexer or emulator while taskg stays open in its own. Enter
+// uses the configured target; Alt+Enter opens a single run in the target, or
+// in the first multiplexer or terminal detected when none is configured.
+
+// spawnPreset is a run_in preset.
+type spawnPreset struct {
+	env      string // set when taskg runs inside the multiplexer or terminal
+	template string // command template, see spawnCommand
+	noun     string // what the task opens in, for the status line
+}
+
+// spawnPresets maps the run_in presets to their spawn commands.
+var spawnPresets = map[string]spawnPreset{
+	config.RunInTmuxSplit:  {"TMUX", "tmux split-window -c {dir} {cmd}", "tmux pane"},
+	config.RunInTmuxWindow: {"TMUX", "tmux new-window -c {dir} {cmd}", "tmux window"},
+	config.RunInZellij:     {"ZELLIJ", "zellij run --cwd {dir} -- {cmd}", "zellij pane"},
+	config.RunInWezterm:    {"WEZTERM_PANE", "wezterm cli spawn --cwd {dir} -- {cmd}", "wezterm tab"},
+	config.RunInKitty:      {"KITTY_WINDOW_ID", "kitty @ launch --type=tab --cwd {dir} {cmd}", "kitty tab"},
+}
+
+// detectOrder is the order Alt+Enter tries the presets in without run_in.
+var detectOrder = []string{config.RunInTmuxSplit, config.RunInZellij, config.RunInWezterm, config.RunInKitty}
+
+// spawnedMsg reports that the external target was asked to run a task.
+type spawnedMsg struct {
+	task   string
+	target string
+	err    error
+}
+
+// SetRunIn sets where Enter runs tasks: one of config.RunInTargets, a
+// command template with {cmd}, or "" to run them after taskg exits. Presets
+// only apply inside their multiplexer or terminal.
+func (m *TaskModel) SetRunIn(target string) { m.runIn = target }
+
+// available reports whether target can spawn from here: presets need their
+// multiplexer or terminal, templates are taken as they are.
+func available(target string) bool {
+	p, ok := spawnPresets[target]
+	return !ok || os.Getenv(p.env) != ""
+}
+
+// detectTarget returns the first preset taskg runs inside of, or "".
+func detectTarget() string {
+	for _, t := range detectOrder {
+		if available(t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// spawnTarget returns the target the next run opens in, or "" when it runs
+// the usual way.
+func (m TaskModel) spawnTarget(requested bool) string {
+	switch {
+	case m.runIn != "":
+		if available(m.runIn) {
+			return m.runIn
+		}
+	case requested:
+		return detectTarget()
+	}
+	return ""
+}
+
+// markForSpawn runs the selected task in the run_in target, or the detected
+// one.
+func (m *TaskModel) markForSpawn() tea.Cmd {
+	if m.spawnTarget(true) == "" {
+		if m.runIn != "" {
+			m.setWarning(fmt.Sprintf("run_in %s does not apply outside %s", m.runIn, strings.Fields(spawnPresets[m.runIn].noun)[0]))
+		} else {
+			m.setWarning("Alt+Enter opens tasks in a new tmux, zellij, wezterm or kitty pane, but none was detected")
+		}
+		return nil
+	}
+	m.spawnRequested = true
+	cmd := m.markForExecution(false)
+	m.spawnRequested = false
+	return cmd
+}
+
+// spawn opens task in the run target. The pane stays open after the task
+// exits so its output and exit status can be read.
+func (m *TaskModel) spawn(task taskmeta.Task, args []string) tea.Cmd {
+	target := m.runSpawn
+	if !taskmeta.ShellAvailable() {
+		m.setError(fmt.Sprintf("Cannot open %s in a %s: %v", task.Name, spawnNoun(target), taskmeta.ErrNoShell))
+		return nil
+	}
+	bin, argv := task.RunInvocation(args)
+	env := append(m.envFor(task), m.experimentEnv(task)...)
+	if m.runContainer {
+		bin, argv = m.containerInvocation(task, env, true, bin, argv)
+	}
+	line := taskmeta.CommandLine(bin, argv)
+	if len(env) > 0 {
+		line = taskmeta.CommandLine("env", env) + " " + line
+	}
+	script := line + `; status=$?; printf '\n[exit %s] Press Enter to close' "$status"; read _`
+	cmd := spawnCommand(target, task.WorkDir(m.projectRoot), script)
+	m.recordRuns(task)
+	name := task.Name
+	return func() tea.Msg {
+		out, err := cmd.CombinedOutput()
+		if msg := strings.TrimSpace(string(out)); err != nil && msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return spawnedMsg{task: name, target: target, err: err}
+	}
+}
+
+// spawnCommand builds the command running script with sh in dir for target,
+// a preset or a template. Templates are split into words at spaces and {dir}
+// is replaced in each word. A {cmd} word becomes `sh -c script` as separate
+// arguments, a {cmd} inside a longer word the quoted command line. Without
+// any {cmd} the command is appended.
+func spawnCommand(target, dir, script string) *exec.Cmd {
+	template := target
+	if p, ok := spawnPresets[target]; ok {
+		template = p.template
+	}
+	run := []string{"sh", "-c", script}
+	var words []string
+	found := false
+	for _, w := range strings.Fields(template) {
+		w = strings.ReplaceAll(w, "{dir}", dir)
+		switch {
+		case w == "{cmd}":
+			words = append(words, run...)
+			found = true
+		case strings.Contains(w, "{cmd}"):
+			words = append(words, strings.ReplaceAll(w, "{cmd}", taskmeta.CommandLine(run[0], run[1:])))
+			found = true
+		default:
+			words = append(words, w)
+		}
+	}
+	if !found {
+		words = append(words, run...)
+	}
+	return exec.Command(words[0], words[1:]...)
+}
+
+// spawnNoun describes what target opens tasks in.
+func spawnNoun(target string) string {
+	if p, ok := spawnPresets[target]; ok {
+		return p.noun
+	}
+	return strings.Fields(target)[0] + " pane"
+}
+
+// handleSpawned reports the outcome of spawn.
+func (m *TaskModel) handleSpawned(msg spawnedMsg) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Could not open %s in a new %s: %v", msg.task, spawnNoun(msg.target), msg.err))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Started %s in a new %s", msg.task, spawnNoun(msg.target)))
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// upToDate tracks the `task --status` result for a task.
+type upToDate int
+
+const (
+	statusChecking upToDate = iota + 1
+	statusFresh             // sources/status say the task is up to date
+	statusStale             // the task needs to run
+	statusUnknown           // the check itself failed
+)
+
+// statusResultMsg delivers the result of an asynchronous status check.
+type statusResultMsg struct {
+	key      string
+	upToDate bool
+	err      error
+}
+
+// statusCheckCmds starts `task --status` checks for visible tasks that declare
+// sources/status and have not been checked yet. Checks run in the background
+// so the list never waits on them.
+func (m *TaskModel) statusCheckCmds() tea.Cmd {
+	if m.projectRoot == "" || m.browsing() || len(m.filteredTasks) == 0 {
+		return nil
+	}
+	var cmds []tea.Cmd
+	end := min(len(m.filteredTasks), m.listOffset+m.visibleTaskCount())
+	for i := m.listOffset; i < end; i++ {
+		t := m.filteredTasks[i]
+		if !t.HasStatus || t.Backend != taskmeta.BackendTask || t.Script != "" {
+			continue
+		}
+		key := taskKey(t)
+		if _, ok := m.taskStatus[key]; ok {
+			continue
+		}
+		m.taskStatus[key] = statusChecking
+		root, name := t.WorkDir(m.projectRoot), t.Name
+		cmds = append(cmds, func() tea.Msg {
+			ok, err := taskmeta.IsUpToDate(root, name)
+			return statusResultMsg{key: key, upToDate: ok, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleStatusResult records a status check. A check that failed, rather
+// than found the task needing to run, is shown as an error.
+func (m *TaskModel) handleStatusResult(msg statusResultMsg) {
+	switch {
+	case msg.err != nil:
+		m.taskStatus[msg.key] = statusUnknown
+		m.setError("Up-to-date check failed: " + msg.err.Error())
+	case msg.upToDate:
+		m.taskStatus[msg.key] = statusFresh
+	default:
+		m.taskStatus[msg.key] = statusStale
+	}
+}
+
+// statusBadge renders the up-to-date badge for t, or "" when there is none.
+func (m TaskModel) statusBadge(t taskmeta.Task) string {
+	switch m.taskStatus[taskKey(t)] {
+	case statusChecking:
+		return m.theme.Help.Render("…")
+	case statusFresh:
+		return m.theme.Status.Render("✓ up-to-date")
+	case statusStale:
+		return m.theme.Highlight.Render("● needs run")
+	case statusUnknown:
+		return m.theme.Error.Render("✗ status failed")
+	}
+	return ""
+}
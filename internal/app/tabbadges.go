@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"taskg/internal/taskmeta"
+)
+
+// Tab labels carry the number of tasks in the tab, e.g. "Docker 12", and a
+// red dot while a run of the output pane's current group that started from
+// one of its tasks has failed. hide_tab_counts: in the config file drops the
+// counts.
+
+// SetHideTabCounts sets whether tab labels leave out their task counts.
+func (m *TaskModel) SetHideTabCounts(hide bool) { m.hideTabCounts = hide }
+
+// tabBadges renders what follows the title of tab in the tab strip.
+func (m TaskModel) tabBadges(tab string) string {
+	var out string
+	if !m.hideTabCounts {
+		out += " " + m.theme.Help.Render(fmt.Sprint(len(m.tabTasks[tab])))
+	}
+	if m.tabFailed(tab) {
+		out += " " + m.theme.Error.Render("●")
+	}
+	return out
+}
+
+// tabFailed reports whether a finished in-TUI run of a task in tab failed.
+func (m TaskModel) tabFailed(tab string) bool {
+	for _, j := range m.jobs {
+		if !j.finished || j.canceled || (j.exitCode == 0 && j.err == nil) {
+			continue
+		}
+		key := taskKey(j.task)
+		if slices.ContainsFunc(m.tabTasks[tab], func(t taskmeta.Task) bool { return taskKey(t) == key }) {
+			return true
+		}
+	}
+	return false
+}
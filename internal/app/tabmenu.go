@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Alt+T, or a click on the ▶ arrow of an overflowing tab strip, opens a menu
+// listing every tab with its number of tasks, so far-away tabs are one
+// selection away instead of many arrow presses.
+
+// tabTitle is how a tab is labelled. Pinned tabs are flagged.
+func (m *TaskModel) tabTitle(tab string) string {
+	title := m.titleCase(tab)
+	if tab == "main" {
+		title = "Main"
+	}
+	if m.tabPinned(tab) {
+		title = "⚑ " + title
+	}
+	return title
+}
+
+// tabsOverflow reports whether the tab strip does not fit on one line.
+func (m *TaskModel) tabsOverflow() bool {
+	if len(m.tabs) <= 1 {
+		return false
+	}
+	if m.tabOffset > 0 {
+		return true
+	}
+	width := 0
+	for _, tab := range m.tabs {
+		width += lipgloss.Width(m.renderTab(tab))
+	}
+	return width > tabStripWidth(m.listWidth())
+}
+
+// onTabOverflowArrow reports whether column x of the tab strip is on its ▶
+// arrow, which renderTabs puts right after the space the tabs may use.
+func (m *TaskModel) onTabOverflowArrow(x int) bool {
+	if !m.tabsOverflow() {
+		return false
+	}
+	appFrameW, _ := m.theme.AppContainer.GetFrameSize()
+	arrow := appFrameW/2 + m.headerIndent + tabStripWidth(m.listWidth()) - 1
+	return x >= arrow-1 && x <= arrow+1
+}
+
+// openTabMenu lists all tabs with the active one selected.
+func (m *TaskModel) openTabMenu() {
+	if len(m.tabs) <= 1 {
+		m.setStatus("There is only one tab")
+		return
+	}
+	m.tabMenu = true
+	m.tabMenuSelected = 0
+	for i, tab := range m.tabs {
+		if tab == m.activeTab {
+			m.tabMenuSelected = i
+		}
+	}
+}
+
+func (m *TaskModel) handleTabMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "alt+t":
+		m.tabMenu = false
+	case "up", "k":
+		if m.tabMenuSelected > 0 {
+			m.tabMenuSelected--
+		}
+	case "down", "j":
+		if m.tabMenuSelected < len(m.tabs)-1 {
+			m.tabMenuSelected++
+		}
+	case "pgup":
+		m.tabMenuSelected = max(0, m.tabMenuSelected-m.tabMenuHeight())
+	case "pgdown":
+		m.tabMenuSelected = min(len(m.tabs)-1, m.tabMenuSelected+m.tabMenuHeight())
+	case "home":
+		m.tabMenuSelected = 0
+	case "end":
+		m.tabMenuSelected = len(m.tabs) - 1
+	case "enter":
+		m.tabMenu = false
+		if m.tabMenuSelected >= len(m.tabs) {
+			break
+		}
+		if m.tree {
+			m.selectSection(m.tabs[m.tabMenuSelected])
+			break
+		}
+		m.activeTab = m.tabs[m.tabMenuSelected]
+		m.ensureTabVisible(m.tabMenuSelected)
+		m.updateFilter()
+	}
+	return m, nil
+}
+
+// tabMenuHeight is the number of tabs the menu shows at once.
+func (m *TaskModel) tabMenuHeight() int {
+	if m.height <= 0 {
+		return 15
+	}
+	return max(3, m.height-12)
+}
+
+func (m TaskModel) renderTabMenu() string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.HighlightColor).
+		Render(fmt.Sprintf("Tabs (%d)", len(m.tabs)))
+	sections := []string{header, ""}
+
+	width := 0
+	for _, tab := range m.tabs {
+		width = max(width, lipgloss.Width(m.tabTitle(tab)))
+	}
+	height := m.tabMenuHeight()
+	first := max(0, min(m.tabMenuSelected-height/2, len(m.tabs)-height))
+	last := min(len(m.tabs), first+height)
+	if first > 0 {
+		sections = append(sections, m.theme.Help.Render(fmt.Sprintf("  ↑ %d more", first)))
+	}
+	for i := first; i < last; i++ {
+		tab := m.tabs[i]
+		title := m.tabTitle(tab)
+		name := title + strings.Repeat(" ", width-lipgloss.Width(title))
+		count := m.theme.Help.Render(fmt.Sprintf("%4d", len(m.tabTasks[tab])))
+		if m.tabFailed(tab) {
+			count += " " + m.theme.Error.Render("●")
+		}
+		if tab == m.activeTab {
+			count += m.theme.Help.Render(" (current)")
+		}
+		line := "  " + name + "  " + count
+		if i == m.tabMenuSelected {
+			line = m.theme.Highlight.Render("▶ "+name) + "  " + count
+		}
+		sections = append(sections, line)
+	}
+	if last < len(m.tabs) {
+		sections = append(sections, m.theme.Help.Render(fmt.Sprintf("  ↓ %d more", len(m.tabs)-last)))
+	}
+
+	helperText := fmt.Sprintf("%s open  %s move  %s cancel",
+		m.theme.Highlight.Render("ENTER"),
+		m.theme.Highlight.Render("↑↓"),
+		m.theme.Highlight.Render("ESC"))
+	sections = append(sections, "", m.theme.Help.Copy().Italic(true).Render(helperText))
+
+	dialogBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.HighlightColor).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBox,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
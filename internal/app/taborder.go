@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Tabs listed under pinned_tabs: in the config file, or pinned with
+// Ctrl+Shift+↑, come first in the tab strip. Ctrl+Shift+←/→ move the active
+// tab among the pinned or the other tabs. Pins and the order are kept per
+// project in .taskg/state.json; tabs that do not exist are skipped.
+
+// SetPinnedTabs sets the tabs pinned in every project.
+func (m *TaskModel) SetPinnedTabs(tabs []string) {
+	m.configPins = tabs
+	m.buildTabs()
+	m.updateFilter()
+}
+
+// pinnedTabs returns the pinned tabs in order: the project's own pins, then
+// those of the config file.
+func (m TaskModel) pinnedTabs() []string {
+	pins := slices.Clone(m.state.PinnedTabs)
+	for _, tab := range m.configPins {
+		if !slices.Contains(pins, tab) {
+			pins = append(pins, tab)
+		}
+	}
+	return pins
+}
+
+func (m TaskModel) tabPinned(tab string) bool {
+	return slices.Contains(m.state.PinnedTabs, tab) || slices.Contains(m.configPins, tab)
+}
+
+// arrangeTabs orders tabs, given in their default order, by the pins and
+// the saved order. Tabs the saved order does not know keep their place
+// after the ones it does.
+func (m TaskModel) arrangeTabs(tabs []string) []string {
+	var pinned, rest []string
+	for _, tab := range m.pinnedTabs() {
+		if slices.Contains(tabs, tab) {
+			pinned = append(pinned, tab)
+		}
+	}
+	for _, tab := range tabs {
+		if !slices.Contains(pinned, tab) {
+			rest = append(rest, tab)
+		}
+	}
+	rank := func(tab string) int {
+		if i := slices.Index(m.state.TabOrder, tab); i >= 0 {
+			return i
+		}
+		return len(m.state.TabOrder)
+	}
+	sort.SliceStable(rest, func(i, j int) bool { return rank(rest[i]) < rank(rest[j]) })
+	return append(pinned, rest...)
+}
+
+// moveTab swaps the active tab with its neighbour delta tabs away, unless
+// that would move it across the pinned tabs' boundary.
+func (m *TaskModel) moveTab(delta int) {
+	i := slices.Index(m.tabs, m.activeTab)
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(m.tabs) || m.tabPinned(m.tabs[i]) != m.tabPinned(m.tabs[j]) {
+		return
+	}
+	tabs := slices.Clone(m.tabs)
+	tabs[i], tabs[j] = tabs[j], tabs[i]
+	var pinned, rest []string
+	for _, tab := range tabs {
+		if m.tabPinned(tab) {
+			pinned = append(pinned, tab)
+		} else {
+			rest = append(rest, tab)
+		}
+	}
+	if m.tabPinned(m.activeTab) {
+		m.state.PinnedTabs = keepUnlisted(pinned, m.state.PinnedTabs)
+	} else {
+		m.state.TabOrder = keepUnlisted(rest, m.state.TabOrder)
+	}
+	m.saveTabs(j)
+}
+
+// togglePinTab pins the active tab to the front, or unpins it.
+func (m *TaskModel) togglePinTab() {
+	tab := m.activeTab
+	if len(m.tabs) <= 1 || tab == "" {
+		return
+	}
+	if slices.Contains(m.configPins, tab) {
+		m.setWarning(fmt.Sprintf("%s is pinned in the config file", m.tabTitle(tab)))
+		return
+	}
+	if i := slices.Index(m.state.PinnedTabs, tab); i >= 0 {
+		m.state.PinnedTabs = slices.Delete(m.state.PinnedTabs, i, i+1)
+		m.setStatus(fmt.Sprintf("Unpinned %s", m.tabTitle(tab)))
+	} else {
+		m.setStatus(fmt.Sprintf("Pinned %s", m.tabTitle(tab)))
+		m.state.PinnedTabs = append(m.state.PinnedTabs, tab)
+	}
+	m.saveTabs(-1)
+}
+
+// saveTabs rearranges the tabs after a change of the pins or the order and
+// saves them. index is where the active tab went, or -1 to look it up.
+func (m *TaskModel) saveTabs(index int) {
+	m.buildTabs()
+	if index < 0 {
+		index = slices.Index(m.tabs, m.activeTab)
+	}
+	m.ensureTabVisible(max(0, index))
+	if err := m.state.Save(); err != nil {
+		m.setWarning(fmt.Sprintf("Could not save the tab order: %v", err))
+	}
+}
+
+// keepUnlisted returns order followed by the entries of old it does not
+// list, so tabs of other groupings keep their saved place.
+func keepUnlisted(order, old []string) []string {
+	out := slices.Clone(order)
+	for _, tab := range old {
+		if !slices.Contains(out, tab) {
+			out = append(out, tab)
+		}
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// untaggedTab collects tasks without tags when tabs are grouped by tag.
+const untaggedTab = "untagged"
+
+// tagTabs returns the tabs t belongs to when grouping by tag. Tags are
+// lowercased so "Deploy" and "deploy" share a tab.
+func tagTabs(t taskmeta.Task) []string {
+	if len(t.Tags) == 0 {
+		return []string{untaggedTab}
+	}
+	tabs := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tabs = append(tabs, strings.ToLower(tag))
+	}
+	return tabs
+}
+
+// renderTags renders t's tags as "#tag" labels after its name.
+func (m TaskModel) renderTags(t taskmeta.Task) string {
+	if len(t.Tags) == 0 {
+		return ""
+	}
+	labels := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		labels[i] = "#" + tag
+	}
+	return m.theme.Accent.Render(strings.Join(labels, " "))
+}
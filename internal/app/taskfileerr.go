@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// taskfileEditedMsg reports that the editor opened on a Taskfile, e.g. at an
+// error, exited.
+type taskfileEditedMsg struct{ err error }
+
+// snippetContext is how many lines around a Taskfile error are shown.
+const snippetContext = 2
+
+// openTaskfileError suspends the TUI and opens the broken Taskfile in
+// $VISUAL/$EDITOR at the reported line. Tasks are rediscovered afterwards.
+func (m *TaskModel) openTaskfileError() tea.Cmd {
+	e := m.taskfileErr
+	if e == nil {
+		return nil
+	}
+	return tea.ExecProcess(editorCommandAt(e.File, e.Line, e.Column), func(err error) tea.Msg {
+		return taskfileEditedMsg{err: err}
+	})
+}
+
+// editorCommandAt is editorCommand placing the cursor at line and column for
+// the editors whose syntax for it is known.
+func editorCommandAt(path string, line, column int) *exec.Cmd {
+	cmd := editorCommand(path)
+	if line <= 0 {
+		return cmd
+	}
+	column = max(column, 1)
+	args := cmd.Args[1 : len(cmd.Args)-1]
+	switch strings.TrimSuffix(filepath.Base(cmd.Args[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor":
+		args = append(args, "--goto", fmt.Sprintf("%s:%d:%d", path, line, column))
+	case "subl", "zed", "hx", "helix":
+		args = append(args, fmt.Sprintf("%s:%d:%d", path, line, column))
+	case "notepad":
+		args = append(args, path)
+	default: // vi, vim, nvim, nano, emacs, micro, kak, ...
+		args = append(args, "+"+strconv.Itoa(line), path)
+	}
+	return exec.Command(cmd.Args[0], args...)
+}
+
+// taskfileErrorLines renders the lines around the reported position, the
+// offending one marked, for the empty state.
+func (m TaskModel) taskfileErrorLines() []string {
+	e := m.taskfileErr
+	if e == nil || e.Line <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(e.File)
+	if err != nil {
+		return nil
+	}
+	src := strings.Split(strings.ReplaceAll(string(data), "\t", "    "), "\n")
+	from, to := max(1, e.Line-snippetContext), min(len(src), e.Line+snippetContext)
+	width := len(strconv.Itoa(to))
+	var lines []string
+	for n := from; n <= to; n++ {
+		marker := "  "
+		if n == e.Line {
+			marker = "> "
+		}
+		line := fmt.Sprintf("%s%*d | %s", marker, width, n, src[n-1])
+		if n == e.Line {
+			lines = append(lines, m.theme.Error.Render(line))
+			if e.Column > 0 {
+				lines = append(lines, m.theme.Error.Render(strings.Repeat(" ", 2+width+3+e.Column-1)+"^"))
+			}
+		} else {
+			lines = append(lines, m.theme.Help.Render(line))
+		}
+	}
+	return lines
+}
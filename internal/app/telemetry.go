@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/taskmeta"
+	"taskg/internal/telemetry"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// telemetryMsg reports the outcome of exporting a run's trace.
+type telemetryMsg struct{ err error }
+
+// SetTelemetry sets where in-TUI runs are exported as traces. Runs started
+// after the TUI exits are exported by main.
+func (m *TaskModel) SetTelemetry(t config.Telemetry) { m.telemetry = t }
+
+// stepLineRe matches the line Task prints before each command it runs,
+// e.g. "task: [build] go build ./...".
+var stepLineRe = regexp.MustCompile(`^task: \[([^\]]+)\] `)
+
+// noteStep records when line shows a task of j's run starting its first
+// command, for the child spans of its trace.
+func (m *TaskModel) noteStep(j *job, line string) {
+	if !telemetry.Enabled(m.telemetry) {
+		return
+	}
+	match := stepLineRe.FindStringSubmatch(ansi.Strip(line))
+	if match == nil {
+		return
+	}
+	if j.steps == nil {
+		j.steps = make(map[string]time.Time)
+	}
+	if _, seen := j.steps[match[1]]; !seen {
+		j.steps[match[1]] = time.Now()
+	}
+}
+
+// traceSteps returns the tasks of j's plan that were seen starting, each
+// lasting until the next one started or the run ended. Deps of one task run
+// in parallel, so this is only an approximation of their own durations.
+func (m TaskModel) traceSteps(j *job) []telemetry.Step {
+	if j.task.Backend != taskmeta.BackendTask {
+		return nil
+	}
+	var steps []telemetry.Step
+	added := map[string]bool{}
+	for _, s := range taskmeta.Plan(m.tasks, j.task) {
+		start, seen := j.steps[s.Name]
+		if !seen || s.Name == j.task.Name || added[s.Name] {
+			continue
+		}
+		added[s.Name] = true
+		steps = append(steps, telemetry.Step{Name: s.Name, Start: start})
+	}
+	sort.Slice(steps, func(a, b int) bool { return steps[a].Start.Before(steps[b].Start) })
+	for i := range steps {
+		steps[i].End = j.end
+		if i+1 < len(steps) {
+			steps[i].End = steps[i+1].Start
+		}
+		if root, ok := j.steps[j.task.Name]; ok && root.After(steps[i].Start) && root.Before(steps[i].End) {
+			// The task's own commands start once its deps are done.
+			steps[i].End = root
+		}
+	}
+	return steps
+}
+
+// exportTrace exports the finished job j as a trace. Cancelled runs are not
+// exported.
+func (m *TaskModel) exportTrace(j *job) tea.Cmd {
+	if j.canceled || !telemetry.Enabled(m.telemetry) {
+		return nil
+	}
+	dir := j.task.WorkDir(m.projectRoot)
+	r := telemetry.Run{
+		Task:     j.task.Name,
+		Args:     j.args,
+		Project:  filepath.Base(dir),
+		Dir:      dir,
+		Started:  j.start,
+		Duration: j.end.Sub(j.start),
+		ExitCode: j.exitCode,
+		Attempt:  j.attempt,
+		Steps:    m.traceSteps(j),
+	}
+	cfg := m.telemetry
+	return func() tea.Msg {
+		return telemetryMsg{err: telemetry.Export(context.Background(), cfg, r)}
+	}
+}
+
+func (m *TaskModel) handleTelemetry(msg telemetryMsg) {
+	if msg.err != nil {
+		m.setWarning(fmt.Sprintf("Telemetry export failed: %v", msg.err))
+	}
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Layout math is done in terminal cells, never in bytes or runes: CJK
+// characters and most emoji take two cells, and styled strings carry escape
+// sequences that take none.
+
+// truncateStringToWidth cuts s, which may be styled, to at most maxW cells,
+// ending it with an ellipsis when anything was cut. A wide character that
+// would straddle the limit is dropped whole.
+func truncateStringToWidth(s string, maxW int) string {
+	if maxW <= 0 {
+		return ""
+	}
+	return ansi.Truncate(s, maxW, "…")
+}
+
+// padRight pads s with spaces to width cells.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-ansi.StringWidth(s)))
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Parts of the screen that change by themselves, such as toasts that
+// expire and the elapsed time of runs, are redrawn by a tick that is only
+// scheduled while one of them is shown: an idle taskg is never woken up.
+// Reduced-motion mode (--reduced-motion, or reduced_motion: true in the
+// config file) also stills animations such as the discovery spinner.
+
+// tickMsg redraws the parts of the screen that change by themselves.
+type tickMsg time.Time
+
+const (
+	// liveTick is how often the clocks of runs and countdowns are redrawn.
+	liveTick = time.Second
+	// ageTick is how often the ages of last runs in the list are redrawn.
+	ageTick = time.Minute
+)
+
+// SetReducedMotion turns animations off.
+func (m *TaskModel) SetReducedMotion(enabled bool) {
+	m.reducedMotion = enabled
+}
+
+// nextTick returns how long after now the screen next changes by itself.
+// It reports false when nothing on screen changes until the next event.
+func (m TaskModel) nextTick(now time.Time) (time.Duration, bool) {
+	for _, j := range m.jobs {
+		if j.running || !j.retryAt.IsZero() {
+			return liveTick, true
+		}
+	}
+	if m.repeat.every > 0 {
+		return liveTick, true
+	}
+	var next time.Duration
+	ok := false
+	for _, t := range m.toasts {
+		d := t.expires.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		if !ok || d < next {
+			next, ok = d, true
+		}
+	}
+	if m.state != nil && len(m.state.Runs) > 0 && (!ok || ageTick < next) {
+		next, ok = ageTick, true
+	}
+	return next, ok
+}
+
+// tickCmd schedules the next tick, unless an earlier one is pending or
+// nothing needs one.
+func (m *TaskModel) tickCmd() tea.Cmd {
+	now := time.Now()
+	d, ok := m.nextTick(now)
+	if !ok {
+		return nil
+	}
+	at := now.Add(d)
+	if !m.tickAt.IsZero() && !at.Before(m.tickAt) {
+		return nil
+	}
+	m.tickAt = at
+	return tea.Tick(d, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// handleTick drops expired toasts. Update then schedules the next tick.
+func (m *TaskModel) handleTick(now time.Time) {
+	if !now.Before(m.tickAt) {
+		m.tickAt = time.Time{}
+	}
+	m.pruneToasts(now)
+}
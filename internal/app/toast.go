@@ -0,0 +1,121 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// toastLevel is the severity of a status toast.
+type toastLevel int
+
+const (
+	toastInfo toastLevel = iota
+	toastWarn
+	toastError
+)
+
+// maxToasts caps how many toasts are stacked above the footer.
+const maxToasts = 3
+
+// defaultDuration is how long a toast of the level stays up unless the
+// caller picks a duration: long enough to read an error before it goes.
+func (l toastLevel) defaultDuration() time.Duration {
+	switch l {
+	case toastError:
+		return 8 * time.Second
+	case toastWarn:
+		return 5 * time.Second
+	}
+	return 3 * time.Second
+}
+
+// toast is a transient status message.
+type toast struct {
+	text    string
+	level   toastLevel
+	expires time.Time
+}
+
+// setStatus shows an informational toast.
+func (m *TaskModel) setStatus(message string) {
+	m.pushToast(toastInfo, message, 0)
+}
+
+// setWarning shows a warning toast.
+func (m *TaskModel) setWarning(message string) {
+	m.pushToast(toastWarn, message, 0)
+}
+
+// setError shows an error toast.
+func (m *TaskModel) setError(message string) {
+	m.pushToast(toastError, message, 0)
+}
+
+// pushToast stacks a toast shown for d, or for its level's default when d is
+// zero. Repeating the newest message only extends it. When the stack is full
+// the least severe, oldest toast makes room, so a burst of info messages never
+// pushes out an error.
+func (m *TaskModel) pushToast(level toastLevel, text string, d time.Duration) {
+	if d <= 0 {
+		d = level.defaultDuration()
+	}
+	expires := time.Now().Add(d)
+	if n := len(m.toasts); n > 0 && m.toasts[n-1].text == text && m.toasts[n-1].level == level {
+		m.toasts[n-1].expires = expires
+		return
+	}
+	m.toasts = append(m.toasts, toast{text: text, level: level, expires: expires})
+	if len(m.toasts) > maxToasts {
+		drop := 0
+		for i, t := range m.toasts {
+			if t.level < m.toasts[drop].level {
+				drop = i
+			}
+		}
+		m.toasts = append(m.toasts[:drop], m.toasts[drop+1:]...)
+	}
+	m.ensureSelectionVisible()
+}
+
+// pruneToasts drops expired toasts. It runs on the tick so that the stack
+// height, which the list layout depends on, only changes inside Update.
+func (m *TaskModel) pruneToasts(now time.Time) {
+	kept := m.toasts[:0]
+	for _, t := range m.toasts {
+		if now.Before(t.expires) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) != len(m.toasts) {
+		m.toasts = kept
+		m.ensureSelectionVisible()
+	}
+}
+
+// toastHeight is the number of lines the status area takes; one is always
+// reserved so the layout does not jump for a single message.
+func (m TaskModel) toastHeight() int {
+	return max(1, len(m.toasts))
+}
+
+// renderToasts renders the stack, newest at the bottom next to the footer.
+func (m TaskModel) renderToasts(width int) string {
+	if len(m.toasts) == 0 {
+		return m.theme.Status.Copy().Width(width).Render("")
+	}
+	lines := make([]string, len(m.toasts))
+	for i, t := range m.toasts {
+		style := m.theme.Status
+		icon := ""
+		switch t.level {
+		case toastWarn:
+			style, icon = m.theme.Warning, "⚠ "
+		case toastError:
+			style, icon = m.theme.Error, "✗ "
+		}
+		lines[i] = style.Copy().Width(width).MaxHeight(1).Render(icon + strings.ReplaceAll(t.text, "\n", " "))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
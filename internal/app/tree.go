@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+
+	"taskg/internal/taskmeta"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The tree layout (tree: true in the config file, or the palette) lists
+// every task in one list instead of tabs. Each tab becomes a section header
+// that ←/→ or Enter collapse and expand, and Tab/Shift+Tab jump between
+// headers. Headers are list rows of their own, so the selection can rest on
+// a collapsed section; they stand in the list as tasks of sectionBackend.
+
+// sectionBackend marks the section header rows of the tree layout.
+const sectionBackend = "section"
+
+// SetTree selects the tree layout instead of tabs.
+func (m *TaskModel) SetTree(enabled bool) {
+	m.tree = enabled
+	m.updateFilter()
+}
+
+// toggleTree switches between the tree layout and tabs.
+func (m *TaskModel) toggleTree() {
+	m.SetTree(!m.tree)
+	if m.tree {
+		m.setStatus("Showing all tasks in sections")
+	} else {
+		m.setStatus("Showing tasks in tabs")
+	}
+}
+
+// showTabStrip reports whether the tabs are shown above the list.
+func (m TaskModel) showTabStrip() bool { return len(m.tabs) > 1 && !m.tree }
+
+func isSection(t taskmeta.Task) bool { return t.Backend == sectionBackend }
+
+// treeTasks returns the rows of the tree layout: a header per tab followed
+// by its tasks, unless it is collapsed. Tabs with no task left after the
+// never-run filter are left out.
+func (m TaskModel) treeTasks() []taskmeta.Task {
+	var rows []taskmeta.Task
+	for _, tab := range m.tabs {
+		tasks := m.keepNeverRun(m.tabTasks[tab])
+		if len(tasks) == 0 && m.neverRun {
+			continue
+		}
+		rows = append(rows, taskmeta.Task{Name: tab, Backend: sectionBackend})
+		if !m.collapsed[tab] {
+			rows = append(rows, tasks...)
+		}
+	}
+	return rows
+}
+
+// sectionAt returns the index of the header of the section row i is in.
+func (m TaskModel) sectionAt(i int) int {
+	for ; i > 0; i-- {
+		if isSection(m.filteredTasks[i]) {
+			break
+		}
+	}
+	return i
+}
+
+// sectionSelected reports whether the selection is on a section header.
+func (m TaskModel) sectionSelected() bool {
+	return m.selected < len(m.filteredTasks) && isSection(m.filteredTasks[m.selected])
+}
+
+// setCollapsed collapses or expands the section whose header is at index
+// i, keeping the header selected.
+func (m *TaskModel) setCollapsed(i int, collapsed bool) {
+	tab := m.filteredTasks[i].Name
+	if m.collapsed[tab] == collapsed {
+		return
+	}
+	m.collapsed[tab] = collapsed
+	m.selected = i
+	m.updateFilter()
+}
+
+// handleTreeKeys handles the keys the tree layout gives a meaning of its
+// own. It reports false for the keys the list handles as usual.
+func (m *TaskModel) handleTreeKeys(key string) bool {
+	if !m.tree || m.searchQuery != "" || len(m.filteredTasks) == 0 {
+		return false
+	}
+	header := m.sectionAt(m.selected)
+	switch key {
+	case "left":
+		m.setCollapsed(header, true)
+	case "right":
+		if m.sectionSelected() {
+			m.setCollapsed(header, false)
+		}
+	case "enter", " ":
+		if !m.sectionSelected() {
+			return false
+		}
+		m.setCollapsed(header, !m.collapsed[m.filteredTasks[header].Name])
+	case "tab", "shift+tab":
+		m.jumpSection(key == "tab")
+	case "ctrl+e", "ctrl+n", "ctrl+f", "f2", "alt+enter", "alt+c", "ctrl+o":
+		if !m.sectionSelected() || (key == "ctrl+o" && len(m.marked) > 0) {
+			return false
+		}
+		m.setStatus(fmt.Sprintf("%s is a section, select one of its tasks", m.tabTitle(m.filteredTasks[header].Name)))
+	default:
+		return false
+	}
+	m.ensureSelectionVisible()
+	return true
+}
+
+// jumpSection selects the next (or the previous) section header.
+func (m *TaskModel) jumpSection(forward bool) {
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for i := m.selected + step; i >= 0 && i < len(m.filteredTasks); i += step {
+		if isSection(m.filteredTasks[i]) {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// selectSection selects the header of the section of tab.
+func (m *TaskModel) selectSection(tab string) {
+	if m.searchQuery != "" {
+		m.searchQuery = ""
+		m.updateFilter()
+	}
+	for i, t := range m.filteredTasks {
+		if isSection(t) && t.Name == tab {
+			m.selected = i
+			m.ensureSelectionVisible()
+			return
+		}
+	}
+}
+
+// renderSection renders the header row of a section.
+func (m TaskModel) renderSection(tab string, selected bool, width int) string {
+	arrow := "▾"
+	if m.collapsed[tab] {
+		arrow = "▸"
+	}
+	prefix := "  "
+	title := m.theme.Accent.Copy().Bold(true).Render(arrow + " " + m.tabTitle(tab))
+	if selected {
+		prefix = m.theme.Highlight.Render(m.selectionBar()) + " "
+		title = m.theme.Highlight.Render(arrow + " " + m.tabTitle(tab))
+	}
+	return ansi.Truncate(prefix+title+m.tabBadges(tab), width, "…")
+}
+
+// listRow renders row i of the task list.
+func (m TaskModel) listRow(i, width int) string {
+	t := m.filteredTasks[i]
+	if isSection(t) {
+		return m.renderSection(t.Name, i == m.selected, width)
+	}
+	return m.cachedRow(t, i == m.selected, m.hotkeyFor(i), width)
+}
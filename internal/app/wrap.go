@@ -0,0 +1,134 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+
+	"taskg/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Long descriptions wrap inside the task box, continuation lines indented
+// under the task name, up to desc_lines lines (config.DefaultDescLines by
+// default) and are cut short after that. Command lines never wrap. Rows are
+// thus not all the same height anymore, so the list measures the rows it
+// shows instead of dividing its height by itemHeight; only the one-line
+// boxes of the grid still share itemHeight.
+
+// SetDescLines sets how many lines the name and description of a task wrap
+// to at most; 0 stands for config.DefaultDescLines.
+func (m *TaskModel) SetDescLines(n int) {
+	m.descLines = n
+	m.invalidateRows()
+}
+
+// maxDescLines is how many lines the task text of a row may take. Grid cells
+// keep to one line each so the boxes of a row line up.
+func (m TaskModel) maxDescLines() int {
+	switch {
+	case m.gridColumns() > 1:
+		return 1
+	case m.descLines <= 0:
+		return config.DefaultDescLines
+	}
+	return m.descLines
+}
+
+// wrapTaskText wraps the styled task text to width, in at most maxDescLines
+// lines, the last of which ends with … when text does not fit.
+func (m TaskModel) wrapTaskText(text string, width int) []string {
+	if width < 1 || ansi.StringWidth(text) <= width {
+		return []string{text}
+	}
+	n := m.maxDescLines()
+	if n <= 1 {
+		return []string{ansi.Truncate(text, width, "…")}
+	}
+	lines := strings.Split(ansi.Wrap(text, width, ""), "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+		lines[n-1] = ansi.Truncate(lines[n-1], width-1, "") + "…"
+	}
+	return carryStyles(lines)
+}
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// carryStyles reopens on each line the styles left open at the end of the
+// line before, and closes them at its end, so a styled span split by
+// wrapping keeps its style and does not bleed into the box border.
+func carryStyles(lines []string) []string {
+	open := ""
+	for i, l := range lines {
+		l = open + l
+		for _, seq := range sgrPattern.FindAllString(l, -1) {
+			if seq == "\x1b[0m" || seq == "\x1b[m" {
+				open = ""
+			} else {
+				open += seq
+			}
+		}
+		if open != "" {
+			l += "\x1b[0m"
+		}
+		lines[i] = l
+	}
+	return lines
+}
+
+// listLines is the number of screen lines the rows of the list may take.
+func (m *TaskModel) listLines() int {
+	avail := m.height
+	if avail <= 0 {
+		avail = 24
+	}
+	inner := avail - m.theme.AppContainer.GetVerticalFrameSize()
+	overhead := m.headerRows() + m.tabsRows() + m.searchRows() + m.toastHeight() + m.footerRows()
+	if m.showDetail {
+		overhead += detailHeight
+	}
+	return inner - overhead
+}
+
+// listRows is the number of rows of the list; a grid row holds several tasks.
+func (m TaskModel) listRows() int {
+	cols := m.gridColumns()
+	return (len(m.filteredTasks) + cols - 1) / cols
+}
+
+// rowHeight is the height of row r of the list. It is measured without the
+// hotkey badge, which is as wide as the blank taking its place but depends
+// on how many rows fit.
+func (m TaskModel) rowHeight(r int) int {
+	if m.gridColumns() > 1 || r >= len(m.filteredTasks) {
+		return max(1, m.itemHeight)
+	}
+	t := m.filteredTasks[r]
+	if isSection(t) {
+		return 1
+	}
+	return lipgloss.Height(m.cachedRow(t, r == m.selected, 0, m.listWidth()))
+}
+
+// rowsFit reports whether rows first to last all fit in the list at once.
+func (m *TaskModel) rowsFit(first, last int) bool {
+	lines := m.listLines()
+	for r := first; r <= last; r++ {
+		lines -= m.rowHeight(r)
+	}
+	return lines >= 0
+}
+
+// maxListRow is the first row of the list when it is scrolled to the end.
+func (m *TaskModel) maxListRow() int {
+	rows := m.listRows()
+	lines := m.listLines()
+	first := rows
+	for first > 0 && lines >= m.rowHeight(first-1) {
+		lines -= m.rowHeight(first - 1)
+		first--
+	}
+	return min(first, max(0, rows-1))
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Alt+Y switches the detail pane to the selected task's YAML as written in
+// its Taskfile, highlighted with the theme's colors: keys, values, template
+// expressions and comments each get their own style.
+
+// yamlEntry caches the YAML body of one task.
+type yamlEntry struct {
+	body    string
+	err     error
+	loading bool
+}
+
+// yamlMsg delivers an asynchronously read task body.
+type yamlMsg struct {
+	key  string
+	body string
+	err  error
+}
+
+var (
+	// yamlKeyRe matches a mapping key and its colon at the start of s.
+	yamlKeyRe = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"\[{][^#]*?):(?:\s|$)`)
+	// yamlBlockRe matches the indicator of a block scalar, e.g. "|" or ">-".
+	yamlBlockRe = regexp.MustCompile(`^[|>][-+0-9]*$`)
+	// templateRe matches Task's template expressions, e.g. {{.CLI_ARGS}}.
+	templateRe = regexp.MustCompile(`\{\{.*?\}\}`)
+)
+
+// yamlCmd reads the YAML of the selected task when the detail pane shows
+// it, like summaryCmd. Bodies are cached until the next refresh.
+func (m *TaskModel) yamlCmd() tea.Cmd {
+	t, ok := m.selectedTask()
+	if !m.showDetail || !m.showYAML || !ok || t.Backend != taskmeta.BackendTask {
+		return nil
+	}
+	key := taskKey(t)
+	if _, ok := m.yamlBodies[key]; ok {
+		return nil
+	}
+	path, name, ok := taskmeta.TaskfileOf(t, m.projectRoot)
+	if !ok {
+		m.yamlBodies[key] = yamlEntry{err: fmt.Errorf("could not find the Taskfile defining %s", t.Name)}
+		return nil
+	}
+	m.yamlBodies[key] = yamlEntry{loading: true}
+	return func() tea.Msg {
+		body, err := taskmeta.TaskYAML(path, name)
+		return yamlMsg{key: key, body: body, err: err}
+	}
+}
+
+// toggleYAML switches the detail pane between the summary and the YAML of
+// the selected task, opening the pane if needed.
+func (m *TaskModel) toggleYAML() {
+	m.showYAML = !m.showYAML
+	m.detailOffset = 0
+	if m.showYAML && !m.showDetail {
+		m.showDetail = true
+		m.ensureSelectionVisible()
+	}
+}
+
+// yamlLines renders the detail pane's view of t's YAML.
+func (m TaskModel) yamlLines(t taskmeta.Task) []string {
+	if t.Backend != taskmeta.BackendTask {
+		return []string{m.theme.Help.Render(fmt.Sprintf("%s comes from %s, not from a Taskfile", t.Name, t.Backend))}
+	}
+	entry := m.yamlBodies[taskKey(t)]
+	switch {
+	case entry.loading:
+		return []string{m.theme.Help.Render("Reading the Taskfile…")}
+	case entry.err != nil:
+		return []string{m.theme.Error.Render(entry.err.Error())}
+	}
+	lines := []string{m.theme.Accent.Render(t.Name) + m.theme.Help.Render(":")}
+	for _, l := range m.highlightYAML(m.masker.mask(entry.body)) {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+// highlightYAML styles each line of src. It only knows as much YAML as task
+// definitions use: mappings, sequences, block scalars and comments.
+func (m TaskModel) highlightYAML(src string) []string {
+	var out []string
+	block := -1 // indent of the line opening a block scalar, -1 outside one
+	for _, line := range strings.Split(src, "\n") {
+		rest := strings.TrimLeft(line, " ")
+		indent := len(line) - len(rest)
+		if block >= 0 && (rest == "" || indent > block) {
+			out = append(out, m.highlightScalar(line))
+			continue
+		}
+		block = -1
+		var b strings.Builder
+		b.WriteString(line[:indent])
+		for rest == "-" || strings.HasPrefix(rest, "- ") {
+			after := strings.TrimLeft(rest[1:], " ")
+			b.WriteString(m.theme.Help.Render("-") + rest[1:len(rest)-len(after)])
+			rest = after
+		}
+		if k := yamlKeyRe.FindStringSubmatch(rest); k != nil {
+			b.WriteString(m.theme.Accent.Render(k[1]) + m.theme.Help.Render(":"))
+			rest = rest[len(k[1])+1:]
+		}
+		value, comment := splitComment(rest)
+		if yamlBlockRe.MatchString(strings.TrimSpace(value)) {
+			block = indent
+			b.WriteString(m.theme.Help.Render(value))
+		} else {
+			b.WriteString(m.highlightScalar(value))
+		}
+		if comment != "" {
+			b.WriteString(m.theme.Help.Render(comment))
+		}
+		out = append(out, b.String())
+	}
+	return out
+}
+
+// highlightScalar styles a value, setting template expressions apart.
+func (m TaskModel) highlightScalar(s string) string {
+	var b strings.Builder
+	last := 0
+	plain := func(s string) {
+		if s != "" {
+			b.WriteString(m.theme.Command.Render(s))
+		}
+	}
+	for _, loc := range templateRe.FindAllStringIndex(s, -1) {
+		plain(s[last:loc[0]])
+		b.WriteString(m.theme.Highlight.Render(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	plain(s[last:])
+	return b.String()
+}
+
+// splitComment splits a trailing "# comment" off s, ignoring #s in quotes.
+func splitComment(s string) (value, comment string) {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
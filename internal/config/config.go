@@ -1,7 +1,556 @@
-//go:build ignore
+package config
 
-// Archived legacy config placeholder.
-// Actual historical references moved to _archive/config/.
-// File retained only because deletion via automated tool failed during cleanup.
-// It is excluded from builds by the build tag above.
-package ignore
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project layouts for recursive (monorepo) mode.
+const (
+	LayoutTabs   = "tabs"   // one tab per subproject
+	LayoutColumn = "column" // regular tabs, subproject shown next to each task
+)
+
+// Type-to-search modes: what typing a letter in the task list does.
+const (
+	TypeToSearchLetters = "letters" // search, except for the q, j, k and r keys
+	TypeToSearchAll     = "all"     // search with any letter; Ctrl+Q quits
+	TypeToSearchOff     = "off"     // nothing, only / searches
+)
+
+// DefaultDiscoveryTimeout is used when the config file does not set one.
+const DefaultDiscoveryTimeout = 10 * time.Second
+
+// DefaultDescLines is how many lines the name and description of a task
+// wrap to at most.
+const DefaultDescLines = 2
+
+// Defaults of the task grid shown on wide terminals.
+const (
+	DefaultGridColumns     = 3
+	DefaultGridColumnWidth = 70
+)
+
+// Default header lines, Go templates of the project's details; see
+// Config.Header.
+const (
+	DefaultHeader    = "Task Runner Gui - taskg"
+	DefaultSubheader = "{{.Root}}{{with .Branch}} on {{.}}{{end}}{{with .TaskVersion}} · task {{.}}{{end}}"
+)
+
+// Tab grouping strategies.
+const (
+	GroupPrefix    = "prefix"    // name up to the first "-" (backend or subproject when those apply)
+	GroupNamespace = "namespace" // include namespace, the name up to the last ":"
+	GroupFile      = "file"      // Taskfile the task is defined in
+	GroupTag       = "tag"       // one tab per tag
+	GroupFlat      = "flat"      // a single tab
+)
+
+// External run target presets: where run_in opens tasks instead of running
+// them after taskg exits. Any other run_in value is a spawn command template
+// with a {cmd} placeholder, e.g. "wezterm cli spawn -- {cmd}".
+const (
+	RunInTmuxSplit  = "tmux-split"  // a new pane below taskg
+	RunInTmuxWindow = "tmux-window" // a new window of the session
+	RunInZellij     = "zellij"      // a new zellij pane
+	RunInWezterm    = "wezterm"     // a new wezterm tab
+	RunInKitty      = "kitty"       // a new kitty tab, through remote control
+)
+
+// RunInTargets lists the run_in presets.
+var RunInTargets = []string{RunInTmuxSplit, RunInTmuxWindow, RunInZellij, RunInWezterm, RunInKitty}
+
+// ValidRunIn reports whether s is "", one of RunInTargets or a template
+// containing {cmd}.
+func ValidRunIn(s string) bool {
+	return s == "" || slices.Contains(RunInTargets, s) || strings.Contains(s, "{cmd}")
+}
+
+// When a hook is called, see Hook.On.
+const (
+	HookAlways  = "always"
+	HookSuccess = "success"
+	HookFailure = "failure"
+)
+
+// Hook posts a JSON summary of each finished run to a webhook URL, e.g. a
+// Slack or Discord incoming webhook.
+type Hook struct {
+	URL string `yaml:"url"`
+	// Tasks limits the hook to task name patterns, matched like Confirm.
+	// Empty means every task.
+	Tasks []string `yaml:"tasks"`
+	// MinDuration skips runs that took less, e.g. "1m".
+	MinDuration time.Duration `yaml:"min_duration"`
+	// On is HookAlways (the default), HookSuccess or HookFailure.
+	On string `yaml:"on"`
+}
+
+// Logs configures the log files kept of in-TUI runs.
+type Logs struct {
+	// Disabled stops writing log files.
+	Disabled bool `yaml:"disabled"`
+	// MaxAge removes logs of runs started longer ago, e.g. "720h".
+	MaxAge time.Duration `yaml:"max_age"`
+	// MaxSizeMB bounds the total size of a project's logs; the oldest are
+	// removed first.
+	MaxSizeMB int `yaml:"max_size_mb"`
+}
+
+// Retry retries failed in-TUI runs, e.g. of flaky integration tests. The
+// taskg_retry var of a task overrides it.
+type Retry struct {
+	// Count is how many times a failed run is retried.
+	Count int `yaml:"count"`
+	// Backoff is the wait before the first retry, e.g. "5s"; it doubles for
+	// each further one.
+	Backoff time.Duration `yaml:"backoff"`
+	// Tasks limits retries to task name patterns, matched like Confirm.
+	// Empty means every task.
+	Tasks []string `yaml:"tasks"`
+}
+
+// Policy limits the tasks that remotely triggered runs may start, through
+// taskg serve, taskg lsp-ish and taskg ssh-serve. Patterns are matched like
+// Confirm.
+type Policy struct {
+	// Allow lists the tasks that may run. Empty allows every task.
+	Allow []string `yaml:"allow"`
+	// Deny lists tasks that never run, even when Allow matches them too.
+	Deny []string `yaml:"deny"`
+	// Confirm lists tasks that only run once confirmed, on top of the
+	// tasks that ask anyway.
+	Confirm []string `yaml:"confirm"`
+}
+
+// Allows reports whether the task called name may run under p.
+func (p Policy) Allows(name string) bool {
+	if MatchAny(p.Deny, name) {
+		return false
+	}
+	return len(p.Allow) == 0 || MatchAny(p.Allow, name)
+}
+
+// Telemetry exports each run as an OpenTelemetry trace.
+type Telemetry struct {
+	// Endpoint is the OTLP/HTTP collector, e.g. "http://localhost:4318".
+	// Empty turns exporting off.
+	Endpoint string `yaml:"endpoint"`
+	// Headers are sent with each export, e.g. an API key.
+	Headers map[string]string `yaml:"headers"`
+	// ServiceName is the service.name of the spans (default "taskg").
+	ServiceName string `yaml:"service_name"`
+}
+
+// Container runs a project's tasks in a toolchain container, for Taskfiles
+// that assume tools the host does not have.
+type Container struct {
+	// Image is the image the tasks run in, e.g. "golang:1.22".
+	Image string `yaml:"image"`
+	// Engine is "docker" (the default) or "podman".
+	Engine string `yaml:"engine"`
+	// Workdir is where the project root is mounted in the container. It
+	// defaults to the root's path on the host, so absolute paths still work.
+	Workdir string `yaml:"workdir"`
+	// Mounts are further volumes in -v syntax, e.g. "~/.cache/go:/go".
+	Mounts []string `yaml:"mounts"`
+	// Args are extra options for `docker run`, e.g. ["--network", "host"].
+	Args []string `yaml:"args"`
+}
+
+// Container engines.
+var ContainerEngines = []string{"docker", "podman"}
+
+// Default log retention, used when the config file does not set one.
+const (
+	DefaultLogMaxAge    = 14 * 24 * time.Hour
+	DefaultLogMaxSizeMB = 50
+)
+
+// GroupStrategies lists the grouping strategies in the order the UI cycles them.
+var GroupStrategies = []string{GroupPrefix, GroupNamespace, GroupFile, GroupTag, GroupFlat}
+
+// ValidGroupBy reports whether s names a grouping strategy.
+func ValidGroupBy(s string) bool {
+	for _, g := range GroupStrategies {
+		if s == g {
+			return true
+		}
+	}
+	return false
+}
+
+// Config holds user preferences from config.yml in the taskg config directory.
+// Command-line flags take precedence over these values.
+type Config struct {
+	// Recursive scans subdirectories for further Taskfiles (monorepo mode).
+	Recursive bool `yaml:"recursive"`
+	// ProjectLayout is LayoutTabs or LayoutColumn.
+	ProjectLayout string `yaml:"project_layout"`
+	// GroupBy is one of GroupStrategies.
+	GroupBy string `yaml:"group_by"`
+	// DiscoveryTimeout bounds parsing the Taskfile and each `task --list`
+	// call, e.g. "30s".
+	DiscoveryTimeout time.Duration `yaml:"discovery_timeout"`
+	// Confirm lists task name patterns, e.g. "*deploy*", that ask for a y/N
+	// confirmation before running.
+	Confirm []string `yaml:"confirm"`
+	// ShowPlan shows the order in which a task and its deps will run, and
+	// asks before running tasks that start others.
+	ShowPlan bool `yaml:"show_plan"`
+	// Mask lists extra regular expressions whose matches are hidden in
+	// command previews and task output, on top of the built-in secret
+	// patterns. With a capture group only the group is hidden.
+	Mask []string `yaml:"mask"`
+	// HideUnsupported hides tasks whose platforms: exclude the current OS
+	// and architecture instead of graying them out.
+	HideUnsupported bool `yaml:"hide_unsupported"`
+	// GridColumns caps the columns the task list is split into on wide
+	// terminals; 1 keeps a single column.
+	GridColumns int `yaml:"grid_columns"`
+	// GridColumnWidth is the narrowest a grid column may get, in cells, so
+	// it sets how wide the terminal must be before the list is split.
+	GridColumnWidth int `yaml:"grid_column_width"`
+	// HideCommands starts the list without the command line under each
+	// task, which Alt+P toggles.
+	HideCommands bool `yaml:"hide_commands"`
+	// PinnedTabs lists tabs, e.g. "main" or "docker", shown first in every
+	// project, in this order.
+	PinnedTabs []string `yaml:"pinned_tabs"`
+	// HideTabCounts leaves the number of tasks out of tab labels.
+	HideTabCounts bool `yaml:"hide_tab_counts"`
+	// Tree lists all tasks in one list, with a collapsible section per
+	// group, instead of one tab per group.
+	Tree bool `yaml:"tree"`
+	// ASCII draws with ASCII characters only, for consoles and fonts that
+	// lack box-drawing characters and other glyphs. taskg turns it on by
+	// itself on such terminals.
+	ASCII bool `yaml:"ascii"`
+	// Accessible renders for screen readers and braille displays: plain
+	// lines of text with a marker per row instead of boxes.
+	Accessible bool `yaml:"accessible"`
+	// ReducedMotion stills animations such as the discovery spinner.
+	ReducedMotion bool `yaml:"reduced_motion"`
+	// Icons prefixes tasks with a Nerd Font icon for their category, e.g.
+	// docker, test, build, db or deploy, inferred from their name.
+	Icons bool `yaml:"icons"`
+	// IconMap sets the icon of a category or of any word of task names,
+	// e.g. {proto: "\uf1c9", lint: ""}; an empty icon drops it.
+	IconMap map[string]string `yaml:"icon_map"`
+	// Header is the first line of the header, a Go template that sees
+	// .Project, .Root, .Branch (git) and .TaskVersion, e.g.
+	// "{{.Project}} on {{.Branch}}". Subheader is the second line, which
+	// warnings take over when there are any.
+	Header    string `yaml:"header"`
+	Subheader string `yaml:"subheader"`
+	// HideHeader, HideFooter and Borderless start without the header, the
+	// footer or the borders framing the screen, which Alt+H, Alt+F and
+	// Alt+B toggle, to fit more tasks on small panes.
+	HideHeader bool `yaml:"hide_header"`
+	HideFooter bool `yaml:"hide_footer"`
+	Borderless bool `yaml:"borderless"`
+	// TypeToSearch is what typing a letter in the task list does: one of
+	// TypeToSearchAll (the default), TypeToSearchLetters or TypeToSearchOff.
+	// The default searches with every letter, so names starting with q, j,
+	// k or r are typed like any other.
+	TypeToSearch string `yaml:"type_to_search"`
+	// ScrollMargin is how many rows of the list stay visible above and below
+	// the selection while scrolling; a large value keeps it centered.
+	ScrollMargin int `yaml:"scroll_margin"`
+	// DescLines is how many lines the name and description of a task wrap
+	// to in the list before they are cut short; 1 keeps every task on one
+	// line. It defaults to DefaultDescLines.
+	DescLines int `yaml:"desc_lines"`
+	// RunIn makes Enter open tasks in a new pane, window or tab and keeps
+	// taskg open: one of RunInTargets, which apply inside their multiplexer
+	// or terminal, or a spawn command template with {cmd}.
+	RunIn string `yaml:"run_in"`
+	// Hooks are called after every run that taskg sees finish.
+	Hooks []Hook `yaml:"hooks"`
+	// Logs configures the per-run log files under .taskg/logs.
+	Logs Logs `yaml:"logs"`
+	// Retry is the default retry policy of in-TUI runs.
+	Retry Retry `yaml:"retry"`
+	// Remote limits the tasks that serve, lsp-ish and ssh-serve may run.
+	Remote Policy `yaml:"remote"`
+	// Telemetry exports runs as OpenTelemetry spans.
+	Telemetry Telemetry `yaml:"telemetry"`
+	// Workspaces names lists of project roots that `taskg workspace` runs
+	// a task in. A leading ~/ stands for the home directory.
+	Workspaces map[string][]string `yaml:"workspaces"`
+	// Containers maps project roots to the container their tasks run in
+	// with Alt+C. A leading ~/ stands for the home directory.
+	Containers map[string]Container `yaml:"containers"`
+}
+
+// Default returns the configuration used when no config file exists.
+func Default() *Config {
+	return &Config{
+		ProjectLayout:    LayoutTabs,
+		GroupBy:          GroupPrefix,
+		TypeToSearch:     TypeToSearchAll,
+		DiscoveryTimeout: DefaultDiscoveryTimeout,
+		GridColumns:      DefaultGridColumns,
+		GridColumnWidth:  DefaultGridColumnWidth,
+		DescLines:        DefaultDescLines,
+		Logs:             Logs{MaxAge: DefaultLogMaxAge, MaxSizeMB: DefaultLogMaxSizeMB},
+	}
+}
+
+// Path returns the location of the config file, e.g. ~/.config/taskg/config.yml.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "taskg", "config.yml"), nil
+}
+
+// Load reads the config file. A missing file yields the defaults; a malformed
+// one yields the defaults and an error describing the problem.
+func Load() (*Config, error) {
+	cfg := Default()
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	switch c.ProjectLayout {
+	case "":
+		c.ProjectLayout = LayoutTabs
+	case LayoutTabs, LayoutColumn:
+	default:
+		return fmt.Errorf("project_layout must be %q or %q, got %q", LayoutTabs, LayoutColumn, c.ProjectLayout)
+	}
+	switch c.TypeToSearch {
+	case "":
+		c.TypeToSearch = TypeToSearchAll
+	case TypeToSearchAll, TypeToSearchLetters, TypeToSearchOff:
+	default:
+		return fmt.Errorf("type_to_search must be %q, %q or %q, got %q", TypeToSearchAll, TypeToSearchLetters, TypeToSearchOff, c.TypeToSearch)
+	}
+	if c.GroupBy == "" {
+		c.GroupBy = GroupPrefix
+	} else if !ValidGroupBy(c.GroupBy) {
+		return fmt.Errorf("group_by must be one of %s, got %q", strings.Join(GroupStrategies, ", "), c.GroupBy)
+	}
+	if c.DiscoveryTimeout < 0 {
+		return fmt.Errorf("discovery_timeout must not be negative, got %s", c.DiscoveryTimeout)
+	}
+	if c.DiscoveryTimeout == 0 {
+		c.DiscoveryTimeout = DefaultDiscoveryTimeout
+	}
+	if c.ScrollMargin < 0 {
+		return fmt.Errorf("scroll_margin must not be negative, got %d", c.ScrollMargin)
+	}
+	if c.DescLines < 0 {
+		return fmt.Errorf("desc_lines must not be negative, got %d", c.DescLines)
+	}
+	if c.DescLines == 0 {
+		c.DescLines = DefaultDescLines
+	}
+	if c.GridColumns < 0 || c.GridColumnWidth < 0 {
+		return errors.New("grid_columns and grid_column_width must not be negative")
+	}
+	if c.GridColumns == 0 {
+		c.GridColumns = DefaultGridColumns
+	}
+	if c.GridColumnWidth == 0 {
+		c.GridColumnWidth = DefaultGridColumnWidth
+	}
+	for _, t := range []string{c.Header, c.Subheader} {
+		if _, err := template.New("header").Parse(t); err != nil {
+			return fmt.Errorf("header: %w", err)
+		}
+	}
+	if !ValidRunIn(c.RunIn) {
+		return fmt.Errorf("run_in must be one of %s or a command with {cmd}, got %q", strings.Join(RunInTargets, ", "), c.RunIn)
+	}
+	for _, p := range c.Confirm {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("confirm: bad pattern %q", p)
+		}
+	}
+	for _, p := range c.Mask {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("mask: %w", err)
+		}
+	}
+	if c.Logs.MaxAge < 0 || c.Logs.MaxSizeMB < 0 {
+		return errors.New("logs: max_age and max_size_mb must not be negative")
+	}
+	if c.Logs.MaxAge == 0 {
+		c.Logs.MaxAge = DefaultLogMaxAge
+	}
+	if c.Logs.MaxSizeMB == 0 {
+		c.Logs.MaxSizeMB = DefaultLogMaxSizeMB
+	}
+	if c.Retry.Count < 0 || c.Retry.Backoff < 0 {
+		return errors.New("retry: count and backoff must not be negative")
+	}
+	for _, p := range c.Retry.Tasks {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("retry: bad pattern %q", p)
+		}
+	}
+	for _, list := range [][]string{c.Remote.Allow, c.Remote.Deny, c.Remote.Confirm} {
+		for _, p := range list {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("remote: bad pattern %q", p)
+			}
+		}
+	}
+	if c.Telemetry.Endpoint != "" {
+		u, err := url.Parse(c.Telemetry.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("telemetry: endpoint must be an http or https URL, got %q", c.Telemetry.Endpoint)
+		}
+	}
+	for i := range c.Hooks {
+		if err := c.Hooks[i].validate(); err != nil {
+			return fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+	}
+	for name, roots := range c.Workspaces {
+		if len(roots) == 0 {
+			return fmt.Errorf("workspaces: %s lists no projects", name)
+		}
+		for i, root := range roots {
+			if rest, ok := strings.CutPrefix(root, "~/"); ok {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("workspaces: %s: %w", name, err)
+				}
+				roots[i] = filepath.Join(home, rest)
+			}
+		}
+	}
+	containers := make(map[string]Container, len(c.Containers))
+	for root, ct := range c.Containers {
+		if err := ct.validate(); err != nil {
+			return fmt.Errorf("containers: %s: %w", root, err)
+		}
+		root, err := expandHome(root)
+		if err != nil {
+			return fmt.Errorf("containers: %w", err)
+		}
+		containers[filepath.Clean(root)] = ct
+	}
+	c.Containers = containers
+	return nil
+}
+
+func (c *Container) validate() error {
+	if c.Image == "" {
+		return errors.New("image is required")
+	}
+	if c.Engine == "" {
+		c.Engine = ContainerEngines[0]
+	} else if !slices.Contains(ContainerEngines, c.Engine) {
+		return fmt.Errorf("engine must be one of %s, got %q", strings.Join(ContainerEngines, ", "), c.Engine)
+	}
+	if c.Workdir != "" && !path.IsAbs(c.Workdir) {
+		return fmt.Errorf("workdir must be an absolute path, got %q", c.Workdir)
+	}
+	for i, m := range c.Mounts {
+		host, rest, ok := strings.Cut(m, ":")
+		if !ok || host == "" || rest == "" {
+			return fmt.Errorf("mounts: expected host:container, got %q", m)
+		}
+		host, err := expandHome(host)
+		if err != nil {
+			return fmt.Errorf("mounts: %w", err)
+		}
+		c.Mounts[i] = host + ":" + rest
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ in p with the home directory.
+func expandHome(p string) (string, error) {
+	rest, ok := strings.CutPrefix(p, "~/")
+	if !ok {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// ContainerFor returns the container configured for the project dir belongs
+// to, and that project's root. The innermost configured root wins.
+func ContainerFor(containers map[string]Container, dir string) (Container, string, bool) {
+	var found Container
+	best := ""
+	for root, c := range containers {
+		if (dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))) && len(root) > len(best) {
+			found, best = c, root
+		}
+	}
+	return found, best, best != ""
+}
+
+func (h *Hook) validate() error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL, got %q", h.URL)
+	}
+	switch h.On {
+	case "":
+		h.On = HookAlways
+	case HookAlways, HookSuccess, HookFailure:
+	default:
+		return fmt.Errorf("on must be %q, %q or %q, got %q", HookAlways, HookSuccess, HookFailure, h.On)
+	}
+	if h.MinDuration < 0 {
+		return fmt.Errorf("min_duration must not be negative, got %s", h.MinDuration)
+	}
+	for _, p := range h.Tasks {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("tasks: bad pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// MatchAny reports whether name matches one of patterns, e.g. the Confirm
+// patterns, in path.Match syntax and ignoring case.
+func MatchAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestPolicyAllows(t *testing.T) {
+	tests := []struct {
+		policy   Policy
+		name     string
+		expected bool
+	}{
+		{Policy{}, "deploy-prod", true},
+		{Policy{Allow: []string{"build", "test*"}}, "build", true},
+		{Policy{Allow: []string{"build", "test*"}}, "test:unit", true},
+		{Policy{Allow: []string{"build", "test*"}}, "deploy", false},
+		{Policy{Deny: []string{"*prod*"}}, "deploy-prod", false},
+		{Policy{Deny: []string{"*prod*"}}, "deploy-staging", true},
+		{Policy{Allow: []string{"deploy-*"}, Deny: []string{"*prod*"}}, "deploy-prod", false}, // Deny wins
+		{Policy{Allow: []string{"deploy-*"}, Deny: []string{"*prod*"}}, "deploy-staging", true},
+		{Policy{Allow: []string{"Build"}}, "build", true}, // case is ignored
+	}
+
+	for _, test := range tests {
+		if got := test.policy.Allows(test.name); got != test.expected {
+			t.Errorf("Task '%s' under %+v: expected %v, got %v", test.name, test.policy, test.expected, got)
+		}
+	}
+}
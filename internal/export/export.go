@@ -0,0 +1,34 @@
+// Package export turns discovered tasks into files other tools read: VS Code
+// tasks, Markdown documentation, dependency graphs and CI workflows.
+package export
+
+import (
+	"path/filepath"
+
+	"taskg/internal/taskmeta"
+)
+
+// invocation is how t is started from root: the binary and its arguments,
+// and the directory relative to root ("." for root itself). Tasks of the
+// built-in runner are exported as `task` calls, since the file is meant for
+// machines that have it.
+func invocation(t taskmeta.Task, root string, args []string) (bin string, argv []string, dir string) {
+	bin, argv = t.Invocation(args)
+	if t.Script != "" {
+		bin, argv = "task", append([]string{t.Name}, args...)
+	}
+	dir = "."
+	if rel, err := filepath.Rel(root, t.WorkDir(root)); err == nil {
+		dir = filepath.ToSlash(rel)
+	}
+	return bin, argv, dir
+}
+
+// label names t the way the exports refer to it: the subproject, if any,
+// and the task name.
+func label(t taskmeta.Task) string {
+	if t.Project != "" && t.Project != "." {
+		return t.Project + "/" + t.Name
+	}
+	return t.Name
+}
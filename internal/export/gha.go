@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"taskg/internal/taskmeta"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitHubWorkflow renders a GitHub Actions workflow with one job per named
+// task, so CI calls the Taskfile instead of repeating its commands. Each
+// job checks out the repository, installs what the task's backend needs
+// and runs the task from its directory. names are matched against the task
+// name, or "<subproject>/<name>" in recursive mode.
+func GitHubWorkflow(tasks []taskmeta.Task, root, title string, names []string) ([]byte, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("name at least one task to run")
+	}
+	var b strings.Builder
+	b.WriteString("# Generated by `taskg export gha`; edit the Taskfile instead.\n")
+	fmt.Fprintf(&b, "name: %s\n\n", yamlScalar(title))
+	b.WriteString("on:\n  push:\n  pull_request:\n\njobs:\n")
+	seen := map[string]bool{}
+	for _, name := range names {
+		t, ok := findTask(tasks, name)
+		if !ok {
+			return nil, fmt.Errorf("no task named %q", name)
+		}
+		id := jobID(label(t))
+		for n := 2; seen[id]; n++ {
+			id = fmt.Sprintf("%s-%d", jobID(label(t)), n)
+		}
+		seen[id] = true
+		bin, argv, dir := invocation(t, root, nil)
+		fmt.Fprintf(&b, "  %s:\n", id)
+		fmt.Fprintf(&b, "    name: %s\n", yamlScalar(label(t)))
+		b.WriteString("    runs-on: ubuntu-latest\n    steps:\n")
+		b.WriteString("      - uses: actions/checkout@v4\n")
+		switch bin {
+		case "task":
+			b.WriteString("      - uses: arduino/setup-task@v2\n")
+			b.WriteString("        with:\n          version: 3.x\n          repo-token: ${{ secrets.GITHUB_TOKEN }}\n")
+		case "npm":
+			b.WriteString("      - uses: actions/setup-node@v4\n")
+			b.WriteString("      - run: npm ci\n")
+			writeWorkingDir(&b, dir)
+		}
+		fmt.Fprintf(&b, "      - run: %s\n", yamlScalar(taskmeta.CommandLine(bin, argv)))
+		writeWorkingDir(&b, dir)
+	}
+	return []byte(b.String()), nil
+}
+
+// findTask returns the task called name, by label or by plain name.
+func findTask(tasks []taskmeta.Task, name string) (taskmeta.Task, bool) {
+	for _, t := range tasks {
+		if label(t) == name {
+			return t, true
+		}
+	}
+	for _, t := range tasks {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return taskmeta.Task{}, false
+}
+
+func writeWorkingDir(b *strings.Builder, dir string) {
+	if dir != "." {
+		fmt.Fprintf(b, "        working-directory: %s\n", yamlScalar(dir))
+	}
+}
+
+// jobIDRe matches the characters GitHub does not allow in job ids.
+var jobIDRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// jobID turns a task label into a job id, e.g. "api/db:migrate" into
+// "api-db-migrate".
+func jobID(s string) string {
+	id := strings.Trim(jobIDRe.ReplaceAllString(s, "-"), "-")
+	if id == "" || !(id[0] == '_' || (id[0]|0x20 >= 'a' && id[0]|0x20 <= 'z')) {
+		id = "task-" + id
+	}
+	return id
+}
+
+// yamlScalar renders s as a YAML scalar, quoted only where YAML needs it.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil || strings.Count(string(out), "\n") > 1 {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
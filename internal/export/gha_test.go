@@ -0,0 +1,106 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"taskg/internal/taskmeta"
+)
+
+func TestJobID(t *testing.T) {
+	tests := []struct {
+		label string
+		id    string
+	}{
+		{"build", "build"},
+		{"db:migrate", "db-migrate"},
+		{"api/db:migrate", "api-db-migrate"},
+		{"web/lint", "web-lint"},
+		{"_private", "_private"},
+		{"Build", "Build"},
+		{"a  b..c", "a-b-c"},
+		{":weird:", "weird"},
+		{"1build", "task-1build"},
+		{"-x", "x"},
+		{"::", "task-"},
+	}
+
+	for _, test := range tests {
+		if got := jobID(test.label); got != test.id {
+			t.Errorf("Label '%s': expected job id '%s', got '%s'", test.label, test.id, got)
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"CI", "CI"},
+		{"task build", "task build"},
+		{"", `""`},
+		{"yes", `"yes"`},
+		{"a: b", `'a: b'`},
+		{"#comment", `'#comment'`},
+		{"two\nlines", `"two\nlines"`},
+	}
+
+	for _, test := range tests {
+		if got := yamlScalar(test.in); got != test.out {
+			t.Errorf("Scalar '%s': expected %s, got %s", test.in, test.out, got)
+		}
+	}
+}
+
+func TestGitHubWorkflow(t *testing.T) {
+	tasks := []taskmeta.Task{
+		{Name: "build", Backend: taskmeta.BackendTask},
+		{Name: "db:migrate", Backend: taskmeta.BackendTask, Project: "api", ProjectDir: "/r/api"},
+		{Name: "lint", Backend: taskmeta.BackendNPM, Project: "web", ProjectDir: "/r/web"},
+	}
+
+	tests := []struct {
+		names    []string
+		contains []string
+		err      string
+	}{
+		{nil, nil, "name at least one task to run"},
+		{[]string{"deploy"}, nil, `no task named "deploy"`},
+		{[]string{"build"}, []string{
+			"name: CI\n",
+			"  build:\n    name: build\n",
+			"      - uses: arduino/setup-task@v2\n",
+			"      - run: task build\n",
+		}, ""},
+		{[]string{"web/lint"}, []string{
+			"  web-lint:\n    name: web/lint\n",
+			"      - uses: actions/setup-node@v4\n      - run: npm ci\n        working-directory: web\n",
+			"      - run: npm run lint\n        working-directory: web\n",
+		}, ""},
+		{[]string{"db:migrate"}, []string{
+			"  api-db-migrate:\n    name: api/db:migrate\n",
+			"        working-directory: api\n",
+		}, ""},
+		{[]string{"build", "build"}, []string{"  build:\n", "  build-2:\n"}, ""},
+	}
+
+	for _, test := range tests {
+		out, err := GitHubWorkflow(tasks, "/r", "CI", test.names)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Names %v: expected error '%s', got %v", test.names, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Names %v: %v", test.names, err)
+			continue
+		}
+		for _, want := range test.contains {
+			if !strings.Contains(string(out), want) {
+				t.Errorf("Names %v: expected the workflow to contain %q, got:\n%s", test.names, want, out)
+			}
+		}
+	}
+}
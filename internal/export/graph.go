@@ -0,0 +1,222 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// Graph formats accepted by Graph.
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// GraphFormats lists the formats Graph can render.
+var GraphFormats = []string{GraphDOT, GraphMermaid}
+
+// graphNode is a task of the dependency graph. Deps that are not among the
+// discovered tasks, such as internal tasks, get a node too, drawn dashed.
+type graphNode struct {
+	id      string // the task's label
+	project string
+	name    string
+	missing bool
+	deps    []string
+}
+
+// cluster groups the nodes of a subproject or include namespace.
+type cluster struct {
+	name     string
+	children map[string]*cluster
+	nodes    []*graphNode
+}
+
+// Graph renders the dependency graph of tasks as Graphviz DOT or a Mermaid
+// flowchart, with an arrow from each task to the tasks in its deps:.
+// Subprojects and include namespaces become nested clusters. With focus set,
+// only that task and the tasks it depends on, directly or not, are drawn.
+func Graph(tasks []taskmeta.Task, format, focus string) ([]byte, error) {
+	nodes := map[string]*graphNode{}
+	for _, t := range tasks {
+		nodes[label(t)] = &graphNode{id: label(t), project: graphProject(t), name: t.Name}
+	}
+	for _, t := range tasks {
+		n := nodes[label(t)]
+		for _, d := range t.Deps {
+			dep := taskmeta.Task{Name: d, Project: t.Project}
+			id := label(dep)
+			if nodes[id] == nil {
+				nodes[id] = &graphNode{id: id, project: graphProject(dep), name: d, missing: true}
+			}
+			if !slices.Contains(n.deps, id) {
+				n.deps = append(n.deps, id)
+			}
+		}
+	}
+	if focus != "" {
+		var err error
+		if nodes, err = subtree(nodes, focus); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	root := &cluster{children: map[string]*cluster{}}
+	for _, id := range ids {
+		n := nodes[id]
+		c := root
+		for _, part := range graphClusterPath(n) {
+			if c.children[part] == nil {
+				c.children[part] = &cluster{name: part, children: map[string]*cluster{}}
+			}
+			c = c.children[part]
+		}
+		c.nodes = append(c.nodes, n)
+	}
+
+	switch format {
+	case GraphDOT:
+		return renderDOT(root, nodes, ids), nil
+	case GraphMermaid:
+		return renderMermaid(root, nodes, ids), nil
+	}
+	return nil, fmt.Errorf("unknown graph format %q, want one of %s", format, strings.Join(GraphFormats, ", "))
+}
+
+// subtree keeps the node named focus and the nodes it reaches.
+func subtree(nodes map[string]*graphNode, focus string) (map[string]*graphNode, error) {
+	if nodes[focus] == nil {
+		return nil, fmt.Errorf("no task named %q", focus)
+	}
+	keep := map[string]*graphNode{}
+	queue := []string{focus}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if keep[id] != nil {
+			continue
+		}
+		keep[id] = nodes[id]
+		queue = append(queue, nodes[id].deps...)
+	}
+	return keep, nil
+}
+
+func graphProject(t taskmeta.Task) string {
+	if t.Project == "." {
+		return ""
+	}
+	return t.Project
+}
+
+// graphClusterPath returns the clusters n is nested in: its subproject and
+// each level of its include namespace.
+func graphClusterPath(n *graphNode) []string {
+	var path []string
+	if n.project != "" {
+		path = append(path, n.project)
+	}
+	if i := strings.LastIndex(n.name, ":"); i > 0 {
+		path = append(path, strings.Split(n.name[:i], ":")...)
+	}
+	return path
+}
+
+// sortedChildren returns the subclusters of c by name.
+func (c *cluster) sortedChildren() []*cluster {
+	names := make([]string, 0, len(c.children))
+	for name := range c.children {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	out := make([]*cluster, len(names))
+	for i, name := range names {
+		out[i] = c.children[name]
+	}
+	return out
+}
+
+func renderDOT(root *cluster, nodes map[string]*graphNode, ids []string) []byte {
+	var b strings.Builder
+	b.WriteString("// Generated by `taskg graph`; edit the Taskfile instead.\n")
+	b.WriteString("digraph tasks {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	n := 0
+	var walk func(c *cluster, indent string)
+	walk = func(c *cluster, indent string) {
+		for _, node := range c.nodes {
+			b.WriteString(indent + dotID(node.id))
+			if node.missing {
+				b.WriteString(" [style=dashed]")
+			}
+			b.WriteString(";\n")
+		}
+		for _, sub := range c.sortedChildren() {
+			n++
+			fmt.Fprintf(&b, "%ssubgraph cluster_%d {\n%s\tlabel=%s;\n", indent, n, indent, dotID(sub.name))
+			walk(sub, indent+"\t")
+			b.WriteString(indent + "}\n")
+		}
+	}
+	walk(root, "\t")
+	for _, id := range ids {
+		for _, d := range nodes[id].deps {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", dotID(id), dotID(d))
+		}
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// dotID quotes s as a DOT identifier.
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func renderMermaid(root *cluster, nodes map[string]*graphNode, ids []string) []byte {
+	// Mermaid ids are kept to letters and digits; the names go in labels.
+	short := map[string]string{}
+	for i, id := range ids {
+		short[id] = fmt.Sprintf("t%d", i)
+	}
+	var b strings.Builder
+	b.WriteString("%% Generated by `taskg graph`; edit the Taskfile instead.\n")
+	b.WriteString("flowchart LR\n")
+	n := 0
+	var walk func(c *cluster, indent string)
+	walk = func(c *cluster, indent string) {
+		for _, node := range c.nodes {
+			fmt.Fprintf(&b, "%s%s[%s]", indent, short[node.id], mermaidLabel(node.id))
+			if node.missing {
+				b.WriteString(":::missing")
+			}
+			b.WriteString("\n")
+		}
+		for _, sub := range c.sortedChildren() {
+			n++
+			fmt.Fprintf(&b, "%ssubgraph c%d [%s]\n", indent, n, mermaidLabel(sub.name))
+			walk(sub, indent+"  ")
+			b.WriteString(indent + "end\n")
+		}
+	}
+	walk(root, "  ")
+	for _, id := range ids {
+		for _, d := range nodes[id].deps {
+			fmt.Fprintf(&b, "  %s --> %s\n", short[id], short[d])
+		}
+	}
+	b.WriteString("  classDef missing stroke-dasharray: 5 5\n")
+	return []byte(b.String())
+}
+
+// mermaidLabel quotes s as a Mermaid label, which has no escape for quotes
+// but an entity.
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
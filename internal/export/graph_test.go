@@ -0,0 +1,146 @@
+package export
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"taskg/internal/taskmeta"
+)
+
+// graphTasks is a small project: a root build, a db namespace, an internal
+// dep that is not listed, and a subproject.
+var graphTasks = []taskmeta.Task{
+	{Name: "build", Deps: []string{"gen"}},
+	{Name: "db:migrate", Deps: []string{"build", "build"}},
+	{Name: "test", Project: "api", Deps: []string{"db:up"}},
+	{Name: "db:up", Project: "api"},
+}
+
+func TestGraphDOT(t *testing.T) {
+	want := "// Generated by `taskg graph`; edit the Taskfile instead.\n" +
+		"digraph tasks {\n" +
+		"\trankdir=LR;\n" +
+		"\tnode [shape=box];\n" +
+		"\t\"build\";\n" +
+		"\t\"gen\" [style=dashed];\n" +
+		"\tsubgraph cluster_1 {\n" +
+		"\t\tlabel=\"api\";\n" +
+		"\t\t\"api/test\";\n" +
+		"\t\tsubgraph cluster_2 {\n" +
+		"\t\t\tlabel=\"db\";\n" +
+		"\t\t\t\"api/db:up\";\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\tsubgraph cluster_3 {\n" +
+		"\t\tlabel=\"db\";\n" +
+		"\t\t\"db:migrate\";\n" +
+		"\t}\n" +
+		"\t\"api/test\" -> \"api/db:up\";\n" +
+		"\t\"build\" -> \"gen\";\n" +
+		"\t\"db:migrate\" -> \"build\";\n" +
+		"}\n"
+
+	got, err := Graph(graphTasks, GraphDOT, "")
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	want := "%% Generated by `taskg graph`; edit the Taskfile instead.\n" +
+		"flowchart LR\n" +
+		"  t2[\"build\"]\n" +
+		"  t4[\"gen\"]:::missing\n" +
+		"  subgraph c1 [\"api\"]\n" +
+		"    t1[\"api/test\"]\n" +
+		"    subgraph c2 [\"db\"]\n" +
+		"      t0[\"api/db:up\"]\n" +
+		"    end\n" +
+		"  end\n" +
+		"  subgraph c3 [\"db\"]\n" +
+		"    t3[\"db:migrate\"]\n" +
+		"  end\n" +
+		"  t1 --> t0\n" +
+		"  t2 --> t4\n" +
+		"  t3 --> t2\n" +
+		"  classDef missing stroke-dasharray: 5 5\n"
+
+	got, err := Graph(graphTasks, GraphMermaid, "")
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestGraphFocus(t *testing.T) {
+	tests := []struct {
+		focus string
+		nodes []string
+		err   string
+	}{
+		{"", []string{`"api/db:up"`, `"api/test"`, `"build"`, `"db:migrate"`, `"gen"`}, ""},
+		{"db:migrate", []string{`"build"`, `"db:migrate"`, `"gen"`}, ""},
+		{"build", []string{`"build"`, `"gen"`}, ""},
+		{"gen", []string{`"gen"`}, ""},
+		{"api/test", []string{`"api/db:up"`, `"api/test"`}, ""},
+		{"deploy", nil, `no task named "deploy"`},
+	}
+
+	for _, test := range tests {
+		got, err := Graph(graphTasks, GraphDOT, test.focus)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Focus '%s': expected error '%s', got %v", test.focus, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Focus '%s': %v", test.focus, err)
+			continue
+		}
+		var nodes []string
+		for _, line := range strings.Split(string(got), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, `"`) && !strings.Contains(line, "->") {
+				nodes = append(nodes, strings.TrimSuffix(strings.TrimSuffix(line, ";"), " [style=dashed]"))
+			}
+		}
+		slices.Sort(nodes)
+		if !slices.Equal(nodes, test.nodes) {
+			t.Errorf("Focus '%s': expected nodes %v, got %v", test.focus, test.nodes, nodes)
+		}
+	}
+}
+
+func TestGraphFormat(t *testing.T) {
+	if _, err := Graph(graphTasks, "svg", ""); err == nil || !strings.Contains(err.Error(), "dot, mermaid") {
+		t.Errorf("Expected an error naming the formats for svg, got %v", err)
+	}
+}
+
+func TestGraphQuoting(t *testing.T) {
+	tests := []struct {
+		in      string
+		dot     string
+		mermaid string
+	}{
+		{"build", `"build"`, `"build"`},
+		{`say "hi"`, `"say \"hi\""`, `"say #quot;hi#quot;"`},
+		{`C:\path`, `"C:\\path"`, `"C:\path"`},
+	}
+
+	for _, test := range tests {
+		if got := dotID(test.in); got != test.dot {
+			t.Errorf("DOT id '%s': expected %s, got %s", test.in, test.dot, got)
+		}
+		if got := mermaidLabel(test.in); got != test.mermaid {
+			t.Errorf("Mermaid label '%s': expected %s, got %s", test.in, test.mermaid, got)
+		}
+	}
+}
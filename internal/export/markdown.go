@@ -0,0 +1,86 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// Markdown renders tasks as a TASKS.md: one table of names, descriptions,
+// commands and dependencies per namespace, root tasks first. Subprojects
+// and other backends get sections of their own. Tasks are sorted by name
+// so regenerating the file only shows real changes in a diff.
+func Markdown(tasks []taskmeta.Task, title string) []byte {
+	sections := map[string][]taskmeta.Task{}
+	for _, t := range tasks {
+		key := mdSection(t)
+		sections[key] = append(sections[key], t)
+	}
+	keys := make([]string, 0, len(sections))
+	for k := range sections {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys) // "" (the root) sorts first
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	b.WriteString("<!-- Generated by `taskg export md`; edit the Taskfile instead. -->\n")
+	for _, k := range keys {
+		if k != "" {
+			fmt.Fprintf(&b, "\n## %s\n", k)
+		}
+		list := sections[k]
+		slices.SortFunc(list, func(a, c taskmeta.Task) int { return strings.Compare(label(a), label(c)) })
+		b.WriteString("\n| Task | Description | Commands | Dependencies |\n|---|---|---|---|\n")
+		for _, t := range list {
+			var cmds, deps []string
+			for _, c := range t.Cmds {
+				cmds = append(cmds, codeSpan(c))
+			}
+			for _, d := range t.Deps {
+				deps = append(deps, codeSpan(d))
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				codeSpan(label(t)), mdCell(t.Desc), strings.Join(cmds, "<br>"), strings.Join(deps, ", "))
+		}
+	}
+	return []byte(b.String())
+}
+
+// mdSection names the section t is listed in: its subproject, backend and
+// include namespace, or "" for tasks of the root Taskfile.
+func mdSection(t taskmeta.Task) string {
+	var parts []string
+	if t.Project != "" && t.Project != "." {
+		parts = append(parts, t.Project)
+	}
+	if t.Backend != "" && t.Backend != taskmeta.BackendTask {
+		parts = append(parts, t.Backend)
+	}
+	if i := strings.LastIndex(t.Name, ":"); i > 0 {
+		parts = append(parts, t.Name[:i])
+	}
+	return strings.Join(parts, " / ")
+}
+
+// mdCell makes s fit in a table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// codeSpan renders s as inline code in a table cell, using a longer run of
+// backticks than s contains.
+func codeSpan(s string) string {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, "|", `\|`)), " ")
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
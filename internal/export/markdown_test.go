@@ -0,0 +1,96 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"taskg/internal/taskmeta"
+)
+
+func TestMdSection(t *testing.T) {
+	tests := []struct {
+		task    taskmeta.Task
+		section string
+	}{
+		{taskmeta.Task{Name: "build"}, ""},
+		{taskmeta.Task{Name: "build", Project: ".", Backend: taskmeta.BackendTask}, ""},
+		{taskmeta.Task{Name: "db:migrate"}, "db"},
+		{taskmeta.Task{Name: "docker:db:up"}, "docker:db"},
+		{taskmeta.Task{Name: ":weird"}, ""},
+		{taskmeta.Task{Name: "lint", Backend: taskmeta.BackendNPM}, "npm"},
+		{taskmeta.Task{Name: "db:migrate", Project: "api"}, "api / db"},
+		{taskmeta.Task{Name: "lint", Project: "web", Backend: taskmeta.BackendNPM}, "web / npm"},
+	}
+
+	for _, test := range tests {
+		if got := mdSection(test.task); got != test.section {
+			t.Errorf("Task %+v: expected section '%s', got '%s'", test.task, test.section, got)
+		}
+	}
+}
+
+func TestMdCell(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"", ""},
+		{"Build it", "Build it"},
+		{"  padded  ", "padded"},
+		{"a | b", `a \| b`},
+		{"first\nsecond", "first<br>second"},
+	}
+
+	for _, test := range tests {
+		if got := mdCell(test.in); got != test.out {
+			t.Errorf("Cell '%s': expected '%s', got '%s'", test.in, test.out, got)
+		}
+	}
+}
+
+func TestCodeSpan(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"build", "`build`"},
+		{"go  build\n  ./...", "`go build ./...`"},
+		{"a | b", "`a \\| b`"},
+		{"echo `date` now", "``echo `date` now``"},
+		{"echo `x`", "`` echo `x` ``"},
+		{"a``b", "```a``b```"},
+	}
+
+	for _, test := range tests {
+		if got := codeSpan(test.in); got != test.out {
+			t.Errorf("Code '%s': expected '%s', got '%s'", test.in, test.out, got)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tasks := []taskmeta.Task{
+		{Name: "lint", Backend: taskmeta.BackendNPM, Project: "web"},
+		{Name: "test", Desc: "Run the tests", Cmds: []string{"go test ./..."}, Deps: []string{"build"}},
+		{Name: "db:migrate", Desc: "Migrate | db", Cmds: []string{"a", "b"}},
+		{Name: "build", Desc: "Build it", Cmds: []string{"go build ./..."}},
+	}
+	want := "# Tasks\n\n" +
+		"<!-- Generated by `taskg export md`; edit the Taskfile instead. -->\n" +
+		"\n| Task | Description | Commands | Dependencies |\n|---|---|---|---|\n" +
+		"| `build` | Build it | `go build ./...` |  |\n" +
+		"| `test` | Run the tests | `go test ./...` | `build` |\n" +
+		"\n## db\n" +
+		"\n| Task | Description | Commands | Dependencies |\n|---|---|---|---|\n" +
+		"| `db:migrate` | Migrate \\| db | `a`<br>`b` |  |\n" +
+		"\n## web / npm\n" +
+		"\n| Task | Description | Commands | Dependencies |\n|---|---|---|---|\n" +
+		"| `web/lint` |  |  |  |\n"
+
+	if got := string(Markdown(tasks, "Tasks")); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+	if got := string(Markdown(nil, "Empty")); !strings.HasPrefix(got, "# Empty\n") || strings.Contains(got, "| Task |") {
+		t.Errorf("Expected only the heading for no tasks, got:\n%s", got)
+	}
+}
@@ -0,0 +1,283 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"taskg/internal/taskmeta"
+)
+
+// vscodeTask is an entry of .vscode/tasks.json.
+type vscodeTask struct {
+	Label          string         `json:"label"`
+	Type           string         `json:"type"`
+	Command        string         `json:"command"`
+	Args           []string       `json:"args,omitempty"`
+	Options        *vscodeOptions `json:"options,omitempty"`
+	Detail         string         `json:"detail,omitempty"`
+	Group          string         `json:"group,omitempty"`
+	ProblemMatcher []string       `json:"problemMatcher"`
+}
+
+type vscodeOptions struct {
+	Cwd string `json:"cwd"`
+}
+
+// vscodeInput prompts for a usage variable when the task starts, or lets
+// the user pick one of its choices.
+type vscodeInput struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Default     string   `json:"default,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// VSCodeLabel is the label a task gets in tasks.json. It only depends on the
+// backend, subproject and name, so launch.json and keybindings can rely on it
+// across regenerations.
+func VSCodeLabel(t taskmeta.Task) string {
+	backend := t.Backend
+	if backend == "" {
+		backend = taskmeta.BackendTask
+	}
+	return backend + ": " + label(t)
+}
+
+// vscodeEntries converts tasks into tasks.json entries and the inputs their
+// usage variables are asked with.
+func vscodeEntries(tasks []taskmeta.Task, root string) ([]vscodeTask, []vscodeInput) {
+	var entries []vscodeTask
+	var inputs []vscodeInput
+	for _, t := range tasks {
+		lbl := VSCodeLabel(t)
+		var args []string
+		vars, _ := t.UsageVars()
+		for _, v := range vars {
+			id := label(t) + "." + v.Name
+			args = append(args, fmt.Sprintf("%s=${input:%s}", v.Name, id))
+			in := vscodeInput{ID: id, Type: "promptString", Description: fmt.Sprintf("%s for %s", v.Name, lbl), Default: v.Default}
+			if len(v.Choices) > 0 {
+				in.Type, in.Options = "pickString", v.Choices
+			}
+			inputs = append(inputs, in)
+		}
+		bin, argv, dir := invocation(t, root, args)
+		e := vscodeTask{
+			Label:          lbl,
+			Type:           "process",
+			Command:        bin,
+			Args:           argv,
+			Detail:         t.Desc,
+			ProblemMatcher: []string{},
+		}
+		if dir != "." {
+			e.Options = &vscodeOptions{Cwd: path.Join("${workspaceFolder}", dir)}
+		}
+		// VS Code runs these with its build and test shortcuts.
+		switch t.Name {
+		case "build", "test":
+			e.Group = t.Name
+		}
+		entries = append(entries, e)
+	}
+	return entries, inputs
+}
+
+// VSCodeTasks renders tasks as a .vscode/tasks.json for root. existing is
+// the current file, if any: its entries are kept in place, generated
+// entries replace the fields taskg sets in entries with the same label
+// (other fields such as presentation or dependsOn stay), and tasks that are
+// new are appended. Comments in existing are not kept.
+func VSCodeTasks(tasks []taskmeta.Task, root string, existing []byte) ([]byte, error) {
+	entries, inputs := vscodeEntries(tasks, root)
+	doc := map[string]any{}
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := json.Unmarshal(stripJSONC(existing), &doc); err != nil {
+			return nil, fmt.Errorf("existing tasks.json: %w", err)
+		}
+	}
+	doc["version"] = "2.0.0"
+	var err error
+	if doc["tasks"], err = mergeByKey(doc["tasks"], entries, "label", func(e vscodeTask) string { return e.Label }); err != nil {
+		return nil, err
+	}
+	if doc["inputs"], err = mergeByKey(doc["inputs"], inputs, "id", func(in vscodeInput) string { return in.ID }); err != nil {
+		return nil, err
+	}
+	if list, _ := doc["inputs"].([]any); len(list) == 0 {
+		delete(doc, "inputs")
+	}
+	data, err := json.Marshal(ordered(doc))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// vscodeKeyOrder lists the keys written first, in this order, so the file
+// reads like a hand-written one; other keys follow alphabetically.
+var vscodeKeyOrder = []string{
+	"version", "label", "id", "type", "command", "args", "options", "detail",
+	"description", "default", "group", "problemMatcher", "tasks", "inputs",
+}
+
+// orderedObject is a JSON object written in vscodeKeyOrder.
+type orderedObject map[string]any
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	rank := func(k string) int {
+		if i := slices.Index(vscodeKeyOrder, k); i >= 0 {
+			return i
+		}
+		return len(vscodeKeyOrder)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if d := rank(a) - rank(b); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(o[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// ordered wraps the objects in v, a decoded JSON value, as orderedObjects.
+func ordered(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		o := orderedObject{}
+		for k, item := range v {
+			o[k] = ordered(item)
+		}
+		return o
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = ordered(item)
+		}
+		return out
+	}
+	return v
+}
+
+// mergeByKey merges generated items into the existing JSON list old,
+// matching them by the key field.
+func mergeByKey[T any](old any, generated []T, key string, keyOf func(T) string) ([]any, error) {
+	list, _ := old.([]any)
+	index := map[string]int{}
+	for i, item := range list {
+		if obj, ok := item.(map[string]any); ok {
+			if k, ok := obj[key].(string); ok {
+				index[k] = i
+			}
+		}
+	}
+	for _, g := range generated {
+		var fields map[string]any
+		data, err := json.Marshal(g)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		i, ok := index[keyOf(g)]
+		if !ok {
+			list = append(list, fields)
+			continue
+		}
+		obj := list[i].(map[string]any)
+		// Fields taskg leaves out this time, e.g. options of a task now
+		// run from the root, must not linger.
+		for _, f := range []string{"args", "options", "detail"} {
+			if _, set := fields[f]; !set {
+				delete(obj, f)
+			}
+		}
+		for f, v := range fields {
+			if f == "group" || f == "problemMatcher" {
+				if _, set := obj[f]; set {
+					continue // the user's choice wins
+				}
+			}
+			obj[f] = v
+		}
+	}
+	return list, nil
+}
+
+// stripJSONC removes the comments and trailing commas VS Code allows in
+// its JSON files.
+func stripJSONC(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ']' || c == '}':
+			// Drop a comma directly before the closing bracket.
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
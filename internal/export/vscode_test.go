@@ -0,0 +1,180 @@
+package export
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"taskg/internal/taskmeta"
+)
+
+func TestVSCodeLabel(t *testing.T) {
+	tests := []struct {
+		task  taskmeta.Task
+		label string
+	}{
+		{taskmeta.Task{Name: "build"}, "task: build"},
+		{taskmeta.Task{Name: "build", Backend: taskmeta.BackendTask}, "task: build"},
+		{taskmeta.Task{Name: "db:migrate", Project: "."}, "task: db:migrate"},
+		{taskmeta.Task{Name: "lint", Backend: taskmeta.BackendNPM, Project: "web"}, "npm: web/lint"},
+	}
+
+	for _, test := range tests {
+		if got := VSCodeLabel(test.task); got != test.label {
+			t.Errorf("Task %+v: expected label '%s', got '%s'", test.task, test.label, got)
+		}
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"{\"a\": 1, // note\n}", "{\"a\": 1 \n}"},
+		{`{"a": /* x */ 1}`, `{"a":  1}`},
+		{`{"a": [1, 2,], }`, `{"a": [1, 2] }`},
+		{`{"a": "// not a comment,}"}`, `{"a": "// not a comment,}"}`},
+		{`{"a": "say \"/*\""}`, `{"a": "say \"/*\""}`},
+		{`{"a": 1 /* open`, `{"a": 1 `},
+	}
+
+	for _, test := range tests {
+		if got := string(stripJSONC([]byte(test.in))); got != test.out {
+			t.Errorf("Input '%s': expected '%s', got '%s'", test.in, test.out, got)
+		}
+	}
+}
+
+// vscodeDoc is the part of a tasks.json the tests look at.
+type vscodeDoc struct {
+	Version string           `json:"version"`
+	Tasks   []map[string]any `json:"tasks"`
+	Inputs  []map[string]any `json:"inputs"`
+}
+
+func decodeVSCode(t *testing.T, data []byte) vscodeDoc {
+	t.Helper()
+	var doc vscodeDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Generated tasks.json does not parse: %v\n%s", err, data)
+	}
+	return doc
+}
+
+func TestVSCodeTasks(t *testing.T) {
+	tasks := []taskmeta.Task{
+		{Name: "build", Desc: "Build it", Backend: taskmeta.BackendTask},
+		{Name: "deploy", Backend: taskmeta.BackendTask, Choices: map[string][]string{"ENV": {"dev", "prod"}}},
+		{Name: "test", Backend: taskmeta.BackendNPM, Project: "web", ProjectDir: "/r/web"},
+	}
+	data, err := VSCodeTasks(tasks, "/r", nil)
+	if err != nil {
+		t.Fatalf("VSCodeTasks: %v", err)
+	}
+	doc := decodeVSCode(t, data)
+	if doc.Version != "2.0.0" || len(doc.Tasks) != len(tasks) {
+		t.Fatalf("Expected version 2.0.0 and %d tasks, got %s and %d", len(tasks), doc.Version, len(doc.Tasks))
+	}
+
+	tests := []struct {
+		label   string
+		command string
+		args    []string
+		cwd     string
+		group   string
+	}{
+		{"task: build", "task", []string{"build"}, "", "build"},
+		{"task: deploy", "task", []string{"deploy", "ENV=${input:deploy.ENV}"}, "", ""},
+		{"npm: web/test", "npm", []string{"run", "test"}, "${workspaceFolder}/web", "test"},
+	}
+
+	for i, test := range tests {
+		e := doc.Tasks[i]
+		var args []string
+		for _, a := range e["args"].([]any) {
+			args = append(args, a.(string))
+		}
+		var cwd string
+		if opts, ok := e["options"].(map[string]any); ok {
+			cwd, _ = opts["cwd"].(string)
+		}
+		group, _ := e["group"].(string)
+		if e["label"] != test.label || e["command"] != test.command || !slices.Equal(args, test.args) || cwd != test.cwd || group != test.group {
+			t.Errorf("Task %d: expected %s %s %v (cwd '%s', group '%s'), got %v", i, test.label, test.command, test.args, test.cwd, test.group, e)
+		}
+	}
+
+	if len(doc.Inputs) != 1 || doc.Inputs[0]["id"] != "deploy.ENV" || doc.Inputs[0]["type"] != "pickString" || doc.Inputs[0]["default"] != "dev" {
+		t.Errorf("Expected a pickString input deploy.ENV defaulting to dev, got %v", doc.Inputs)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"version\": \"2.0.0\",\n  \"tasks\": [\n    {\n      \"label\": \"task: build\",") {
+		t.Errorf("Expected version, tasks and label first, got:\n%s", data)
+	}
+}
+
+func TestVSCodeTasksMerge(t *testing.T) {
+	existing := `{
+  // Edited by hand.
+  "version": "2.0.0",
+  "tasks": [
+    {
+      "label": "mine",
+      "type": "shell",
+      "command": "make",
+    },
+    {
+      "label": "task: build",
+      "type": "process",
+      "command": "task",
+      "args": ["build", "--old"],
+      "detail": "Old description",
+      "group": {"kind": "build", "isDefault": true},
+      "presentation": {"reveal": "silent"},
+      "problemMatcher": ["$go"],
+    },
+  ],
+}`
+	tasks := []taskmeta.Task{
+		{Name: "build", Backend: taskmeta.BackendTask},
+		{Name: "lint", Backend: taskmeta.BackendTask},
+	}
+	data, err := VSCodeTasks(tasks, "/r", []byte(existing))
+	if err != nil {
+		t.Fatalf("VSCodeTasks: %v", err)
+	}
+	doc := decodeVSCode(t, data)
+
+	var labels []string
+	for _, e := range doc.Tasks {
+		labels = append(labels, e["label"].(string))
+	}
+	if want := []string{"mine", "task: build", "task: lint"}; !slices.Equal(labels, want) {
+		t.Fatalf("Expected tasks %v, got %v", want, labels)
+	}
+
+	build := doc.Tasks[1]
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"args", `["build"]`},
+		{"detail", `null`},
+		{"group", `{"isDefault":true,"kind":"build"}`},
+		{"presentation", `{"reveal":"silent"}`},
+		{"problemMatcher", `["$go"]`},
+	}
+
+	for _, test := range tests {
+		got, _ := json.Marshal(build[test.key])
+		if string(got) != test.want {
+			t.Errorf("Key '%s': expected %s, got %s", test.key, test.want, got)
+		}
+	}
+
+	if _, err := VSCodeTasks(tasks, "/r", []byte(`{"tasks": [`)); err == nil {
+		t.Errorf("Expected an error for a tasks.json that does not parse")
+	}
+}
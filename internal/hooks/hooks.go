@@ -0,0 +1,156 @@
+// Package hooks tells webhooks about finished runs, so that e.g. a Slack or
+// Discord channel hears when a long deploy launched from taskg is done.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"taskg/internal/config"
+)
+
+// Timeout bounds each webhook request.
+const Timeout = 10 * time.Second
+
+// Run is a finished run of a task.
+type Run struct {
+	Task     string
+	Args     []string
+	Project  string // project name, e.g. the base name of its root
+	Dir      string // directory the task ran in
+	Started  time.Time
+	Duration time.Duration
+	ExitCode int
+}
+
+// payload is the JSON body posted to the hooks. Text and Content carry a
+// one-line summary in the fields Slack and Discord display.
+type payload struct {
+	Task     string    `json:"task"`
+	Args     []string  `json:"args,omitempty"`
+	Project  string    `json:"project"`
+	Dir      string    `json:"dir"`
+	Started  time.Time `json:"started"`
+	Duration float64   `json:"duration_seconds"`
+	ExitCode int       `json:"exit_code"`
+	Status   string    `json:"status"`
+	Text     string    `json:"text"`
+	Content  string    `json:"content"`
+}
+
+func (r Run) status() string {
+	if r.ExitCode == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+func (r Run) summary() string {
+	name := r.Task
+	if len(r.Args) > 0 {
+		name += " " + strings.Join(r.Args, " ")
+	}
+	d := r.Duration.Round(time.Second)
+	if r.Duration < time.Second {
+		d = r.Duration.Round(time.Millisecond)
+	}
+	if r.ExitCode == 0 {
+		return fmt.Sprintf("✅ %s in %s finished in %s", name, r.Project, d)
+	}
+	return fmt.Sprintf("❌ %s in %s failed with exit code %d after %s", name, r.Project, r.ExitCode, d)
+}
+
+func (r Run) payload() payload {
+	text := r.summary()
+	return payload{
+		Task:     r.Task,
+		Args:     r.Args,
+		Project:  r.Project,
+		Dir:      r.Dir,
+		Started:  r.Started,
+		Duration: r.Duration.Seconds(),
+		ExitCode: r.ExitCode,
+		Status:   r.status(),
+		Text:     text,
+		Content:  text,
+	}
+}
+
+// wants reports whether h is called for r.
+func wants(h config.Hook, r Run) bool {
+	if len(h.Tasks) > 0 && !config.MatchAny(h.Tasks, r.Task) {
+		return false
+	}
+	if r.Duration < h.MinDuration {
+		return false
+	}
+	switch h.On {
+	case config.HookSuccess:
+		return r.ExitCode == 0
+	case config.HookFailure:
+		return r.ExitCode != 0
+	}
+	return true
+}
+
+// Wanted reports whether any of hooks is called for r, so callers can skip
+// starting a notification that would do nothing.
+func Wanted(hooks []config.Hook, r Run) bool {
+	for _, h := range hooks {
+		if wants(h, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify posts r to the hooks that want it and returns their errors.
+func Notify(ctx context.Context, hooks []config.Hook, r Run) error {
+	body, err := json.Marshal(r.payload())
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: Timeout}
+	var errs []error
+	for _, h := range hooks {
+		if !wants(h, r) {
+			continue
+		}
+		if err := post(ctx, client, h.URL, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends body to target. Errors only name the host: webhook URLs
+// usually carry their secret in the path.
+func post(ctx context.Context, client *http.Client, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
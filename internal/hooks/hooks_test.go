@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"taskg/internal/config"
+)
+
+func TestWants(t *testing.T) {
+	tests := []struct {
+		hook     config.Hook
+		run      Run
+		expected bool
+	}{
+		{config.Hook{}, Run{Task: "build", ExitCode: 1}, true},
+		{config.Hook{On: config.HookSuccess}, Run{Task: "build"}, true},
+		{config.Hook{On: config.HookSuccess}, Run{Task: "build", ExitCode: 2}, false},
+		{config.Hook{On: config.HookFailure}, Run{Task: "build", ExitCode: 2}, true},
+		{config.Hook{On: config.HookFailure}, Run{Task: "build"}, false},
+		{config.Hook{Tasks: []string{"deploy*"}}, Run{Task: "deploy-prod"}, true},
+		{config.Hook{Tasks: []string{"deploy*"}}, Run{Task: "build"}, false},
+		{config.Hook{MinDuration: time.Minute}, Run{Task: "build", Duration: 30 * time.Second}, false},
+		{config.Hook{MinDuration: time.Minute}, Run{Task: "build", Duration: time.Minute}, true},
+		{config.Hook{Tasks: []string{"deploy*"}, On: config.HookFailure, MinDuration: time.Minute}, Run{Task: "deploy-prod", ExitCode: 1, Duration: time.Hour}, true},
+	}
+
+	for _, test := range tests {
+		if got := wants(test.hook, test.run); got != test.expected {
+			t.Errorf("Hook %+v for %+v: expected %v, got %v", test.hook, test.run, test.expected, got)
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var got []payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Webhook body: %v", err)
+		}
+		got = append(got, p)
+	}))
+	defer srv.Close()
+
+	hooks := []config.Hook{
+		{URL: srv.URL + "/all"},
+		{URL: srv.URL + "/failures", On: config.HookFailure},
+	}
+	tests := []struct {
+		run      Run
+		expected int
+		status   string
+	}{
+		{Run{Task: "build", Project: "app", Duration: time.Second}, 1, "success"},
+		{Run{Task: "build", Project: "app", ExitCode: 3, Duration: time.Second}, 2, "failure"},
+	}
+
+	for _, test := range tests {
+		got = nil
+		if err := Notify(context.Background(), hooks, test.run); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != test.expected {
+			t.Errorf("Run %+v: expected %d webhook calls, got %d", test.run, test.expected, len(got))
+			continue
+		}
+		if got[0].Status != test.status || got[0].Text == "" || got[0].Text != got[0].Content {
+			t.Errorf("Run %+v: unexpected payload %+v", test.run, got[0])
+		}
+	}
+}
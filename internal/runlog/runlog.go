@@ -0,0 +1,179 @@
+// Package runlog keeps the output of runs in log files under .taskg/logs,
+// one file per run, and prunes old logs by age and total size.
+package runlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"taskg/internal/state"
+)
+
+// timeLayout starts every log file name; it has a fixed width so names sort
+// by start time.
+const timeLayout = "20060102T150405.000000"
+
+// marker starts the header and footer lines taskg adds around the output.
+const marker = "# taskg: "
+
+// Dir returns the log directory of the project at root.
+func Dir(root string) string { return filepath.Join(root, state.DirName, "logs") }
+
+// fileName names the log of a run of the task with the given key. The key
+// is escaped so that every key maps to its own, portable file name.
+func fileName(key string, start time.Time) string {
+	return start.UTC().Format(timeLayout) + "-" + url.QueryEscape(key) + ".log"
+}
+
+// Writer writes one run's log. Lines go straight to the file, so the log is
+// complete up to the last line even when taskg is killed.
+type Writer struct {
+	f     *os.File
+	start time.Time
+}
+
+// Create starts the log of a run of the task with the given key; title is
+// the command line, recorded in the header.
+func Create(root, key, title string, start time.Time) (*Writer, error) {
+	dir := Dir(root)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, fileName(key, start)), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{f: f, start: start}
+	w.Line(marker + title + " (started " + start.Format(time.RFC3339) + ")")
+	return w, nil
+}
+
+// Line appends a line of output.
+func (w *Writer) Line(s string) {
+	_, _ = w.f.WriteString(s + "\n")
+}
+
+// Close records the exit code and duration and closes the file.
+func (w *Writer) Close(exitCode int, end time.Time) error {
+	w.Line(fmt.Sprintf("%sexit %d after %s", marker, exitCode, end.Sub(w.start).Round(time.Millisecond)))
+	return w.f.Close()
+}
+
+// Entry is a log file.
+type Entry struct {
+	Path    string
+	Key     string
+	Started time.Time
+	Size    int64
+}
+
+// List returns the logs of the project at root, newest first. With a key
+// only that task's logs are listed.
+func List(root, key string) ([]Entry, error) {
+	files, err := os.ReadDir(Dir(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, f := range files {
+		e, ok := parseName(f.Name())
+		if !ok || (key != "" && e.Key != key) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		e.Path = filepath.Join(Dir(root), f.Name())
+		e.Size = info.Size()
+		out = append(out, e)
+	}
+	slices.SortFunc(out, func(a, b Entry) int { return b.Started.Compare(a.Started) })
+	return out, nil
+}
+
+func parseName(name string) (Entry, bool) {
+	rest, ok := strings.CutSuffix(name, ".log")
+	if !ok || len(rest) < len(timeLayout)+2 || rest[len(timeLayout)] != '-' {
+		return Entry{}, false
+	}
+	start, err := time.Parse(timeLayout, rest[:len(timeLayout)])
+	if err != nil {
+		return Entry{}, false
+	}
+	key, err := url.QueryUnescape(rest[len(timeLayout)+1:])
+	if err != nil {
+		return Entry{}, false
+	}
+	return Entry{Key: key, Started: start}, true
+}
+
+// Output returns the output lines recorded in the log at path, without the
+// header and footer taskg adds.
+func Output(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4<<20)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 && strings.HasPrefix(lines[0], marker) {
+		lines = lines[1:]
+	}
+	if n := len(lines); n > 0 && strings.HasPrefix(lines[n-1], marker+"exit ") {
+		lines = lines[:n-1]
+	}
+	return lines, nil
+}
+
+// Retention bounds the logs kept per project.
+type Retention struct {
+	// MaxAge removes logs of runs started longer ago. Zero keeps them.
+	MaxAge time.Duration
+	// MaxSize removes the oldest logs while all of them together are
+	// larger, in bytes. Zero means no limit.
+	MaxSize int64
+}
+
+// Prune removes the logs of the project at root that r does not keep. The
+// newest log is always kept, as it may belong to a run still going.
+func Prune(root string, r Retention, now time.Time) error {
+	logs, err := List(root, "")
+	if err != nil || len(logs) < 2 {
+		return err
+	}
+	var total int64
+	var errs []error
+	for i, e := range logs {
+		total += e.Size
+		if i == 0 {
+			continue
+		}
+		tooOld := r.MaxAge > 0 && now.Sub(e.Started) > r.MaxAge
+		tooBig := r.MaxSize > 0 && total > r.MaxSize
+		if tooOld || tooBig {
+			if err := os.Remove(e.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package runner
+
+// Buffer is a bounded ring buffer of output lines. Once the limit is reached
+// the oldest lines are evicted so long-running tasks cannot grow memory
+// without bound.
+type Buffer struct {
+	lines   []string
+	start   int // index of the oldest retained line in lines
+	count   int
+	limit   int
+	dropped int // number of lines evicted so far
+}
+
+// DefaultScrollback is the number of lines kept when no limit is configured.
+const DefaultScrollback = 10000
+
+// NewBuffer returns a buffer keeping at most limit lines (DefaultScrollback if limit <= 0).
+func NewBuffer(limit int) *Buffer {
+	if limit <= 0 {
+		limit = DefaultScrollback
+	}
+	return &Buffer{limit: limit}
+}
+
+// Append adds a line, evicting the oldest one when the buffer is full.
+func (b *Buffer) Append(line string) {
+	if b.count < b.limit {
+		if len(b.lines) < b.limit {
+			b.lines = append(b.lines, line)
+		} else {
+			b.lines[(b.start+b.count)%b.limit] = line
+		}
+		b.count++
+		return
+	}
+	b.lines[b.start] = line
+	b.start = (b.start + 1) % b.limit
+	b.dropped++
+}
+
+// ReplaceLast overwrites the newest line, appending if the buffer is empty.
+// It is used to update a line that was still being written (see Event.Partial).
+func (b *Buffer) ReplaceLast(line string) {
+	if b.count == 0 {
+		b.Append(line)
+		return
+	}
+	b.lines[(b.start+b.count-1)%len(b.lines)] = line
+}
+
+// Replace overwrites the line with absolute index abs (see Dropped), as
+// ReplaceLast does for the newest one. It reports false when that line was
+// evicted or not written yet.
+func (b *Buffer) Replace(abs int, line string) bool {
+	i := abs - b.dropped
+	if i < 0 || i >= b.count {
+		return false
+	}
+	b.lines[(b.start+i)%len(b.lines)] = line
+	return true
+}
+
+// Len returns the number of retained lines.
+func (b *Buffer) Len() int { return b.count }
+
+// Line returns the i-th retained line, 0 being the oldest.
+func (b *Buffer) Line(i int) string {
+	return b.lines[(b.start+i)%len(b.lines)]
+}
+
+// Dropped returns how many lines have been evicted. Dropped()+i is the absolute
+// index of Line(i) since the buffer was created, which stays stable as lines scroll out.
+func (b *Buffer) Dropped() int { return b.dropped }
+
+// Lines returns a copy of the retained lines, oldest first.
+func (b *Buffer) Lines() []string {
+	out := make([]string, b.count)
+	for i := range out {
+		out[i] = b.Line(i)
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	tests := []struct {
+		limit   int
+		appends []string
+		lines   []string
+		dropped int
+	}{
+		{3, nil, []string{}, 0},
+		{3, []string{"a", "b"}, []string{"a", "b"}, 0},
+		{3, []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{3, []string{"a", "b", "c", "d"}, []string{"b", "c", "d"}, 1},
+		{3, []string{"a", "b", "c", "d", "e", "f", "g"}, []string{"e", "f", "g"}, 4},
+		{1, []string{"a", "b"}, []string{"b"}, 1},
+	}
+
+	for _, test := range tests {
+		b := NewBuffer(test.limit)
+		for _, l := range test.appends {
+			b.Append(l)
+		}
+		if got := b.Lines(); !slices.Equal(got, test.lines) || b.Len() != len(test.lines) || b.Dropped() != test.dropped {
+			t.Errorf("Appends %v to %d: expected %v (%d dropped), got %v (%d dropped)", test.appends, test.limit, test.lines, test.dropped, got, b.Dropped())
+		}
+	}
+
+	if b := NewBuffer(0); b.limit != DefaultScrollback {
+		t.Errorf("NewBuffer(0): expected limit %d, got %d", DefaultScrollback, b.limit)
+	}
+}
+
+func TestBufferReplace(t *testing.T) {
+	tests := []struct {
+		abs      int
+		ok       bool
+		expected []string
+	}{
+		{0, false, []string{"b", "c", "d"}}, // evicted
+		{1, true, []string{"x", "c", "d"}},
+		{3, true, []string{"b", "c", "x"}},
+		{4, false, []string{"b", "c", "d"}}, // not written yet
+	}
+
+	for _, test := range tests {
+		b := NewBuffer(3)
+		for _, l := range []string{"a", "b", "c", "d"} {
+			b.Append(l)
+		}
+		if ok := b.Replace(test.abs, "x"); ok != test.ok || !slices.Equal(b.Lines(), test.expected) {
+			t.Errorf("Replace(%d): expected %v %v, got %v %v", test.abs, test.ok, test.expected, ok, b.Lines())
+		}
+	}
+
+	b := NewBuffer(3)
+	b.ReplaceLast("a")
+	b.Append("b")
+	b.ReplaceLast("c")
+	if !slices.Equal(b.Lines(), []string{"a", "c"}) {
+		t.Errorf("ReplaceLast: expected [a c], got %v", b.Lines())
+	}
+}
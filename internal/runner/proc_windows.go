@@ -0,0 +1,29 @@
+//go:build windows
+
+package runner
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts the child in a new process group so console control
+// events aimed at taskg do not reach it and the tree can be killed as a unit.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// terminateGroup kills the child and all of its descendants. Windows has no
+// graceful group signal for non-console children, so this is the same as killGroup.
+func terminateGroup(cmd *exec.Cmd) error {
+	return killGroup(cmd)
+}
+
+// killGroup forcibly kills the child's process tree.
+func killGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
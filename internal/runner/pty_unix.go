@@ -0,0 +1,46 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+type ptyFile = *os.File
+
+// PTYSupported reports whether StartPTY allocates a real pseudo-terminal.
+const PTYSupported = true
+
+// ptyDrainDelay is how long output is still read from the terminal after the
+// task exits before it is closed (background children may keep it open).
+const ptyDrainDelay = 250 * time.Millisecond
+
+// StartPTY is like Start but runs the task attached to a pseudo-terminal of
+// the given size, so programs that prompt, draw progress bars or check isatty
+// behave as in a normal terminal. Input is forwarded with Write.
+func StartPTY(dir, bin string, args, env []string, cols, rows int) (*Run, error) {
+	cmd := newCommand(dir, bin, args, env)
+	// pty.Start makes the child a session leader, which also makes it the
+	// leader of its own process group, so group cancellation keeps working.
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+	r := newRun(cmd)
+	r.input = f
+	r.pty = f
+	go r.wait(f, func() {
+		time.AfterFunc(ptyDrainDelay, func() { _ = f.Close() })
+	})
+	return r, nil
+}
+
+// Resize updates the terminal size of an interactive run.
+func (r *Run) Resize(cols, rows int) {
+	if r.pty != nil {
+		_ = pty.Setsize(r.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	}
+}
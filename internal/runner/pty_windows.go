@@ -0,0 +1,18 @@
+//go:build windows
+
+package runner
+
+import "os"
+
+type ptyFile = *os.File
+
+// PTYSupported reports whether StartPTY allocates a real pseudo-terminal.
+const PTYSupported = false
+
+// StartPTY falls back to plain pipes on Windows.
+func StartPTY(dir, bin string, args, env []string, cols, rows int) (*Run, error) {
+	return Start(dir, bin, args, env)
+}
+
+// Resize is a no-op without a pseudo-terminal.
+func (r *Run) Resize(cols, rows int) {}
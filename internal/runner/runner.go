@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Event is emitted by a Run: either one output line or, as the last event,
+// the completion with the process exit code.
+type Event struct {
+	Line string
+	// Partial marks a line that has not been terminated yet (e.g. a prompt
+	// waiting for input). The next event replaces it.
+	Partial  bool
+	Done     bool
+	ExitCode int
+	Err      error // start/wait error that is not a plain non-zero exit
+}
+
+// Run is a task process whose combined stdout/stderr is streamed line by line.
+type Run struct {
+	cmd    *exec.Cmd
+	events chan Event
+	exited chan struct{}
+	input  io.Writer // pty master for interactive runs, nil otherwise
+	pty    ptyFile
+
+	cancelOnce sync.Once
+}
+
+// KillGrace is how long Cancel waits after SIGTERM before sending SIGKILL.
+const KillGrace = 3 * time.Second
+
+// Start launches bin with args in dir. env, when non-empty, replaces the
+// process environment (callers typically pass os.Environ() plus overrides).
+func Start(dir, bin string, args, env []string) (*Run, error) {
+	cmd := newCommand(dir, bin, args, env)
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	r := newRun(cmd)
+	go r.wait(pr, func() { pw.Close() })
+	return r, nil
+}
+
+func newCommand(dir, bin string, args, env []string) *exec.Cmd {
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = env
+	}
+	return cmd
+}
+
+func newRun(cmd *exec.Cmd) *Run {
+	return &Run{cmd: cmd, events: make(chan Event, 256), exited: make(chan struct{})}
+}
+
+// wait streams out until EOF and emits the final Done event once the process
+// has exited. closeOut is called after exit to unblock the reader.
+func (r *Run) wait(out io.Reader, closeOut func()) {
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		r.stream(out)
+	}()
+
+	err := r.cmd.Wait()
+	close(r.exited)
+	closeOut()
+	<-streamDone
+
+	ev := Event{Done: true}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		ev.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		ev.ExitCode = -1
+		ev.Err = err
+	}
+	r.events <- ev
+	close(r.events)
+}
+
+// maxLine bounds how much of an unterminated line is buffered before it is
+// flushed as a line of its own.
+const maxLine = 64 * 1024
+
+// stream splits out into lines. Whatever is left after a read without a
+// trailing newline is emitted as a Partial event so prompts show up immediately.
+func (r *Run) stream(out io.Reader) {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := out.Read(buf)
+		pending = append(pending, buf[:n]...)
+		for {
+			i := bytes.IndexByte(pending, '\n')
+			if i == -1 {
+				break
+			}
+			r.events <- Event{Line: cleanLine(string(pending[:i]))}
+			pending = pending[i+1:]
+		}
+		if len(pending) > maxLine {
+			r.events <- Event{Line: cleanLine(string(pending))}
+			pending = nil
+		}
+		if err != nil {
+			if len(pending) > 0 {
+				r.events <- Event{Line: cleanLine(string(pending))}
+			}
+			return
+		}
+		if len(pending) > 0 && n > 0 {
+			r.events <- Event{Line: cleanLine(string(pending)), Partial: true}
+		}
+	}
+}
+
+// Events returns the channel of output lines followed by a final Done event.
+func (r *Run) Events() <-chan Event { return r.events }
+
+// Interactive reports whether the run has a terminal that accepts input.
+func (r *Run) Interactive() bool { return r.input != nil }
+
+// Write forwards keystrokes to an interactive run. It is a no-op for runs
+// without a pseudo-terminal.
+func (r *Run) Write(p []byte) (int, error) {
+	if r.input == nil {
+		return len(p), nil
+	}
+	return r.input.Write(p)
+}
+
+// Cancel stops the task by signalling its whole process group, not only the
+// direct child, so shell pipelines and watchers stop too. Processes still
+// alive after KillGrace are killed. Cancel does not wait for the exit; the
+// Done event reports it as usual.
+func (r *Run) Cancel() {
+	r.cancelOnce.Do(func() {
+		_ = terminateGroup(r.cmd)
+		go func() {
+			select {
+			case <-r.exited:
+			case <-time.After(KillGrace):
+				_ = killGroup(r.cmd)
+			}
+		}()
+	})
+}
+
+// StopWait is how long to wait for cancelled runs before exiting: long
+// enough for Cancel to kill those still alive after KillGrace.
+const StopWait = KillGrace + time.Second
+
+// Wait discards the events left until the Done event, so a process blocked
+// on writing output nobody reads any more can exit, and reports whether it
+// ended before deadline. Events read elsewhere meanwhile go to either reader.
+func (r *Run) Wait(deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case ev, ok := <-r.events:
+			if !ok || ev.Done {
+				return true
+			}
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// cleanLine strips ANSI escape sequences and keeps only the text after the last
+// carriage return, which is what a terminal would show for progress output.
+func cleanLine(s string) string {
+	s = strings.TrimRight(s, "\r")
+	if i := strings.LastIndex(s, "\r"); i != -1 {
+		s = s[i+1:]
+	}
+	return ansi.Strip(s)
+}
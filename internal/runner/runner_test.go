@@ -0,0 +1,122 @@
+package runner
+
+import (
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCancelWait(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+
+	tests := []struct {
+		name   string
+		script string
+	}{
+		// Output nobody reads fills the events channel and blocks the task.
+		{"unread output", "while :; do echo spam; done"},
+		// Only the kill after KillGrace stops it.
+		{"ignores SIGTERM", "trap '' TERM; while :; do sleep 0.1; done"},
+	}
+
+	for _, test := range tests {
+		r, err := Start(t.TempDir(), "sh", []string{"-c", test.script}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(200 * time.Millisecond)
+		r.Cancel()
+		if !r.Wait(time.Now().Add(StopWait)) {
+			t.Errorf("Run '%s': still running %s after Cancel", test.name, StopWait)
+		}
+	}
+}
+
+func TestCleanLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"crlf\r", "crlf"},
+		{"\x1b[32mgreen\x1b[0m", "green"},
+		{"10%\r50%\r100%", "100%"},
+		{"progress\r\x1b[1mdone\x1b[0m\r", "done"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := cleanLine(test.input); got != test.expected {
+			t.Errorf("Line %q: expected %q, got %q", test.input, test.expected, got)
+		}
+	}
+}
+
+// chunkReader returns one chunk per Read, as a pipe does for separate writes.
+type chunkReader struct {
+	chunks []string
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks[0] = c.chunks[0][n:]
+	if c.chunks[0] == "" {
+		c.chunks = c.chunks[1:]
+	}
+	return n, nil
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		chunks   []string
+		expected []string // events, partial ones marked with a trailing "…"
+	}{
+		{[]string{"a\nb\n"}, []string{"a", "b"}},
+		{[]string{"Continue? "}, []string{"Continue? …", "Continue? "}},
+		{[]string{"Cont", "inue? ", "y\n"}, []string{"Cont…", "Continue? …", "Continue? y"}},
+		{[]string{"a\nb", "c\n"}, []string{"a", "b…", "bc"}},
+		{[]string{"\x1b[31mred\x1b[0m\n"}, []string{"red"}},
+		{[]string{"10%\r", "100%\n"}, []string{"10%…", "100%"}},
+	}
+
+	for _, test := range tests {
+		r := &Run{events: make(chan Event, 256)}
+		r.stream(&chunkReader{chunks: slices.Clone(test.chunks)})
+		close(r.events)
+		var got []string
+		for ev := range r.events {
+			if ev.Partial {
+				got = append(got, ev.Line+"…")
+			} else {
+				got = append(got, ev.Line)
+			}
+		}
+		if !slices.Equal(got, test.expected) {
+			t.Errorf("Chunks %q: expected %q, got %q", test.chunks, test.expected, got)
+		}
+	}
+}
+
+func TestStreamLongLine(t *testing.T) {
+	r := &Run{events: make(chan Event, 256)}
+	r.stream(&chunkReader{chunks: []string{strings.Repeat("x", maxLine+1), "y\n"}})
+	close(r.events)
+	var lines []int
+	for ev := range r.events {
+		if !ev.Partial {
+			lines = append(lines, len(ev.Line))
+		}
+	}
+	// The unterminated line is flushed once it outgrows maxLine.
+	if !slices.Equal(lines, []int{maxLine + 1, 1}) {
+		t.Errorf("Line of %d bytes: expected lines of %v bytes, got %v", maxLine+1, []int{maxLine + 1, 1}, lines)
+	}
+}
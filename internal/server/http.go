@@ -0,0 +1,169 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Handler returns the HTTP handler serving the REST API:
+//
+//	GET  /tasks            the project's tasks
+//	POST /run/{name}       start a task, returns the run; needs a JSON
+//	                       content type
+//	GET  /runs             runs still going and the last finished ones
+//	GET  /runs/{id}        one run
+//	GET  /runs/{id}/logs   the run's output as server-sent events
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", s.handleTasks)
+	mux.HandleFunc("POST /run/{name}", s.handleRun)
+	mux.HandleFunc("GET /runs", s.handleRuns)
+	mux.HandleFunc("GET /runs/{id}", s.handleRunStatus)
+	mux.HandleFunc("GET /runs/{id}/logs", s.handleLogs)
+	return s.guard(s.authorize(mux))
+}
+
+// guard refuses requests a web page could make on the user's behalf: ones
+// sent from another origin, and, without a token, ones naming a host other
+// than localhost or an IP address, as a DNS rebinding attack would.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, fmt.Errorf("cross-origin request from %s refused", origin))
+				return
+			}
+		}
+		if s.Token == "" && !directHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("request for host %q refused: set a token to serve host names other than localhost", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// directHost reports whether host, a Host header, is localhost or an IP
+// address, neither of which a DNS rebinding attack can point here.
+func directHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.EqualFold(host, "localhost") || net.ParseIP(host) != nil
+}
+
+// authorize rejects requests without the bearer token, when one is set.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "Bearer " + s.Token
+		if s.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := s.listTasks()
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleRun starts a task. The JSON body with args and confirm is optional,
+// but the JSON content type is not: browsers only send it cross-origin after
+// a preflight request, which the server does not answer, so a web page
+// cannot start tasks.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, errors.New(`send "Content-Type: application/json"`))
+		return
+	}
+	var req runRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+			return
+		}
+	}
+	req.Name = r.PathValue("name")
+	run, err := s.startRun(req)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run.status())
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.runStatuses())
+}
+
+func (s *Server) handleRunStatus(w http.ResponseWriter, r *http.Request) {
+	run, err := s.findRun(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run.status())
+}
+
+// handleLogs streams the output of a run as server-sent events: every line
+// kept so far, then new lines as they come, and a final "done" event with
+// the exit code.
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	run, err := s.findRun(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	next := 0
+	for {
+		lines, pos, done, changed := run.since(next)
+		next = pos
+		for _, l := range lines {
+			fmt.Fprintf(w, "data: %s\n\n", l)
+		}
+		if done {
+			data, _ := json.Marshal(run.status())
+			fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		token    string
+		host     string
+		origin   string
+		expected int
+	}{
+		{"", "localhost:8787", "", http.StatusOK},
+		{"", "127.0.0.1:8787", "", http.StatusOK},
+		{"", "[::1]:8787", "", http.StatusOK},
+		{"", "192.168.1.5:8787", "", http.StatusOK},
+		{"", "LOCALHOST", "", http.StatusOK},
+		{"", "attacker.example:8787", "", http.StatusForbidden}, // DNS rebinding
+		{"s3cret", "buildbox.example:8787", "", http.StatusOK},  // the token keeps rebinding out
+		{"", "localhost:8787", "http://localhost:8787", http.StatusOK},
+		{"", "localhost:8787", "https://attacker.example", http.StatusForbidden},
+		{"", "localhost:8787", "null", http.StatusForbidden},
+		{"s3cret", "localhost:8787", "https://attacker.example", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		s := &Server{Token: test.token}
+		r := httptest.NewRequest("GET", "/tasks", nil)
+		r.Host = test.host
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		w := httptest.NewRecorder()
+		s.guard(ok).ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("Host '%s', Origin '%s': expected %d, got %d", test.host, test.origin, test.expected, w.Code)
+		}
+	}
+}
+
+func TestHandleRunContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    int
+	}{
+		{"", http.StatusUnsupportedMediaType},
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"application/json", http.StatusNotFound},
+		{"application/json; charset=utf-8", http.StatusNotFound},
+	}
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Taskfile.yml"), []byte("version: '3'\ntasks:\n  build:\n    cmds: [echo build]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		s := New(root)
+		r := httptest.NewRequest("POST", "/run/missing", strings.NewReader(""))
+		r.Host = "localhost:8787"
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("Content-Type '%s': expected %d, got %d", test.contentType, test.expected, w.Code)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	// rpcServerError is used for failed calls, with the HTTP status the REST
+	// API would answer in the error data.
+	rpcServerError = -32000
+)
+
+// rpcRequest is a JSON-RPC request, or a notification when ID is missing
+// or null.
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// rpcConn writes messages framed like LSP's: a Content-Length header, a
+// blank line and the JSON body. Writes from concurrent calls are serialized.
+type rpcConn struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (c *rpcConn) send(msg map[string]any) {
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (c *rpcConn) reply(id json.RawMessage, result any) {
+	c.send(map[string]any{"id": id, "result": result})
+}
+
+func (c *rpcConn) fail(id json.RawMessage, rerr *rpcError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	c.send(map[string]any{"id": id, "error": rerr})
+}
+
+func (c *rpcConn) notify(method string, params any) {
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// ServeRPC speaks JSON-RPC 2.0 on in and out, framed as LSP does so editor
+// plugins can use their LSP client libraries. The methods are:
+//
+//	listTasks                       the project's tasks
+//	runTask {name, args, confirm}   start a task, returns the run
+//	streamOutput {id}               send the run's lines as "output"
+//	                                notifications, answering once it ends
+//	cancelRun {id}                  stop a run
+//	listRuns                        runs still going and the last finished ones
+//	shutdown, exit                  as in LSP
+//
+// Calls are handled concurrently, so a streamOutput does not hold up the
+// others. ServeRPC returns when in ends or after "exit"; callers should
+// then cancel the runs, which also ends the streams.
+func (s *Server) ServeRPC(in io.Reader, out io.Writer) error {
+	conn := &rpcConn{out: out}
+	r := textproto.NewReader(bufio.NewReader(in))
+	for {
+		body, err := readFrame(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			conn.fail(nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+		if string(req.ID) == "null" {
+			// A null id is no id: the call is a notification.
+			req.ID = nil
+		}
+		switch req.Method {
+		case "":
+			conn.fail(req.ID, &rpcError{Code: rpcInvalidRequest, Message: "missing method"})
+			continue
+		case "exit":
+			return nil
+		}
+		go func() {
+			result, rerr := s.call(conn, req)
+			if req.ID == nil {
+				return // notifications get no answer
+			}
+			if rerr != nil {
+				conn.fail(req.ID, rerr)
+			} else {
+				conn.reply(req.ID, result)
+			}
+		}()
+	}
+}
+
+// maxFrameSize bounds the body of a message, which is read into memory
+// whole.
+const maxFrameSize = 4 << 20
+
+// readFrame reads the headers and body of one message.
+func readFrame(r *textproto.Reader) ([]byte, error) {
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("bad Content-Length %q", header.Get("Content-Length"))
+	}
+	if n > maxFrameSize {
+		return nil, fmt.Errorf("message of %d bytes is larger than %d", n, maxFrameSize)
+	}
+	body := make([]byte, n)
+	_, err = io.ReadFull(r.R, body)
+	return body, err
+}
+
+// runParams names a run.
+type runParams struct {
+	ID string `json:"id"`
+}
+
+// outputParams is an "output" notification: one line of a run.
+type outputParams struct {
+	ID   string `json:"id"`
+	Line string `json:"line"`
+}
+
+func (s *Server) call(conn *rpcConn, req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "listTasks":
+		return wrap(s.listTasks())
+	case "runTask":
+		var p runRequest
+		if rerr := decodeParams(req.Params, &p); rerr != nil {
+			return nil, rerr
+		}
+		run, err := s.startRun(p)
+		if err != nil {
+			return wrap(nil, err)
+		}
+		return run.status(), nil
+	case "streamOutput", "cancelRun":
+		var p runParams
+		if rerr := decodeParams(req.Params, &p); rerr != nil {
+			return nil, rerr
+		}
+		run, err := s.findRun(p.ID)
+		if err != nil {
+			return wrap(nil, err)
+		}
+		if req.Method == "cancelRun" {
+			run.proc.Cancel()
+			return run.status(), nil
+		}
+		next := 0
+		for {
+			lines, pos, done, changed := run.since(next)
+			next = pos
+			for _, l := range lines {
+				conn.notify("output", outputParams{ID: run.id, Line: l})
+			}
+			if done {
+				return run.status(), nil
+			}
+			<-changed
+		}
+	case "listRuns":
+		return s.runStatuses(), nil
+	case "shutdown":
+		s.CancelRuns()
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}
+}
+
+// decodeParams reads the call's params into v.
+func decodeParams(params json.RawMessage, v any) *rpcError {
+	if len(params) == 0 {
+		return &rpcError{Code: rpcInvalidParams, Message: "missing params"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// wrap turns the result of a Server method into a call result.
+func wrap(result any, err error) (any, *rpcError) {
+	if err != nil {
+		return nil, &rpcError{Code: rpcServerError, Message: err.Error(), Data: map[string]int{"status": statusOf(err)}}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{"Content-Length: 2\r\n\r\n{}", "{}", false},
+		{"content-length: 2\r\nContent-Type: application/vscode-jsonrpc\r\n\r\n{}", "{}", false},
+		{"Content-Length: 0\r\n\r\n", "", false},
+		{"Content-Length: 5\r\n\r\n{}", "", true}, // body cut short
+		{"Content-Length: -1\r\n\r\n", "", true},
+		{"Content-Length: two\r\n\r\n{}", "", true},
+		{"\r\n{}", "", true}, // no Content-Length
+		{"Content-Length: " + strconv.Itoa(maxFrameSize+1) + "\r\n\r\n", "", true},
+	}
+
+	for _, test := range tests {
+		r := textproto.NewReader(bufio.NewReader(strings.NewReader(test.input)))
+		body, err := readFrame(r)
+		if (err != nil) != test.err || (err == nil && string(body) != test.expected) {
+			t.Errorf("Frame %q: expected %q (error %v), got %q (%v)", test.input, test.expected, test.err, body, err)
+		}
+	}
+
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader("")))
+	if _, err := readFrame(r); !errors.Is(err, io.EOF) {
+		t.Errorf("Empty input: expected io.EOF, got %v", err)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent replies of ServeRPC.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServeRPCNotifications(t *testing.T) {
+	tests := []struct {
+		id      string
+		replies int
+	}{
+		{`"id": 1, `, 2},
+		{`"id": "a", `, 2},
+		{`"id": null, `, 1},
+		{``, 1},
+	}
+
+	frame := func(body string) string {
+		return "Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+	}
+	for _, test := range tests {
+		// Calls are answered concurrently: a last call that is answered
+		// tells when the first one had its chance.
+		in := frame(`{"jsonrpc": "2.0", `+test.id+`"method": "listRuns"}`) +
+			frame(`{"jsonrpc": "2.0", "id": "last", "method": "listRuns"}`)
+		var out syncBuffer
+		if err := New(t.TempDir()).ServeRPC(strings.NewReader(in), &out); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for !strings.Contains(out.String(), `"last"`) && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if replies := strings.Count(out.String(), "Content-Length"); replies != test.replies {
+			t.Errorf("Request with %q: expected %d replies, got %q", test.id, test.replies, out.String())
+		}
+	}
+}
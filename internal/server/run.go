@@ -0,0 +1,100 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"taskg/internal/runner"
+)
+
+// run is a task started through the API, with its output kept for
+// GET /runs/{id}/logs.
+type run struct {
+	id      string
+	task    string
+	args    []string
+	started time.Time
+	proc    *runner.Run
+
+	mu       sync.Mutex
+	buf      *runner.Buffer
+	done     bool
+	exitCode int
+	err      error
+	// changed is closed and replaced whenever output arrives or the run
+	// ends, waking up the log streams.
+	changed chan struct{}
+}
+
+func newRun(id, task string, args []string, proc *runner.Run, scrollback int) *run {
+	return &run{
+		id:      id,
+		task:    task,
+		args:    args,
+		started: time.Now(),
+		proc:    proc,
+		buf:     runner.NewBuffer(scrollback),
+		changed: make(chan struct{}),
+	}
+}
+
+// collect reads the process events until it exits. Unterminated lines are
+// left out until they are complete.
+func (r *run) collect() {
+	for ev := range r.proc.Events() {
+		if ev.Partial {
+			continue
+		}
+		r.mu.Lock()
+		if ev.Done {
+			r.done, r.exitCode, r.err = true, ev.ExitCode, ev.Err
+		} else {
+			r.buf.Append(ev.Line)
+		}
+		close(r.changed)
+		r.changed = make(chan struct{})
+		r.mu.Unlock()
+	}
+}
+
+// since returns the lines from absolute position pos on (lines dropped from
+// the scrollback are skipped), the position after them, whether the run has
+// ended and a channel closed on the next change.
+func (r *run) since(pos int) ([]string, int, bool, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start := max(pos-r.buf.Dropped(), 0)
+	var lines []string
+	for i := start; i < r.buf.Len(); i++ {
+		lines = append(lines, r.buf.Line(i))
+	}
+	return lines, r.buf.Dropped() + r.buf.Len(), r.done, r.changed
+}
+
+// finished reports whether the run has ended.
+func (r *run) finished() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.done
+}
+
+func (r *run) status() runStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := runStatus{
+		ID:      r.id,
+		Task:    r.task,
+		Args:    r.args,
+		Started: r.started,
+		Running: !r.done,
+		Logs:    "/runs/" + r.id + "/logs",
+	}
+	if r.done {
+		code := r.exitCode
+		st.ExitCode = &code
+		if r.err != nil {
+			st.Error = r.err.Error()
+		}
+	}
+	return st
+}
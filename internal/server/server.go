@@ -0,0 +1,332 @@
+// Package server exposes the tasks of a project, and runs of them, to other
+// programs: over HTTP for `taskg serve` and as JSON-RPC over stdio for
+// `taskg lsp-ish`.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/hooks"
+	"taskg/internal/runner"
+	"taskg/internal/state"
+	"taskg/internal/taskmeta"
+	"taskg/internal/telemetry"
+)
+
+// Server discovers the tasks of a project and keeps track of the runs
+// started through it. http.go and rpc.go put the protocols on top.
+type Server struct {
+	root string
+	// Mixed also lists Makefile targets and package.json scripts.
+	Mixed bool
+	// Confirm holds task name patterns that, like tasks with taskg_confirm,
+	// only run when the request confirms them.
+	Confirm []string
+	// Policy limits which tasks may run, and which need confirming.
+	Policy config.Policy
+	// Token, when set, must be sent as "Authorization: Bearer <token>" to
+	// the HTTP API.
+	Token string
+	// Scrollback bounds the output lines kept per run.
+	Scrollback int
+	// Hooks are called after each run.
+	Hooks []config.Hook
+	// Telemetry exports each run as a trace.
+	Telemetry config.Telemetry
+
+	mu     sync.Mutex
+	runs   []*run
+	nextID int
+}
+
+// New returns a server for the project in root.
+func New(root string) *Server {
+	return &Server{root: root, Scrollback: runner.DefaultScrollback}
+}
+
+// CancelRuns stops the runs still going, e.g. when the server shuts down.
+func (s *Server) CancelRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.runs {
+		r.proc.Cancel()
+	}
+}
+
+// WaitRuns waits for the runs stopped by CancelRuns to end, for at most
+// runner.StopWait, so those ignoring SIGTERM are killed before the server
+// exits.
+func (s *Server) WaitRuns() {
+	s.mu.Lock()
+	runs := slices.Clone(s.runs)
+	s.mu.Unlock()
+	deadline := time.Now().Add(runner.StopWait)
+	for _, r := range runs {
+		if !r.finished() {
+			r.proc.Wait(deadline)
+		}
+	}
+}
+
+// apiError is a failed request, with the HTTP status describing it.
+type apiError struct {
+	status int
+	err    error
+}
+
+func (e *apiError) Error() string { return e.err.Error() }
+
+// statusOf returns the HTTP status for err.
+func statusOf(err error) int {
+	var aerr *apiError
+	if errors.As(err, &aerr) {
+		return aerr.status
+	}
+	return http.StatusInternalServerError
+}
+
+// taskJSON is a task as the APIs list it.
+type taskJSON struct {
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc,omitempty"`
+	Cmds      []string `json:"cmds,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Backend   string   `json:"backend"`
+	Platforms []string `json:"platforms,omitempty"`
+	Supported bool     `json:"supported"`
+	// Confirm is set for tasks that only run with "confirm": true.
+	Confirm bool `json:"confirm,omitempty"`
+	// Allowed is false for tasks the remote policy refuses to run.
+	Allowed bool `json:"allowed"`
+}
+
+func (s *Server) discover() ([]taskmeta.Task, error) {
+	discover := taskmeta.DiscoverTasks
+	if s.Mixed {
+		discover = taskmeta.DiscoverAll
+	}
+	tasks, err := discover(s.root)
+	var partial *taskmeta.PartialError
+	if errors.As(err, &partial) {
+		err = nil
+	}
+	return tasks, err
+}
+
+// listTasks discovers the project's tasks anew.
+func (s *Server) listTasks() ([]taskJSON, error) {
+	tasks, err := s.discover()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]taskJSON, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, taskJSON{
+			Name:      t.Name,
+			Desc:      t.Desc,
+			Cmds:      t.Cmds,
+			Tags:      t.Tags,
+			Backend:   t.Backend,
+			Platforms: t.Platforms,
+			Supported: t.Supported(),
+			Confirm:   s.needsConfirm(t),
+			Allowed:   s.Policy.Allows(t.Name),
+		})
+	}
+	return out, nil
+}
+
+// needsConfirm reports whether t asks before running, in taskg or through
+// its own prompt:.
+func (s *Server) needsConfirm(t taskmeta.Task) bool {
+	return t.Confirm || t.Prompt != "" || config.MatchAny(s.Confirm, t.Name) || config.MatchAny(s.Policy.Confirm, t.Name)
+}
+
+// runRequest asks to run a task.
+type runRequest struct {
+	// Name is the task; the HTTP API takes it from the path instead.
+	Name string `json:"name"`
+	// Args are passed after the task name, e.g. ["VERSION=1.2"]. Only
+	// VAR=value assignments are taken, see checkRunArgs.
+	Args []string `json:"args"`
+	// Confirm runs tasks that would ask first, answering yes to their
+	// prompt: too.
+	Confirm bool `json:"confirm"`
+}
+
+// startRun starts the task req names, refusing tasks that cannot or may not
+// run unattended.
+func (s *Server) startRun(req runRequest) (*run, error) {
+	if err := checkRunArgs(req.Args); err != nil {
+		return nil, &apiError{http.StatusBadRequest, err}
+	}
+	tasks, err := s.discover()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(tasks, func(t taskmeta.Task) bool { return t.Name == req.Name })
+	if i < 0 {
+		return nil, &apiError{http.StatusNotFound, fmt.Errorf("no task %q", req.Name)}
+	}
+	t := tasks[i]
+	switch shellErr := taskmeta.ShellError(t); {
+	case !s.Policy.Allows(t.Name):
+		return nil, &apiError{http.StatusForbidden, fmt.Errorf("%s may not be run remotely", t.Name)}
+	case !t.Supported():
+		return nil, &apiError{http.StatusUnprocessableEntity, fmt.Errorf("%s does not run on %s", t.Name, taskmeta.Platform())}
+	case shellErr != nil:
+		return nil, &apiError{http.StatusUnprocessableEntity, shellErr}
+	case s.needsConfirm(t) && !req.Confirm:
+		return nil, &apiError{http.StatusConflict, fmt.Errorf(`%s asks before running: send "confirm": true`, t.Name)}
+	case !s.trusted(t):
+		return nil, &apiError{http.StatusForbidden, fmt.Errorf("%s comes from %s, which has not been trusted in the TUI yet", t.Name, t.RemoteSource())}
+	}
+
+	bin, args := t.RunInvocation(req.Args)
+	if req.Confirm && t.Prompt != "" && bin == "task" {
+		args = append([]string{"--yes"}, args...)
+	}
+	var env []string
+	if t.Backend == taskmeta.BackendTask && t.Script == "" {
+		if extra := taskmeta.ExperimentEnv(t.WorkDir(s.root)); extra != nil {
+			env = append(os.Environ(), extra...)
+		}
+	}
+	proc, err := runner.Start(t.WorkDir(s.root), bin, args, env)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.nextID++
+	run := newRun(strconv.Itoa(s.nextID), t.Name, req.Args, proc, s.Scrollback)
+	s.runs = append(s.runs, run)
+	s.mu.Unlock()
+	go func() {
+		run.collect()
+		s.notify(run, t.WorkDir(s.root))
+		s.mu.Lock()
+		s.pruneRuns()
+		s.mu.Unlock()
+	}()
+	return run, nil
+}
+
+// maxFinishedRuns bounds the finished runs kept for listing, each of which
+// holds its scrollback.
+const maxFinishedRuns = 100
+
+// pruneRuns drops the oldest finished runs beyond maxFinishedRuns; runs
+// still going are always kept. s.mu must be held.
+func (s *Server) pruneRuns() {
+	finished := 0
+	for _, r := range s.runs {
+		if r.finished() {
+			finished++
+		}
+	}
+	s.runs = slices.DeleteFunc(s.runs, func(r *run) bool {
+		if finished > maxFinishedRuns && r.finished() {
+			finished--
+			return true
+		}
+		return false
+	})
+}
+
+// varAssignRe matches a VAR=value argument.
+var varAssignRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// checkRunArgs refuses run arguments other than VAR=value assignments: task
+// and make take any other word for one more target to run, and a leading -
+// for a flag, either of which would get past the remote policy, which only
+// sees the task name.
+func checkRunArgs(args []string) error {
+	for _, a := range args {
+		if !varAssignRe.MatchString(a) {
+			return fmt.Errorf("argument %q is not a VAR=value assignment", a)
+		}
+	}
+	return nil
+}
+
+// notify calls the hooks for the finished run, which ran in dir, and
+// exports its trace. Failures only go to the log, as nobody may be watching
+// the run.
+func (s *Server) notify(r *run, dir string) {
+	st := r.status()
+	hr := hooks.Run{
+		Task:     r.task,
+		Args:     r.args,
+		Project:  filepath.Base(dir),
+		Dir:      dir,
+		Started:  r.started,
+		Duration: time.Since(r.started),
+		ExitCode: *st.ExitCode,
+	}
+	if err := hooks.Notify(context.Background(), s.Hooks, hr); err != nil {
+		log.Printf("webhook for run %s failed: %v", r.id, err)
+	}
+	tr := telemetry.Run{Task: hr.Task, Args: hr.Args, Project: hr.Project, Dir: hr.Dir, Started: hr.Started, Duration: hr.Duration, ExitCode: hr.ExitCode}
+	if err := telemetry.Export(context.Background(), s.Telemetry, tr); err != nil {
+		log.Printf("telemetry export for run %s failed: %v", r.id, err)
+	}
+}
+
+// trusted reports whether t may run: remote Taskfiles have to be trusted in
+// the TUI first, which records them in the user config directory of whoever
+// runs the server.
+func (s *Server) trusted(t taskmeta.Task) bool {
+	src := t.RemoteSource()
+	if src == "" {
+		return true
+	}
+	trust, err := state.LoadTrust()
+	return err == nil && trust.Trusted(t.WorkDir(s.root), src)
+}
+
+// findRun returns the run with the given id.
+func (s *Server) findRun(id string) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.id == id {
+			return run, nil
+		}
+	}
+	return nil, &apiError{http.StatusNotFound, fmt.Errorf("no run %q", id)}
+}
+
+// runStatuses lists the runs still going and the last finished ones.
+func (s *Server) runStatuses() []runStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]runStatus, 0, len(s.runs))
+	for _, run := range s.runs {
+		out = append(out, run.status())
+	}
+	return out
+}
+
+// runStatus is a run as the APIs report it.
+type runStatus struct {
+	ID       string    `json:"id"`
+	Task     string    `json:"task"`
+	Args     []string  `json:"args,omitempty"`
+	Started  time.Time `json:"started"`
+	Running  bool      `json:"running"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Logs     string    `json:"logs"`
+}
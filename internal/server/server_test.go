@@ -0,0 +1,65 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPruneRuns(t *testing.T) {
+	tests := []struct {
+		running  int
+		finished int
+		expected int // runs kept
+	}{
+		{0, 0, 0},
+		{3, 5, 8},
+		{0, maxFinishedRuns, maxFinishedRuns},
+		{0, maxFinishedRuns + 1, maxFinishedRuns},
+		{10, maxFinishedRuns + 50, maxFinishedRuns + 10},
+	}
+
+	for _, test := range tests {
+		s := &Server{}
+		// Alternate the running runs with the finished ones, oldest first.
+		for i := 0; i < test.running+test.finished; i++ {
+			s.runs = append(s.runs, &run{id: strconv.Itoa(i + 1), done: i >= 2*test.running || i%2 == 1})
+		}
+		s.pruneRuns()
+		running := 0
+		for _, r := range s.runs {
+			if !r.finished() {
+				running++
+			}
+		}
+		if len(s.runs) != test.expected || running != test.running {
+			t.Errorf("%d running, %d finished: expected %d runs, %d running; got %d, %d running", test.running, test.finished, test.expected, test.running, len(s.runs), running)
+		}
+		if test.finished > maxFinishedRuns && len(s.runs) > 0 && s.runs[len(s.runs)-1].id != strconv.Itoa(test.running+test.finished) {
+			t.Errorf("%d running, %d finished: the newest run was dropped", test.running, test.finished)
+		}
+	}
+}
+
+func TestCheckRunArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		ok   bool
+	}{
+		{nil, true},
+		{[]string{"VERSION=1.2"}, true},
+		{[]string{"A=1", "_B=", "C_2=x=y"}, true},
+		{[]string{"deploy-prod"}, false}, // one more task to run
+		{[]string{"--force"}, false},
+		{[]string{"-x"}, false},
+		{[]string{"VERSION=1.2", "deploy"}, false},
+		{[]string{"1A=x"}, false},
+		{[]string{"=x"}, false},
+		{[]string{""}, false},
+	}
+
+	for _, test := range tests {
+		if err := checkRunArgs(test.args); (err == nil) != test.ok {
+			t.Errorf("Args %q: expected ok %v, got %v", test.args, test.ok, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// FrecencyHalfLife is how long it takes for a run to count half as much.
+const FrecencyHalfLife = 7 * 24 * time.Hour
+
+// MaxDurations bounds the durations kept per task for Estimate.
+const MaxDurations = 10
+
+// RunStats is the run history of one task.
+type RunStats struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+	// Score is the frecency score as of Last: every run adds 1 and older
+	// runs decay with FrecencyHalfLife.
+	Score float64 `json:"score"`
+	// Durations holds how long the last successful runs took, oldest first.
+	Durations []time.Duration `json:"durations,omitempty"`
+	// Result is how the run at Last ended: ResultOK, ResultFailed, or empty
+	// while it runs and when taskg did not see it end.
+	Result string `json:"result,omitempty"`
+}
+
+// Results of a run, as kept in RunStats.Result.
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+// RecordRun adds a run of the task identified by key at time now.
+func (s *State) RecordRun(key string, now time.Time) {
+	if s.Runs == nil {
+		s.Runs = make(map[string]*RunStats)
+	}
+	st := s.Runs[key]
+	if st == nil {
+		st = &RunStats{}
+		s.Runs[key] = st
+	}
+	st.Score = st.decayed(now) + 1
+	st.Count++
+	st.Last = now
+	st.Result = ""
+}
+
+// RecordResult sets how the last run of the task identified by key ended.
+func (s *State) RecordResult(key string, ok bool) {
+	st := s.Runs[key]
+	if st == nil {
+		return
+	}
+	st.Result = ResultFailed
+	if ok {
+		st.Result = ResultOK
+	}
+}
+
+// RecordDuration adds how long a successful run of the task identified by
+// key took, keeping the last MaxDurations.
+func (s *State) RecordDuration(key string, d time.Duration) {
+	if s.Runs == nil {
+		s.Runs = make(map[string]*RunStats)
+	}
+	st := s.Runs[key]
+	if st == nil {
+		st = &RunStats{}
+		s.Runs[key] = st
+	}
+	st.Durations = append(st.Durations, d)
+	if n := len(st.Durations); n > MaxDurations {
+		st.Durations = st.Durations[n-MaxDurations:]
+	}
+}
+
+// Estimate returns the median duration of key's recent successful runs.
+func (s *State) Estimate(key string) (time.Duration, bool) {
+	st := s.Runs[key]
+	if st == nil || len(st.Durations) == 0 {
+		return 0, false
+	}
+	sorted := slices.Clone(st.Durations)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2], true
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2, true
+}
+
+// Frecency returns the decayed score of key at time now; never-run tasks score 0.
+func (s *State) Frecency(key string, now time.Time) float64 {
+	st := s.Runs[key]
+	if st == nil {
+		return 0
+	}
+	return st.decayed(now)
+}
+
+func (st *RunStats) decayed(now time.Time) float64 {
+	if st.Last.IsZero() {
+		return 0
+	}
+	age := now.Sub(st.Last)
+	if age < 0 {
+		age = 0
+	}
+	return st.Score * math.Pow(0.5, float64(age)/float64(FrecencyHalfLife))
+}
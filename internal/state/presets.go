@@ -0,0 +1,34 @@
+package state
+
+import "slices"
+
+// Preset is a named set of variable values to run a task with, e.g.
+// "prod" for ENV=prod REGION=us.
+type Preset struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+// SavePreset stores p for the task identified by key, replacing a preset of
+// the same name in place.
+func (s *State) SavePreset(key string, p Preset) {
+	if s.Presets == nil {
+		s.Presets = make(map[string][]Preset)
+	}
+	list := s.Presets[key]
+	if i := slices.IndexFunc(list, func(o Preset) bool { return o.Name == p.Name }); i >= 0 {
+		list[i] = p
+		return
+	}
+	s.Presets[key] = append(list, p)
+}
+
+// DeletePreset removes the preset called name of the task identified by key.
+func (s *State) DeletePreset(key, name string) {
+	list := slices.DeleteFunc(s.Presets[key], func(o Preset) bool { return o.Name == name })
+	if len(list) == 0 {
+		delete(s.Presets, key)
+		return
+	}
+	s.Presets[key] = list
+}
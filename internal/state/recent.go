@@ -0,0 +1,70 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// MaxRecent bounds how many project roots are remembered.
+const MaxRecent = 20
+
+// Recent is the per-user list of recently opened project roots, most recent
+// first. Unlike State it is shared by all projects.
+type Recent struct {
+	Projects []string `json:"projects"`
+
+	path string
+}
+
+// LoadRecent reads the recent projects list from the user config directory.
+// A missing file yields an empty list.
+func LoadRecent() (*Recent, error) {
+	r := &Recent{}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return r, err
+	}
+	r.path = filepath.Join(dir, "taskg", "recent.json")
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Add moves root to the front of the list, dropping the oldest entries beyond MaxRecent.
+func (r *Recent) Add(root string) {
+	projects := []string{root}
+	for _, p := range r.Projects {
+		if p != root {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) > MaxRecent {
+		projects = projects[:MaxRecent]
+	}
+	r.Projects = projects
+}
+
+// Save writes the list back to disk, creating the config directory if needed.
+func (r *Recent) Save() error {
+	if r == nil || r.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Project is a registered project root and the name its tasks are listed
+// under in global mode.
+type Project struct {
+	Name string `json:"name"`
+	Root string `json:"root"`
+}
+
+// Registry is the per-user list of projects `taskg --global` shows, in the
+// order they were registered. Like Recent it is shared by all projects.
+type Registry struct {
+	Projects []Project `json:"projects"`
+
+	path string
+}
+
+// GlobalDir is where global mode keeps its run history, notes and logs,
+// since they belong to no single project.
+func GlobalDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "taskg", "global"), nil
+}
+
+// LoadRegistry reads the registered projects from the user config
+// directory. A missing file yields an empty registry.
+func LoadRegistry() (*Registry, error) {
+	r := &Registry{}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return r, err
+	}
+	r.path = filepath.Join(dir, "taskg", "projects.json")
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Add registers p, replacing a project with the same name or root.
+func (r *Registry) Add(p Project) {
+	r.Projects = slices.DeleteFunc(r.Projects, func(o Project) bool { return o.Name == p.Name || o.Root == p.Root })
+	r.Projects = append(r.Projects, p)
+}
+
+// Remove unregisters the project with the given name or root and reports
+// whether there was one.
+func (r *Registry) Remove(nameOrRoot string) bool {
+	n := len(r.Projects)
+	r.Projects = slices.DeleteFunc(r.Projects, func(o Project) bool { return o.Name == nameOrRoot || o.Root == nameOrRoot })
+	return len(r.Projects) < n
+}
+
+// Save writes the registry back to disk, creating the config directory if
+// needed.
+func (r *Registry) Save() error {
+	if r == nil || r.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
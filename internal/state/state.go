@@ -0,0 +1,73 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// DirName is the per-project directory holding taskg's local data. It lives
+// next to the Taskfile and is meant to be git-ignored.
+const DirName = ".taskg"
+
+// State is the per-project local state persisted to .taskg/state.json.
+type State struct {
+	// Env holds remembered environment overrides keyed by task name.
+	Env map[string]map[string]string `json:"env,omitempty"`
+	// Runs is the run history keyed by backend, project and task name (see history.go).
+	Runs map[string]*RunStats `json:"runs,omitempty"`
+	// Notes holds free-form notes keyed like Runs.
+	Notes map[string]string `json:"notes,omitempty"`
+	// UI is the tab, sort mode and selection the project was left with.
+	UI *UIState `json:"ui,omitempty"`
+	// Presets holds named variable values keyed like Runs (see presets.go).
+	Presets map[string][]Preset `json:"presets,omitempty"`
+	// EnvFiles holds the env file last picked for a task, keyed like Runs;
+	// "" means none.
+	EnvFiles map[string]string `json:"env_files,omitempty"`
+	// PinnedTabs lists the tabs pinned to the front of the tab strip.
+	PinnedTabs []string `json:"pinned_tabs,omitempty"`
+	// TabOrder is the order the other tabs were last arranged in.
+	TabOrder []string `json:"tab_order,omitempty"`
+	// Favorites lists the starred tasks, keyed like Runs.
+	Favorites []string `json:"favorites,omitempty"`
+
+	path string
+}
+
+// Load reads the state for the project at root. A missing file yields an empty
+// state; an empty root yields a state that is never written to disk.
+func Load(root string) (*State, error) {
+	s := &State{}
+	if root == "" {
+		return s, nil
+	}
+	s.path = filepath.Join(root, DirName, "state.json")
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Save writes the state back to disk, creating the .taskg directory if needed.
+func (s *State) Save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0o644)
+}
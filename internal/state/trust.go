@@ -0,0 +1,88 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Trust is the per-user list of remote Taskfile URLs trusted to run, keyed
+// by project root. It lives in the user config directory rather than in
+// .taskg/state.json, which a repository could commit with its own URLs
+// already trusted.
+type Trust struct {
+	Projects map[string][]string `json:"projects"`
+
+	path string
+}
+
+// LoadTrust reads the trusted remote Taskfiles from the user config
+// directory. A missing file yields an empty list.
+func LoadTrust() (*Trust, error) {
+	t := &Trust{}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return t, err
+	}
+	t.path = filepath.Join(dir, "taskg", "trusted.json")
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// trustKey is the key of the project at root, "" when there is none.
+func trustKey(root string) string {
+	if root == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
+
+// Trusted reports whether url has been trusted in the project at root.
+func (t *Trust) Trusted(root, url string) bool {
+	key := trustKey(root)
+	return t != nil && key != "" && slices.Contains(t.Projects[key], url)
+}
+
+// Add trusts url in the project at root. Outside a project nothing is
+// trusted.
+func (t *Trust) Add(root, url string) {
+	key := trustKey(root)
+	if key == "" || t.Trusted(root, url) {
+		return
+	}
+	if t.Projects == nil {
+		t.Projects = make(map[string][]string)
+	}
+	t.Projects[key] = append(t.Projects[key], url)
+}
+
+// Save writes the list back to disk, creating the config directory if
+// needed. Only the user may read it.
+func (t *Trust) Save() error {
+	if t == nil || t.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0o600)
+}
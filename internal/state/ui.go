@@ -0,0 +1,18 @@
+package state
+
+// UIState records where the user left the task list, so reopening the
+// project puts them back there.
+type UIState struct {
+	ActiveTab string `json:"active_tab,omitempty"`
+	SortMode  string `json:"sort_mode,omitempty"`
+	// Selected is the key of the selected task (backend, project and name).
+	Selected string `json:"selected,omitempty"`
+	// ListOffset is the index of the first task shown, so the list scrolls
+	// back to where it was.
+	ListOffset int `json:"list_offset,omitempty"`
+	// Density is "compact" when the command lines under the tasks were
+	// hidden, else "normal".
+	Density string `json:"density,omitempty"`
+	// ShowDetail is set when the detail pane was open.
+	ShowDetail bool `json:"show_detail,omitempty"`
+}
@@ -16,6 +16,7 @@ type Theme struct {
 	Status         lipgloss.Style
 	Output         lipgloss.Style
 	Error          lipgloss.Style
+	Warning        lipgloss.Style
 	HeaderBox      lipgloss.Style
 	CommandBox     lipgloss.Style
 	ContentBox     lipgloss.Style
@@ -64,6 +65,7 @@ func NewDarkTheme() Theme {
 		Help:        lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")),
 		Status:      lipgloss.NewStyle().Foreground(lipgloss.Color("#68D391")).Bold(true),
 		Error:       lipgloss.NewStyle().Foreground(lipgloss.Color("#FC8181")).Bold(true),
+		Warning:     lipgloss.NewStyle().Foreground(lipgloss.Color("#F6E05E")).Bold(true),
 		Output:      lipgloss.NewStyle().Foreground(lipgloss.Color("#E2E8F0")),
 		Border:      lipgloss.NewStyle().Foreground(lipgloss.Color("#4A5568")),
 
@@ -106,6 +108,7 @@ func NewLightTheme() Theme {
 		Help:        lipgloss.NewStyle().Foreground(lipgloss.Color("#718096")),
 		Status:      lipgloss.NewStyle().Foreground(lipgloss.Color("#059669")).Bold(true),
 		Error:       lipgloss.NewStyle().Foreground(lipgloss.Color("#DC2626")).Bold(true),
+		Warning:     lipgloss.NewStyle().Foreground(lipgloss.Color("#B45309")).Bold(true),
 		Output:      lipgloss.NewStyle().Foreground(lipgloss.Color("#1A202C")),
 		Border:      lipgloss.NewStyle().Foreground(lipgloss.Color("#A0AEC0")),
 
@@ -117,4 +120,96 @@ func NewLightTheme() Theme {
 
 		HighlightColor: highlightColor,
 	}
-}
\ No newline at end of file
+}
+
+// NewHighContrastTheme returns a color scheme for low vision: bright,
+// bold colors from the basic 16-color palette, which terminals tune for
+// legibility, and plain white borders.
+func NewHighContrastTheme() Theme {
+	white := lipgloss.Color("15")
+	highlightColor := lipgloss.Color("11") // bright yellow
+	return Theme{
+		AppTitle:     lipgloss.NewStyle().Bold(true).Foreground(white).Padding(0, 4),
+		AppContainer: lipgloss.NewStyle().Padding(1, 1).Border(lipgloss.NormalBorder()).BorderForeground(white),
+
+		HeaderBox: lipgloss.NewStyle().Bold(true).Foreground(white).Border(lipgloss.NormalBorder()).BorderForeground(white).Padding(1, 2).Margin(0, 0, 1, 0),
+
+		TabActive:     lipgloss.NewStyle().Bold(true).Underline(true).Foreground(highlightColor).Padding(0, 3).Margin(0, 1),
+		TabInactive:   lipgloss.NewStyle().Foreground(white).Padding(0, 3).Margin(0, 1),
+		TabsContainer: lipgloss.NewStyle().Padding(0, 1).Margin(0, 0, 1, 0).Border(lipgloss.NormalBorder(), false, false, true, false).BorderForeground(white),
+		TabArrow:      lipgloss.NewStyle().Foreground(highlightColor).Bold(true),
+
+		CommandBox:   lipgloss.NewStyle().Foreground(white).Border(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("7")).Padding(0, 1),
+		Selected:     lipgloss.NewStyle().Foreground(white).Border(lipgloss.ThickBorder()).BorderForeground(highlightColor).Padding(0, 1),
+		SelectedWire: lipgloss.NewStyle().Foreground(white).Border(lipgloss.ThickBorder()).BorderForeground(highlightColor).Padding(0, 1),
+
+		ContentBox: lipgloss.NewStyle().Foreground(white).Border(lipgloss.NormalBorder()).BorderForeground(white).Padding(1, 2).Margin(0, 0, 1, 0),
+		SearchBox:  lipgloss.NewStyle().Foreground(highlightColor).Border(lipgloss.NormalBorder()).BorderForeground(highlightColor).Padding(0, 2).Margin(0, 0, 1, 0),
+		FooterBox:  lipgloss.NewStyle().Foreground(white).Border(lipgloss.NormalBorder()).BorderForeground(white).Padding(0, 2, 0, 2).Margin(1, 0, 0, 0),
+
+		Title:       lipgloss.NewStyle().Foreground(white).Bold(true),
+		TaskName:    lipgloss.NewStyle().Foreground(white).Bold(true),
+		Command:     lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+		Description: lipgloss.NewStyle().Foreground(white),
+		Help:        lipgloss.NewStyle().Foreground(white),
+		Status:      lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		Error:       lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		Warning:     lipgloss.NewStyle().Foreground(highlightColor).Bold(true),
+		Output:      lipgloss.NewStyle().Foreground(white),
+		Border:      lipgloss.NewStyle().Foreground(white),
+
+		Gradient:      lipgloss.NewStyle().Foreground(white),
+		Highlight:     lipgloss.NewStyle().Foreground(highlightColor).Bold(true),
+		Accent:        lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true),
+		Logo:          lipgloss.NewStyle().Foreground(white).Bold(true),
+		BannerOptions: lipgloss.NewStyle().Inline(true).MaxWidth(1000),
+
+		HighlightColor: highlightColor,
+	}
+}
+
+// ThemeNames lists the themes ByName knows, in the order they cycle.
+var ThemeNames = []string{"dark", "light", "high-contrast"}
+
+// ByName returns the theme called name, falling back to the dark one.
+func ByName(name string) Theme {
+	switch name {
+	case "light":
+		return NewLightTheme()
+	case "high-contrast":
+		return NewHighContrastTheme()
+	}
+	return NewDarkTheme()
+}
+
+// Plain strips the borders, padding and margins off the boxes of t, so
+// that screens read as plain lines of text, e.g. by a screen reader.
+func Plain(t Theme) Theme {
+	t = Frameless(t)
+	t.AppContainer = plain(t.AppContainer)
+	t.HeaderBox = plain(t.HeaderBox)
+	t.CommandBox = plain(t.CommandBox)
+	t.Selected = plain(t.Selected)
+	t.SelectedWire = plain(t.SelectedWire)
+	t.ContentBox = plain(t.ContentBox)
+	t.AppTitle = plain(t.AppTitle)
+	return t
+}
+
+// Frameless strips the borders, padding and margins off the boxes framing
+// the screen of t, i.e. the app container, tabs, search box and footer,
+// but keeps those of the tasks. The app container keeps blank sides, which
+// the widths of the task boxes account for.
+func Frameless(t Theme) Theme {
+	t.AppContainer = plain(t.AppContainer).Border(lipgloss.HiddenBorder(), false, true)
+	t.TabsContainer = plain(t.TabsContainer)
+	t.SearchBox = plain(t.SearchBox)
+	t.FooterBox = plain(t.FooterBox)
+	return t
+}
+
+func plain(s lipgloss.Style) lipgloss.Style {
+	return s.Copy().
+		UnsetBorderStyle().UnsetBorderTop().UnsetBorderRight().UnsetBorderBottom().UnsetBorderLeft().
+		UnsetPadding().UnsetMargins()
+}
@@ -0,0 +1,182 @@
+package taskmeta
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewTask describes a task to add to a Taskfile.
+type NewTask struct {
+	Name string
+	Desc string
+	Dir  string
+	Cmds []string
+}
+
+// taskNameRe matches the task names AppendTask accepts.
+var taskNameRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.:-]*$`)
+
+// AppendTask adds t to the end of the tasks: section of the Taskfile in root,
+// creating a Taskfile.yml when there is none, and returns the file written.
+// The YAML tree is only used to find where the task goes; the new lines are
+// spliced into the file so existing comments and formatting stay as they were.
+func AppendTask(root string, t NewTask) (string, error) {
+	if !taskNameRe.MatchString(t.Name) {
+		return "", fmt.Errorf("invalid task name %q", t.Name)
+	}
+	if len(t.Cmds) == 0 {
+		return "", errors.New("a task needs at least one command")
+	}
+	path, ok := resolveTaskfile(root)
+	if !ok {
+		path = filepath.Join(root, taskfileRootCandidates[0])
+		data := "version: '3'\n\ntasks:\n" + renderNewTask(t, 2, 2)
+		return path, os.WriteFile(path, []byte(data), 0o644)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	out, err := insertTask(string(data), t)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(out), info.Mode().Perm())
+}
+
+// insertTask returns src with t added as the last entry of its tasks: map.
+func insertTask(src string, t NewTask) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		return "", err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", errors.New("not a Taskfile")
+	}
+	top := doc.Content[0]
+	if top.Style&yaml.FlowStyle != 0 {
+		return "", errors.New("Taskfile is written in flow style; add the task by hand")
+	}
+	lines := splitLines(src)
+
+	tasksAt := -1
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value == "tasks" {
+			tasksAt = i
+			break
+		}
+	}
+	if tasksAt < 0 {
+		block := "\ntasks:\n" + renderNewTask(t, 2, 2)
+		return splice(lines, endOfSection(lines, len(lines), 0), block), nil
+	}
+
+	key, tasks := top.Content[tasksAt], top.Content[tasksAt+1]
+	switch {
+	case tasks.Kind == yaml.ScalarNode && tasks.Tag == "!!null":
+		// "tasks:" with nothing below it yet
+		return splice(lines, key.Line, renderNewTask(t, 2, 2)), nil
+	case tasks.Kind != yaml.MappingNode:
+		return "", errors.New("tasks: is not a map")
+	case tasks.Style&yaml.FlowStyle != 0:
+		return "", errors.New("tasks: is written in flow style; add the task by hand")
+	}
+	for i := 0; i < len(tasks.Content); i += 2 {
+		if tasks.Content[i].Value == t.Name {
+			return "", fmt.Errorf("task %q already exists", t.Name)
+		}
+	}
+
+	// Follow the indentation of the existing tasks.
+	indent, step := 2, 2
+	if len(tasks.Content) >= 2 {
+		first, body := tasks.Content[0], tasks.Content[1]
+		indent = first.Column - 1
+		if body.Kind == yaml.MappingNode && len(body.Content) > 0 && body.Content[0].Column > first.Column {
+			step = body.Content[0].Column - first.Column
+		}
+	}
+	end := len(lines)
+	if tasksAt+2 < len(top.Content) {
+		end = top.Content[tasksAt+2].Line - 1
+	}
+	return splice(lines, endOfSection(lines, end, 0), "\n"+renderNewTask(t, indent, step)), nil
+}
+
+// splitLines splits src into lines that all end in a newline.
+func splitLines(src string) []string {
+	lines := strings.SplitAfter(src, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines[n-1] += "\n"
+	}
+	return lines
+}
+
+// endOfSection moves end (a 0-based line index) up past blank lines and
+// comments indented by at most indent spaces, which belong to whatever
+// follows the section.
+func endOfSection(lines []string, end, indent int) int {
+	for end > 0 {
+		l := lines[end-1]
+		trimmed := strings.TrimLeft(l, " ")
+		if strings.TrimSpace(l) != "" && (!strings.HasPrefix(trimmed, "#") || len(l)-len(trimmed) > indent) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// splice inserts block before the line with 0-based index at.
+func splice(lines []string, at int, block string) string {
+	var b strings.Builder
+	for _, l := range lines[:at] {
+		b.WriteString(l)
+	}
+	b.WriteString(block)
+	for _, l := range lines[at:] {
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// renderNewTask renders t as a tasks: entry indented by indent spaces, with
+// step more spaces per nesting level.
+func renderNewTask(t NewTask, indent, step int) string {
+	pad := func(level int) string { return strings.Repeat(" ", indent+level*step) }
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s:\n", pad(0), t.Name)
+	if t.Desc != "" {
+		fmt.Fprintf(&b, "%sdesc: %s\n", pad(1), yamlScalar(t.Desc))
+	}
+	if t.Dir != "" {
+		fmt.Fprintf(&b, "%sdir: %s\n", pad(1), yamlScalar(t.Dir))
+	}
+	fmt.Fprintf(&b, "%scmds:\n", pad(1))
+	for _, c := range t.Cmds {
+		fmt.Fprintf(&b, "%s- %s\n", pad(2), yamlScalar(c))
+	}
+	return b.String()
+}
+
+// yamlScalar renders s as a one-line YAML scalar, quoting it when needed.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil || strings.Contains(strings.TrimSpace(string(out)), "\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSpace(string(out))
+}
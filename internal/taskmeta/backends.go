@@ -0,0 +1,217 @@
+package taskmeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Backend identifiers recorded on Task.Backend.
+const (
+	BackendTask = "task"
+	BackendMake = "make"
+	BackendNPM  = "npm"
+)
+
+// Invocation returns the binary and argument list used to run t with the
+// given extra args (e.g. VAR=value pairs).
+func (t Task) Invocation(args []string) (string, []string) {
+	switch t.Backend {
+	case BackendMake:
+		return "make", append([]string{t.Name}, args...)
+	case BackendNPM:
+		out := []string{"run", t.Name}
+		if len(args) > 0 {
+			out = append(out, "--")
+			out = append(out, args...)
+		}
+		return "npm", out
+	default:
+		if t.Script != "" {
+			// "$0" is taskg; args become "$@" for the VAR=value exports.
+			return "sh", append([]string{"-c", t.Script, "taskg"}, args...)
+		}
+		return "task", append([]string{t.Name}, args...)
+	}
+}
+
+// RunInvocation is Invocation for actually running t: task is asked to exit
+// with the status of the failing command (--exit-code) instead of its own
+// generic 201, when the installed release supports it.
+func (t Task) RunInvocation(args []string) (string, []string) {
+	bin, out := t.Invocation(args)
+	if bin == "task" && Supports(FeatureExitCode) {
+		out = append([]string{"--exit-code"}, out...)
+	}
+	return bin, out
+}
+
+// safeWordRe matches arguments that need no quoting in a POSIX shell.
+var safeWordRe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// CommandLine renders bin and args as a POSIX shell command line, quoting
+// only the words that need it.
+func CommandLine(bin string, args []string) string {
+	words := make([]string, 0, len(args)+1)
+	for _, w := range append([]string{bin}, args...) {
+		if safeWordRe.MatchString(w) {
+			words = append(words, w)
+		} else {
+			words = append(words, shellQuote(w))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// DiscoverAll runs every backend provider against root and merges the results.
+// The Taskfile backend is optional here: a project with only a Makefile or a
+// package.json still yields tasks. An error is returned only when no backend
+// produced anything.
+func DiscoverAll(root string) ([]Task, error) {
+	var all []Task
+	var errs, partial []string
+
+	tasks, err := DiscoverTasks(root)
+	var perr *PartialError
+	if errors.As(err, &perr) {
+		partial = append(partial, perr.Reason)
+	} else if err != nil {
+		errs = append(errs, fmt.Sprintf("task:%v", err))
+	}
+	all = append(all, tasks...)
+
+	makeTasks, err := discoverMakeTargets(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Sprintf("make:%v", err))
+	}
+	all = append(all, makeTasks...)
+
+	npmTasks, err := discoverNPMScripts(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Sprintf("npm:%v", err))
+	}
+	all = append(all, npmTasks...)
+
+	if len(all) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("failed to discover tasks (%s)", strings.Join(errs, " "))
+	}
+	return all, mergePartial(partial)
+}
+
+// makeTargetRe matches simple explicit targets such as `build:` or
+// `test-unit: deps`, but not variable assignments (`X := y`) or pattern rules.
+var makeTargetRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.\-/]*)\s*:([^=]|$)`)
+
+// discoverMakeTargets parses a Makefile in root for explicit targets. A `## text`
+// comment on the target line or a `# text` comment directly above it is used as
+// the description.
+func discoverMakeTargets(root string) ([]Task, error) {
+	var path string
+	for _, name := range []string{"GNUmakefile", "Makefile", "makefile"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			path = filepath.Join(root, name)
+			break
+		}
+	}
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	seen := make(map[string]bool)
+	var current *Task
+	lastComment := ""
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if strings.HasPrefix(line, "\t") {
+			if current != nil {
+				cmd := strings.TrimLeft(strings.TrimSpace(line), "@-+")
+				if cmd != "" {
+					current.Cmds = append(current.Cmds, cmd)
+				}
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			lastComment = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		match := makeTargetRe.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(match[1], ".") {
+			current = nil
+			lastComment = ""
+			continue
+		}
+		name := match[1]
+		desc := lastComment
+		if idx := strings.Index(line, "##"); idx != -1 {
+			desc = strings.TrimSpace(line[idx+2:])
+		}
+		lastComment = ""
+		if seen[name] {
+			current = nil
+			continue
+		}
+		seen[name] = true
+		tasks = append(tasks, Task{Name: name, Desc: desc, Line: lineNo, Backend: BackendMake})
+		current = &tasks[len(tasks)-1]
+	}
+	return tasks, sc.Err()
+}
+
+// discoverNPMScripts reads the scripts section of package.json in root,
+// keeping the declaration order.
+func discoverNPMScripts(root string) ([]Task, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Scripts json.RawMessage `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.Scripts) == 0 {
+		return nil, nil
+	}
+
+	// Walk tokens instead of decoding into a map so file order survives.
+	dec := json.NewDecoder(bytes.NewReader(pkg.Scripts))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var script string
+		if err := dec.Decode(&script); err != nil {
+			return nil, err
+		}
+		name, _ := keyTok.(string)
+		tasks = append(tasks, Task{
+			Name:    name,
+			Cmds:    []string{script},
+			Line:    len(tasks) + 1,
+			Backend: BackendNPM,
+		})
+	}
+	return tasks, nil
+}
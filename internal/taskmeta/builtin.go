@@ -0,0 +1,117 @@
+package taskmeta
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// TaskBinaryAvailable reports whether the task CLI is on PATH.
+func TaskBinaryAvailable() bool {
+	_, err := exec.LookPath("task")
+	return err == nil
+}
+
+// maxCallDepth stops runaway expansion of `task:` calls and deps that form a cycle.
+const maxCallDepth = 16
+
+// builtinScripts turns every compiled task into a POSIX shell script for the
+// built-in runner used when the task binary is missing. The runner is
+// deliberately limited: cmds run in order with sh, honoring dir: and env:;
+// deps and `task:` calls run sequentially before/inline; sources, status,
+// preconditions, prompts and dynamic (sh:) variables are ignored.
+func builtinScripts(compiled []*ast.Task) map[string]string {
+	byName := make(map[string]*ast.Task, len(compiled))
+	for _, t := range compiled {
+		byName[t.Task] = t
+	}
+	scripts := make(map[string]string, len(compiled))
+	for _, t := range compiled {
+		var b strings.Builder
+		b.WriteString("set -e\n")
+		// Extra args are VAR=value pairs, as they would be for `task name VAR=value`.
+		b.WriteString("for a in \"$@\"; do case $a in *=*) export \"$a\";; esac; done\n")
+		writeTaskScript(&b, t, byName, 0)
+		scripts[t.Task] = b.String()
+	}
+	return scripts
+}
+
+// writeTaskScript emits t as a subshell so its dir and env do not leak into the caller.
+func writeTaskScript(b *strings.Builder, t *ast.Task, byName map[string]*ast.Task, depth int) {
+	if depth > maxCallDepth {
+		fmt.Fprintf(b, "echo %s >&2; exit 1\n", shellQuote("task: call depth exceeded at "+t.Task))
+		return
+	}
+	b.WriteString("(\n")
+	if t.Dir != "" {
+		fmt.Fprintf(b, "cd %s\n", shellQuote(t.Dir))
+	}
+	env := taskEnv(t)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if envKeyRe.MatchString(k) {
+			fmt.Fprintf(b, "export %s=%s\n", k, shellQuote(env[k]))
+		}
+	}
+	for _, d := range t.Deps {
+		if d == nil {
+			continue
+		}
+		writeCall(b, d.Task, byName, depth)
+	}
+	for _, c := range t.Cmds {
+		switch {
+		case c == nil:
+		case c.Task != "":
+			writeCall(b, c.Task, byName, depth)
+		case c.Cmd != "":
+			if !t.Silent && !c.Silent {
+				fmt.Fprintf(b, "echo %s >&2\n", shellQuote("task: ["+t.Task+"] "+c.Cmd))
+			}
+			b.WriteString(c.Cmd)
+			if c.IgnoreError || t.IgnoreError {
+				b.WriteString(" || true")
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(")\n")
+}
+
+func writeCall(b *strings.Builder, name string, byName map[string]*ast.Task, depth int) {
+	callee, ok := byName[name]
+	if !ok {
+		fmt.Fprintf(b, "echo %s >&2; exit 1\n", shellQuote("task: unknown task "+name))
+		return
+	}
+	writeTaskScript(b, callee, byName, depth+1)
+}
+
+// taskEnv flattens the static env: values of a compiled task.
+func taskEnv(t *ast.Task) map[string]string {
+	env := make(map[string]string)
+	_ = t.Env.Range(func(k string, v ast.Var) error {
+		if v.Value != nil {
+			env[k] = fmt.Sprint(v.Value)
+		}
+		return nil
+	})
+	return env
+}
+
+// envKeyRe matches names that are safe to export from a shell script.
+var envKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// shellQuote wraps s in single quotes for POSIX sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,139 @@
+package taskmeta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// cacheVersion is bumped whenever Task or the cache layout changes, which
+// invalidates every cache file written by older versions.
+const cacheVersion = 11
+
+// discoveryCache is the on-disk form of a cached task list.
+type discoveryCache struct {
+	Version int
+	Root    string
+	Mode    string
+	// Files maps every file the result was derived from to its modification
+	// time in nanoseconds; a missing file is recorded as 0.
+	Files map[string]int64
+	// Dirs are the project directories the result covers in recursive and
+	// global mode: the subprojects found or the registered projects.
+	Dirs  []string
+	Tasks []Task
+}
+
+// cachePath returns the cache file of root discovered in mode, e.g.
+// ~/.cache/taskg/discovery/<hash>.json.
+func cachePath(root, mode string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root + "\x00" + mode))
+	return filepath.Join(dir, "taskg", "discovery", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadCache returns the tasks cached for root and mode when none of the files
+// they came from changed since and they cover the same project directories,
+// dirs. mode distinguishes discovery settings that change the result, such as
+// mixed or recursive mode. dirs is nil outside recursive and global mode;
+// otherwise a subproject added or a project registered since invalidates the
+// cache, which the files alone cannot tell.
+func LoadCache(root, mode string, dirs []string) ([]Task, bool) {
+	path, err := cachePath(root, mode)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var c discoveryCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, false
+	}
+	if c.Version != cacheVersion || c.Root != root || c.Mode != mode || len(c.Tasks) == 0 || !slices.Equal(c.Dirs, dirs) {
+		return nil, false
+	}
+	for file, mtime := range c.Files {
+		if fileMtime(file) != mtime {
+			return nil, false
+		}
+	}
+	return c.Tasks, true
+}
+
+// SaveCache stores tasks discovered for root and mode in the project
+// directories dirs (see LoadCache), fingerprinted by the modification times of
+// the Taskfiles (with their includes), Makefiles and package.json files of
+// root and of every project directory.
+func SaveCache(root, mode string, dirs []string, tasks []Task) error {
+	path, err := cachePath(root, mode)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]int64)
+	for _, f := range cacheInputs(root, dirs, tasks) {
+		files[f] = fileMtime(f)
+	}
+	data, err := json.Marshal(discoveryCache{
+		Version: cacheVersion,
+		Root:    root,
+		Mode:    mode,
+		Files:   files,
+		Dirs:    dirs,
+		Tasks:   tasks,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write then rename so a concurrent taskg never reads half a file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// cacheInputs lists the files a discovery result of root in the project
+// directories projects depends on. Files that do not exist are included too,
+// so creating one invalidates the cache.
+func cacheInputs(root string, projects []string, tasks []Task) []string {
+	dirs := map[string]bool{root: true}
+	for _, dir := range projects {
+		dirs[dir] = true
+	}
+	for _, t := range tasks {
+		dirs[t.WorkDir(root)] = true
+	}
+	var files []string
+	for dir := range dirs {
+		if _, tree := parseTaskfileTree(dir); len(tree) > 0 {
+			files = append(files, tree...)
+		} else {
+			files = append(files, filepath.Join(dir, taskfileRootCandidates[0]))
+		}
+		for _, name := range []string{"GNUmakefile", "Makefile", "makefile", "package.json"} {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func fileMtime(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
@@ -0,0 +1,79 @@
+package taskmeta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// ChoicesVar is the task variable listing the allowed values of other
+// variables, which the pre-run form offers as a select, e.g.
+//
+//	vars:
+//	  taskg_choices: ENV=staging|prod REGION=eu|us
+//
+// A YAML list of NAME=a|b entries works too. Task rejects maps as variable
+// values, hence the flat syntax.
+const ChoicesVar = "taskg_choices"
+
+// choicesFromVars reads ChoicesVar from the vars of a parsed task.
+func choicesFromVars(vars *ast.Vars) map[string][]string {
+	if vars == nil || !vars.Exists(ChoicesVar) {
+		return nil
+	}
+	return parseChoices(vars.Get(ChoicesVar).Value)
+}
+
+// parseChoices reads a ChoicesVar value: NAME=a|b entries, separated by
+// spaces or given as a YAML list. Values may be separated by commas too.
+func parseChoices(v any) map[string][]string {
+	var entries []string
+	switch v := v.(type) {
+	case string:
+		entries = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			entries = append(entries, strings.Fields(fmt.Sprint(item))...)
+		}
+	}
+	var out map[string][]string
+	for _, e := range entries {
+		name, values, ok := strings.Cut(e, "=")
+		list := strings.FieldsFunc(values, func(r rune) bool { return r == '|' || r == ',' })
+		if !ok || name == "" || len(list) == 0 {
+			continue
+		}
+		if out == nil {
+			out = map[string][]string{}
+		}
+		out[name] = list
+	}
+	return out
+}
+
+// choicesFromYAML reads the choices of a task from its raw YAML: the
+// ChoicesVar var and the enum: lists of requires: vars, which newer Task
+// releases check before running the task.
+func choicesFromYAML(task map[string]any) map[string][]string {
+	vars, _ := task["vars"].(map[string]any)
+	out := parseChoices(vars[ChoicesVar])
+	requires, _ := task["requires"].(map[string]any)
+	list, _ := requires["vars"].([]any)
+	for _, item := range list {
+		req, _ := item.(map[string]any)
+		name, _ := req["name"].(string)
+		enum, _ := req["enum"].([]any)
+		if name == "" || len(enum) == 0 {
+			continue
+		}
+		if out == nil {
+			out = map[string][]string{}
+		}
+		out[name] = nil
+		for _, e := range enum {
+			out[name] = append(out[name], strings.TrimSpace(fmt.Sprint(e)))
+		}
+	}
+	return out
+}
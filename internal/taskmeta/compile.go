@@ -0,0 +1,117 @@
+package taskmeta
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	task "github.com/go-task/task/v3"
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// EnvVar is one variable a Taskfile sets for a task.
+type EnvVar struct {
+	Name  string
+	Value string
+	// Shell is set when the variable is already in taskg's environment;
+	// Task keeps that value, which Value then holds.
+	Shell bool
+	// Dynamic is set for sh: variables, whose Value is the command.
+	Dynamic bool
+}
+
+// Compiled is a task as Task would run it, templates resolved.
+type Compiled struct {
+	// Cmds are the command lines with their templates rendered; calls of
+	// other tasks read "task: name".
+	Cmds []string
+	// Loops lists the for: loops of the task's cmds and deps, expanded.
+	Loops []Loop
+	// Env is what the task adds to the environment it inherits.
+	Env    []EnvVar
+	Dotenv []string // .env files loaded for the task, relative to root when below it
+}
+
+// CompileTask compiles the task name of the Taskfile in root with the
+// go-task library. Templates see the Taskfile and task vars, the env and
+// Task's builtins such as {{OS}}; the env merges the global env:, the
+// global and task dotenv: files and the task's own env:, the way Task does.
+// Nothing is run: sh: variables render as "$(command)" instead.
+func CompileTask(root, name string) (*Compiled, error) {
+	e := &task.Executor{
+		Dir:    root,
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	}
+	if err := setupExecutor(e); err != nil {
+		return nil, err
+	}
+	stubDynamicVars(e.Taskfile.Vars)
+	parsed := e.Taskfile.Tasks.Get(name)
+	if parsed != nil {
+		stubDynamicVars(parsed.Vars)
+	}
+	t, err := e.FastCompiledTask(&ast.Call{Task: name})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Compiled{Cmds: cmdLines(t.Cmds)}
+	if parsed != nil {
+		c.Loops = append(expandDepLoops(parsed.Deps, t.Deps), expandCmdLoops(parsed.Cmds, t.Cmds)...)
+	}
+	c.Dotenv = append(c.Dotenv, existingFiles(root, e.Dir, e.Taskfile.Dotenv)...)
+	c.Dotenv = append(c.Dotenv, existingFiles(root, t.Dir, t.Dotenv)...)
+	if t.Env == nil {
+		return c, nil
+	}
+	_ = t.Env.Range(func(k string, v ast.Var) error {
+		ev := EnvVar{Name: k, Value: fmt.Sprint(v.Value)}
+		if v.Sh != "" && v.Value == nil {
+			ev.Value, ev.Dynamic = v.Sh, true
+		}
+		// By default Task does not override variables set in its environment.
+		if val, ok := os.LookupEnv(k); ok {
+			ev.Value, ev.Shell, ev.Dynamic = val, true, false
+		}
+		c.Env = append(c.Env, ev)
+		return nil
+	})
+	sort.Slice(c.Env, func(i, j int) bool { return c.Env[i].Name < c.Env[j].Name })
+	return c, nil
+}
+
+// stubDynamicVars replaces sh: variables by their command substitution, so
+// templates using them render as what the shell would run.
+func stubDynamicVars(vars *ast.Vars) {
+	if vars == nil {
+		return
+	}
+	_ = vars.Range(func(k string, v ast.Var) error {
+		if v.Sh != "" {
+			vars.Set(k, ast.Var{Value: "$(" + v.Sh + ")"})
+		}
+		return nil
+	})
+}
+
+// existingFiles resolves paths against dir and keeps those that exist, as
+// Task silently skips missing dotenv files.
+func existingFiles(root, dir string, paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, p); err == nil && filepath.IsLocal(rel) {
+			p = rel
+		}
+		out = append(out, p)
+	}
+	return out
+}
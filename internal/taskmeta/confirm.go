@@ -0,0 +1,29 @@
+package taskmeta
+
+import (
+	"strconv"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// ConfirmVar is the task variable that makes taskg ask before running a
+// task, e.g.
+//
+//	vars:
+//	  taskg_confirm: true
+const ConfirmVar = "taskg_confirm"
+
+// confirmFromVars reads ConfirmVar, given either as a YAML bool or a string.
+func confirmFromVars(vars *ast.Vars) bool {
+	if vars == nil || !vars.Exists(ConfirmVar) {
+		return false
+	}
+	switch v := vars.Get(ConfirmVar).Value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
@@ -2,13 +2,12 @@ package taskmeta
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"gopkg.in/yaml.v3"
-	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -19,6 +18,42 @@ type Task struct {
 	Desc string
 	Cmds []string // flattened list of command lines extracted from task definition
 	Line int      // line number in the taskfile for preserving file order
+	// Backend records which tool provides the task (task, make, npm) so execution
+	// can be routed to the right binary.
+	Backend string
+	// HasStatus is set for tasks declaring sources: or status:, whose
+	// up-to-date state can be queried with `task --status`.
+	HasStatus bool
+	// Script is set when the task binary is missing: a shell script run by the
+	// limited built-in runner instead of `task name`.
+	Script string
+	// Project is the subproject path relative to the scanned root and
+	// ProjectDir its absolute directory; both are empty outside recursive mode.
+	Project    string
+	ProjectDir string
+	// Tags come from a "[tag] ..." description prefix or the taskg_tags var.
+	Tags []string
+	// Taskfile is the path of the Taskfile defining the task, when known.
+	Taskfile string
+	// Confirm is set by the taskg_confirm var: taskg asks before running it.
+	Confirm bool
+	// Platforms restricts the task to some OSes and architectures, as given
+	// by its platforms: list; empty means it runs everywhere.
+	Platforms []string
+	// Prompt is the task's prompt: text, a yes/no question Task asks before
+	// running it.
+	Prompt string
+	// Deps names the tasks in deps:, which run before this one.
+	Deps []string
+	// Run is the task's run: mode, "always", "once" or "when_changed"; empty
+	// means always, as in Task.
+	Run string
+	// Choices lists the allowed values of variables, from the taskg_choices
+	// var or the enum: of required vars.
+	Choices map[string][]string
+	// Retry is set by the taskg_retry var: how often taskg retries a failed
+	// in-TUI run. Nil leaves it to the config default.
+	Retry *Retry
 	// Future: Vars []string, Sources []string, etc.
 }
 
@@ -30,19 +65,12 @@ type listJSON struct {
 		Name     string `json:"name"`
 		Desc     string `json:"desc"`
 		Location struct {
-			Line int `json:"line"`
+			Line     int    `json:"line"`
+			Taskfile string `json:"taskfile"`
 		} `json:"location"`
 	} `json:"tasks"`
 }
 
-// rawYAMLTask minimal structure for parsing Taskfile directly when JSON list unavailable.
-type rawYAMLTask struct {
-	Desc string `yaml:"desc"`
-	Cmds any    `yaml:"cmds"` // can be string or []string or []interface{}
-	Cmd  any    `yaml:"cmd"`  // alias
-	// We intentionally ignore other Taskfile keys for now.
-}
-
 // taskfileRootCandidates names we consider as Taskfile roots.
 var taskfileRootCandidates = []string{
 	"Taskfile.yml", "Taskfile.yaml", "Taskfile.dist.yml", "Taskfile.dist.yaml",
@@ -51,7 +79,12 @@ var taskfileRootCandidates = []string{
 
 // FindNearestTaskfileRoot walks upward from start until it finds a Taskfile.* returning that directory.
 func FindNearestTaskfileRoot(start string) (string, error) {
-	dir := start
+	// Walk from an absolute path: filepath.Dir never climbs above "." or a bare
+	// volume name such as "C:".
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
 	for {
 		for _, name := range taskfileRootCandidates {
 			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
@@ -67,74 +100,134 @@ func FindNearestTaskfileRoot(start string) (string, error) {
 	return "", errors.New("no Taskfile found in parent directories")
 }
 
-// DiscoverTasks returns all tasks available (merged includes handled by task CLI itself).
+// DiscoverTasks returns all tasks available, with includes merged.
 // Strategy:
-// 1. Run `task --list --json` in root (preferred)
-// 2. If that fails (older task?), run `task --list` and parse lines `* name: desc`
-// 3. As a final fallback, parse the Taskfile YAML minimally for top-level tasks map.
+//  1. Parse the Taskfile with the go-task library (preferred, no subprocess)
+//  2. If that fails, run `task --list-all --json` in root
+//  3. If that fails too (older task?), run `task --list-all` and parse lines `* name: desc`
+//  4. As a final fallback, read the YAML of the Taskfile and its local
+//     includes; those tasks come with a *PartialError.
+//
+// Taskfiles with remote includes skip the library: go-task only loads them
+// when its experiment is enabled in taskg's own environment, while taskg
+// enables it for the task CLI calls. A broken Taskfile is reported as a
+// *TaskfileError. Tags declared in descriptions are split off whichever way
+// tasks were found.
 func DiscoverTasks(root string) ([]Task, error) {
+	tasks, err := discoverTasks(root)
+	applyDescTags(tasks)
+	return tasks, err
+}
+
+// errRemoteIncludes is why the library is not asked for Taskfiles with
+// remote includes.
+var errRemoteIncludes = errors.New("remote includes need the task CLI")
+
+func discoverTasks(root string) ([]Task, error) {
 	if root == "" {
-		cwd, _ := os.Getwd()
-		root = cwd
+		root, _ = os.Getwd()
 	}
 
-	// Ensure task binary exists early
-	if _, err := exec.LookPath("task"); err != nil {
-		return nil, fmt.Errorf("task binary not found in PATH: %w", err)
+	errLib := errRemoteIncludes
+	if !HasRemoteIncludes(root) {
+		tasks, err := listViaLibraryWithin(root, DiscoveryTimeout)
+		if err == nil {
+			return tasks, nil
+		}
+		// A broken Taskfile is reported with its position rather than as
+		// the chain of failed fallbacks.
+		if tfErr := asTaskfileError(err); tfErr != nil {
+			return nil, tfErr
+		}
+		errLib = err
 	}
 
-	// Preferred: JSON list (gives names & desc only)
+	reason := errLib
+	// `task --list` would hang the same way the parser did.
+	if !errors.Is(errLib, ErrDiscoveryTimeout) && TaskBinaryAvailable() {
+		tasks, err := listViaCLI(root)
+		if len(tasks) > 0 {
+			return tasks, err
+		}
+		if tfErr := asTaskfileError(err); tfErr != nil {
+			return nil, tfErr
+		}
+		if err != nil {
+			reason = err
+		}
+	}
+
+	// Last resort: the YAML of the Taskfile and its local includes
+	tasks, _ := parseTaskfileTree(root)
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("failed to discover tasks: %w", reason)
+	}
+	return tasks, &PartialError{Reason: fmt.Sprintf("%v; showing tasks read from the Taskfiles", firstLine(reason))}
+}
+
+// listViaCLI lists the tasks with `task --list-all --json`, or the plain
+// listing when that fails, enriched from the Taskfile tree. Tasks come with
+// a *PartialError when the task release is too old to list tasks without a
+// description.
+func listViaCLI(root string) ([]Task, error) {
 	tasks, err := listViaJSON(root)
 	if err == nil && len(tasks) > 0 {
 		// Enrich with command lines by parsing Taskfile YAML (optional best effort)
 		enrichTaskCmds(root, tasks)
 		return tasks, nil
 	}
+	if errors.Is(err, ErrDiscoveryTimeout) {
+		return nil, err
+	}
 
 	// Fallback: parse `task --list` plain text
 	tasks, errPlain := listViaPlain(root)
 	if errPlain == nil && len(tasks) > 0 {
 		enrichTaskCmds(root, tasks)
+		if err := Require(FeatureListAll); err != nil {
+			return tasks, &PartialError{Reason: err.Error() + "; tasks without a description are not listed"}
+		}
 		return tasks, nil
 	}
-
-	// Last resort: parse YAML directly (top-level tasks only)
-	tasks, errY := parseTaskfileYAML(root)
-	if errY == nil && len(tasks) > 0 {
-		return tasks, nil
+	// A task release without --json may still say what is wrong with the
+	// Taskfile.
+	if err == nil || asTaskfileError(errPlain) != nil {
+		err = errPlain
 	}
-
-	// Compose meaningful error chain
-	return nil, fmt.Errorf("failed to discover tasks (json:%v plain:%v yaml:%v)", err, errPlain, errY)
+	if err == nil {
+		err = errors.New("task --list found no tasks")
+	}
+	return nil, err
 }
 
 func listViaJSON(root string) ([]Task, error) {
-	cmd := exec.Command("task", "--list", "--json")
-	cmd.Dir = root
+	if err := Require(FeatureJSON); err != nil {
+		return nil, err
+	}
 	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = io.Discard
-	if err := cmd.Run(); err != nil {
+	if err := runListAll(root, &out, "--json"); err != nil {
 		return nil, err
 	}
+	// With remote includes, task prints its trust prompt on stdout before
+	// the JSON, even with --yes.
+	data := out.Bytes()
+	if i := bytes.Index(data, []byte("\n{")); i >= 0 && !bytes.HasPrefix(data, []byte("{")) {
+		data = data[i+1:]
+	}
 	var lj listJSON
-	if err := json.Unmarshal(out.Bytes(), &lj); err != nil {
+	if err := json.Unmarshal(data, &lj); err != nil {
 		return nil, err
 	}
 	var tasks []Task
 	for _, t := range lj.Tasks {
-		tasks = append(tasks, Task{Name: t.Name, Desc: t.Desc, Line: t.Location.Line})
+		tasks = append(tasks, Task{Name: t.Name, Desc: t.Desc, Line: t.Location.Line, Taskfile: t.Location.Taskfile, Backend: BackendTask})
 	}
 	return tasks, nil
 }
 
 func listViaPlain(root string) ([]Task, error) {
-	cmd := exec.Command("task", "--list")
-	cmd.Dir = root
 	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = io.Discard
-	if err := cmd.Run(); err != nil {
+	if err := runListAll(root, &out); err != nil {
 		return nil, err
 	}
 	lines := strings.Split(out.String(), "\n")
@@ -153,38 +246,29 @@ func listViaPlain(root string) ([]Task, error) {
 				desc = strings.TrimSpace(l[idx+1:])
 			}
 			if name != "" {
-				tasks = append(tasks, Task{Name: name, Desc: desc})
+				tasks = append(tasks, Task{Name: name, Desc: desc, Backend: BackendTask})
 			}
 		}
 	}
 	return tasks, nil
 }
 
-// parseTaskfileYAML best-effort parse top-level tasks to capture desc & cmds for fallback.
-func parseTaskfileYAML(root string) ([]Task, error) {
-	// choose first existing candidate
-	var path string
-	for _, c := range taskfileRootCandidates {
-		if _, err := os.Stat(filepath.Join(root, c)); err == nil {
-			path = filepath.Join(root, c)
-			break
-		}
-	}
-	if path == "" {
-		return nil, errors.New("no Taskfile found")
-	}
+// readTaskfileYAML parses the top-level tasks and the includes of one Taskfile.
+func readTaskfileYAML(path string) ([]Task, []taskfileInclude, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var node map[string]any
 	if err := yaml.Unmarshal(data, &node); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	includes, _ := node["includes"].(map[string]any)
+	defaultRun, _ := node["run"].(string)
 	// tasks section may be map[string]any
 	section, ok := node["tasks"].(map[string]any)
 	if !ok {
-		return nil, errors.New("no tasks map in Taskfile")
+		return nil, nil, errors.New("no tasks map in Taskfile")
 	}
 	var tasks []Task
 	for name, raw := range section {
@@ -194,6 +278,8 @@ func parseTaskfileYAML(root string) ([]Task, error) {
 		}
 		var tsk Task
 		tsk.Name = name
+		tsk.Backend = BackendTask
+		tsk.Taskfile = path
 		if d, ok := rm["desc"].(string); ok {
 			tsk.Desc = d
 		}
@@ -206,9 +292,38 @@ func parseTaskfileYAML(root string) ([]Task, error) {
 				tsk.Cmds = extractCmds(v)
 			}
 		}
+		_, hasSources := rm["sources"]
+		_, hasStatus := rm["status"]
+		tsk.HasStatus = hasSources || hasStatus
+		tsk.Platforms = platformsFromYAML(rm["platforms"])
+		tsk.Prompt, _ = rm["prompt"].(string)
+		tsk.Deps = depsFromYAML(rm["deps"])
+		tsk.Run, _ = rm["run"].(string)
+		tsk.Run = cmp.Or(tsk.Run, defaultRun)
+		tsk.Choices = choicesFromYAML(rm)
+		vars, _ := rm["vars"].(map[string]any)
+		tsk.Retry = retryFromValue(vars[RetryVar])
 		tasks = append(tasks, tsk)
 	}
-	return tasks, nil
+	return tasks, parseIncludes(includes), nil
+}
+
+// depsFromYAML reads the task names of a deps: list, whose entries are
+// either names or {task: name, vars: ...} maps.
+func depsFromYAML(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, d := range list {
+		switch d := d.(type) {
+		case string:
+			out = append(out, d)
+		case map[string]any:
+			if name, ok := d["task"].(string); ok {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
 }
 
 func extractCmds(v any) []string {
@@ -230,25 +345,53 @@ func extractCmds(v any) []string {
 	return out
 }
 
-// enrichTaskCmds attempts to parse Taskfile YAML to attach command lines for detail view.
+// enrichTaskCmds attempts to parse Taskfile YAML, including included
+// Taskfiles, to attach command lines for detail view. Listed tasks sharing a
+// name are told apart first (see disambiguate); a name that stays ambiguous
+// is not enriched rather than enriched from the wrong definition.
 func enrichTaskCmds(root string, tasks []Task) {
-	// Build index for quick update
-	idx := make(map[string]*Task, len(tasks))
-	for i := range tasks {
-		idx[tasks[i].Name] = &tasks[i]
-	}
-	parsed, err := parseTaskfileYAML(root)
-	if err != nil {
-		return
-	}
+	parsed, _ := parseTaskfileTree(root)
+	disambiguate(tasks, parsed)
+	defs := make(map[string][]Task, len(parsed))
 	for _, p := range parsed {
-		if t, ok := idx[p.Name]; ok {
-			if len(t.Cmds) == 0 && len(p.Cmds) > 0 {
-				t.Cmds = p.Cmds
-			}
-			if t.Desc == "" && p.Desc != "" {
-				t.Desc = p.Desc
+		defs[p.Name] = append(defs[p.Name], p)
+	}
+	for i := range tasks {
+		t := &tasks[i]
+		var p *Task
+		for j, d := range defs[t.Name] {
+			if (t.Taskfile == "" && len(defs[t.Name]) == 1) || (t.Taskfile != "" && sameFile(d.Taskfile, t.Taskfile)) {
+				p = &defs[t.Name][j]
+				break
 			}
 		}
+		if p == nil {
+			continue
+		}
+		if len(t.Cmds) == 0 && len(p.Cmds) > 0 {
+			t.Cmds = p.Cmds
+		}
+		if t.Desc == "" && p.Desc != "" {
+			t.Desc = p.Desc
+		}
+		t.HasStatus = t.HasStatus || p.HasStatus
+		if len(t.Platforms) == 0 {
+			t.Platforms = p.Platforms
+		}
+		if t.Prompt == "" {
+			t.Prompt = p.Prompt
+		}
+		if len(t.Deps) == 0 {
+			t.Deps = p.Deps
+		}
+		if t.Run == "" {
+			t.Run = p.Run
+		}
+		if t.Choices == nil {
+			t.Choices = p.Choices
+		}
+		if t.Retry == nil {
+			t.Retry = p.Retry
+		}
 	}
 }
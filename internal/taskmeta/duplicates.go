@@ -0,0 +1,57 @@
+package taskmeta
+
+// disambiguate gives listed tasks that share a name the identity of the
+// Taskfile definitions they came from. Text listings of the task CLI can
+// report an included task under its bare name; when the Taskfile tree
+// defines it under a namespace, the namespace-qualified name is used, so
+// running it invokes the right task. parsed is the result of
+// parseTaskfileTree.
+func disambiguate(tasks []Task, parsed []Task) {
+	groups := make(map[string][]int)
+	for i, t := range tasks {
+		groups[t.Name] = append(groups[t.Name], i)
+	}
+	for name, idx := range groups {
+		if len(idx) < 2 {
+			continue
+		}
+		defs := definitionsOf(name, parsed)
+		used := make([]bool, len(defs))
+		claim := func(i int, match func(Task) bool) {
+			for j, d := range defs {
+				if !used[j] && match(d) {
+					used[j] = true
+					tasks[i].Name, tasks[i].Taskfile = d.Name, d.Taskfile
+					return
+				}
+			}
+		}
+		// Entries whose Taskfile is known claim its definition first, the
+		// others take the remaining definitions in order.
+		for _, i := range idx {
+			if file := tasks[i].Taskfile; file != "" {
+				claim(i, func(d Task) bool { return sameFile(d.Taskfile, file) })
+			}
+		}
+		for _, i := range idx {
+			if tasks[i].Taskfile == "" {
+				claim(i, func(Task) bool { return true })
+			}
+		}
+	}
+}
+
+// definitionsOf returns the parsed tasks named name, with or without an
+// include namespace, unqualified ones first.
+func definitionsOf(name string, parsed []Task) []Task {
+	var exact, qualified []Task
+	for _, d := range parsed {
+		switch {
+		case d.Name == name:
+			exact = append(exact, d)
+		case len(d.Name) > len(name) && d.Name[len(d.Name)-len(name)-1:] == ":"+name:
+			qualified = append(qualified, d)
+		}
+	}
+	return append(exact, qualified...)
+}
@@ -0,0 +1,224 @@
+package taskmeta
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// taskBlock locates the definition of a task in a Taskfile: the lines of its
+// body (0-based, end exclusive) and how far they are indented.
+type taskBlock struct {
+	start, end int
+	indent     int
+}
+
+// TaskfileOf returns the Taskfile defining t, the one recorded at discovery
+// or else the Taskfile in root, and the name t has in it. That is t.Name
+// without its include namespace only when the Taskfile is one the project's
+// Taskfile includes, so "docs:build" is never taken for the root's "build".
+func TaskfileOf(t Task, root string) (path, name string, ok bool) {
+	rootPath, rootOK := resolveTaskfile(t.WorkDir(root))
+	if t.Taskfile == "" || (rootOK && sameFile(t.Taskfile, rootPath)) {
+		if t.Taskfile != "" {
+			return t.Taskfile, t.Name, true
+		}
+		return rootPath, t.Name, rootOK
+	}
+	name = t.Name
+	if rootOK {
+		if ns, found := includeNamespace(rootPath, t.Taskfile); found && ns != "" {
+			name = strings.TrimPrefix(name, ns+":")
+		}
+	}
+	return t.Taskfile, name, true
+}
+
+// includeNamespace returns the namespace the tasks of the Taskfile at path
+// get when the Taskfile at rootPath includes it, directly or not.
+func includeNamespace(rootPath, path string) (string, bool) {
+	type pending struct {
+		namespace string
+		path      string
+	}
+	frontier := []pending{{"", rootPath}}
+	visited := map[string]bool{rootPath: true}
+	for depth := 0; len(frontier) > 0 && depth <= maxIncludeDepth; depth++ {
+		var next []pending
+		for _, f := range frontier {
+			for _, inc := range localIncludes(f.path) {
+				p := inc.path
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(filepath.Dir(f.path), p)
+				}
+				p, ok := resolveTaskfile(p)
+				if !ok || visited[p] {
+					continue
+				}
+				visited[p] = true
+				ns := joinNamespace(f.namespace, inc.namespace)
+				if sameFile(p, path) {
+					return ns, true
+				}
+				next = append(next, pending{ns, p})
+			}
+		}
+		frontier = next
+	}
+	return "", false
+}
+
+// localIncludes returns the local includes of the Taskfile at path.
+func localIncludes(path string) []taskfileInclude {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		Includes map[string]any `yaml:"includes"`
+	}
+	if yaml.Unmarshal(data, &doc) != nil {
+		return nil
+	}
+	return parseIncludes(doc.Includes)
+}
+
+// sameFile reports whether paths a and b name the same file.
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ia, errA := os.Stat(a)
+	ib, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(ia, ib)
+}
+
+// TaskYAML returns the body of task name in the Taskfile at path, dedented.
+// name is the task's name in that Taskfile (see TaskfileOf).
+func TaskYAML(path, name string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := splitLines(string(data))
+	b, err := locateTask(string(data), lines, name)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, l := range lines[b.start:b.end] {
+		out.WriteString(dedent(l, b.indent))
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
+
+// TaskLine returns the line, counted from 1, task name is defined on in the
+// Taskfile at path.
+func TaskLine(path, name string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	b, err := locateTask(string(data), splitLines(string(data)), name)
+	if err != nil {
+		return 0, err
+	}
+	return b.start, nil
+}
+
+// ReplaceTaskYAML validates body as a task definition and writes it in place
+// of the current body of task name. Everything outside the task's lines is
+// left untouched.
+func ReplaceTaskYAML(path, name, body string) error {
+	var t ast.Task
+	if err := yaml.Unmarshal([]byte(body), &t); err != nil {
+		// go-task's decode errors span several lines ("err: ...\nfile: ...").
+		msg, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+		return errors.New(strings.TrimSpace(strings.TrimPrefix(msg, "err:")))
+	}
+	if strings.TrimSpace(body) == "" {
+		return errors.New("the task body is empty")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := splitLines(string(data))
+	b, err := locateTask(string(data), lines, name)
+	if err != nil {
+		return err
+	}
+	var block strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		if strings.TrimSpace(l) != "" {
+			block.WriteString(strings.Repeat(" ", b.indent))
+		}
+		block.WriteString(strings.TrimRight(l, " \t") + "\n")
+	}
+	out := splice(append(lines[:b.start:b.start], lines[b.end:]...), b.start, block.String())
+	// Make sure the result still parses before touching the file.
+	var check yaml.Node
+	if err := yaml.Unmarshal([]byte(out), &check); err != nil {
+		return fmt.Errorf("the edited Taskfile would not parse: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(out), info.Mode().Perm())
+}
+
+// locateTask finds task name in the tasks: map of src.
+func locateTask(src string, lines []string, name string) (taskBlock, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		return taskBlock{}, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return taskBlock{}, errors.New("not a Taskfile")
+	}
+	top := doc.Content[0]
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value != "tasks" || top.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		tasks := top.Content[i+1]
+		next := len(lines) // line index where the tasks: section ends
+		if i+2 < len(top.Content) {
+			next = top.Content[i+2].Line - 1
+		}
+		for j := 0; j+1 < len(tasks.Content); j += 2 {
+			key, body := tasks.Content[j], tasks.Content[j+1]
+			if key.Value != name {
+				continue
+			}
+			if body.Line == key.Line {
+				return taskBlock{}, fmt.Errorf("task %q is written on one line; edit it in your editor", name)
+			}
+			end := next
+			if j+2 < len(tasks.Content) {
+				end = tasks.Content[j+2].Line - 1
+			}
+			return taskBlock{
+				start:  key.Line,
+				end:    endOfSection(lines, end, key.Column-1),
+				indent: body.Column - 1,
+			}, nil
+		}
+	}
+	return taskBlock{}, fmt.Errorf("task %q not found in the Taskfile", name)
+}
+
+// dedent removes up to n leading spaces from line.
+func dedent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
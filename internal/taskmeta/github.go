@@ -0,0 +1,197 @@
+package taskmeta
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GitHubRepo names a GitHub repository and, optionally, the branch, tag or
+// commit to read; the default branch otherwise.
+type GitHubRepo struct {
+	Owner string
+	Name  string
+	Ref   string
+}
+
+func (r GitHubRepo) String() string {
+	s := "github.com/" + r.Owner + "/" + r.Name
+	if r.Ref != "" {
+		s += "@" + r.Ref
+	}
+	return s
+}
+
+// repoPartRe matches GitHub owner and repository names.
+var repoPartRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ParseGitHubRepo reads a repository given as github.com/org/repo, with or
+// without https:// and .git, as org/repo, or as a /tree/<ref> URL. A ref can
+// also follow an @, e.g. github.com/org/repo@v1.2.
+func ParseGitHubRepo(s string) (GitHubRepo, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, "www."), "github.com/")
+	var r GitHubRepo
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest, r.Ref = rest[:i], rest[i+1:]
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) > 3 && parts[2] == "tree" && r.Ref == "" {
+		r.Ref = strings.Join(parts[3:], "/")
+		parts = parts[:2]
+	}
+	if len(parts) != 2 || !repoPartRe.MatchString(parts[0]) || !repoPartRe.MatchString(parts[1]) {
+		return GitHubRepo{}, fmt.Errorf("%q is not a GitHub repository: use github.com/org/repo", s)
+	}
+	r.Owner, r.Name = parts[0], strings.TrimSuffix(parts[1], ".git")
+	return r, nil
+}
+
+// gitHubRawBase serves the files of GitHub repositories.
+var gitHubRawBase = "https://raw.githubusercontent.com"
+
+// rawURL returns where the file at p, relative to the repository root, is
+// served.
+func (r GitHubRepo) rawURL(p string) string {
+	ref := r.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return strings.Join([]string{gitHubRawBase, r.Owner, r.Name, ref, p}, "/")
+}
+
+// maxFetchSize bounds each Taskfile downloaded from a repository.
+const maxFetchSize = 1 << 20
+
+// FetchGitHubTaskfiles downloads the root Taskfile of repo and the local
+// Taskfiles it includes into dir, at the same paths as in the repository.
+// Includes that cannot be fetched are skipped, like parseTaskfileTree skips
+// unreadable ones; includes pointing outside the repository are never
+// fetched.
+func FetchGitHubTaskfiles(ctx context.Context, repo GitHubRepo, dir string) error {
+	client := &http.Client{Timeout: DiscoveryTimeout}
+	root, err := fetchTaskfileIn(ctx, client, repo, ".", dir)
+	if err != nil {
+		return err
+	}
+	if root == "" {
+		return fmt.Errorf("no Taskfile found in %s", repo)
+	}
+	frontier := []string{root}
+	seen := map[string]bool{root: true}
+	for depth := 0; len(frontier) > 0 && depth < maxIncludeDepth; depth++ {
+		var next []string
+		for _, file := range frontier {
+			_, includes, err := readTaskfileYAML(filepath.Join(dir, filepath.FromSlash(file)))
+			if err != nil {
+				continue
+			}
+			for _, inc := range includes {
+				p := path.Join(path.Dir(file), filepath.ToSlash(inc.path))
+				if path.IsAbs(inc.path) || p == ".." || strings.HasPrefix(p, "../") {
+					continue
+				}
+				var found string
+				if ext := path.Ext(p); ext == ".yml" || ext == ".yaml" {
+					ok, ferr := fetchRepoFile(ctx, client, repo, p, dir)
+					if ok {
+						found = p
+					}
+					err = ferr
+				} else {
+					found, err = fetchTaskfileIn(ctx, client, repo, p, dir)
+				}
+				if err != nil {
+					return err
+				}
+				if found != "" && !seen[found] {
+					seen[found] = true
+					next = append(next, found)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// fetchTaskfileIn fetches the first of the usual Taskfile names found in the
+// repository directory p and returns its path, or "" when there is none.
+func fetchTaskfileIn(ctx context.Context, client *http.Client, repo GitHubRepo, p, dir string) (string, error) {
+	for _, name := range taskfileRootCandidates {
+		file := path.Join(p, name)
+		ok, err := fetchRepoFile(ctx, client, repo, file, dir)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return file, nil
+		}
+	}
+	return "", nil
+}
+
+// fetchRepoFile downloads the file at p into dir, reporting false when the
+// repository has no such file.
+func fetchRepoFile(ctx context.Context, client *http.Client, repo GitHubRepo, p, dir string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, repo.rawURL(p), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("fetching %s: %s", p, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return false, fmt.Errorf("fetching %s: %w", p, err)
+	}
+	if len(data) > maxFetchSize {
+		return false, fmt.Errorf("fetching %s: larger than %d bytes", p, maxFetchSize)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(target, data, 0o644)
+}
+
+// BrowseTasks lists the tasks of Taskfiles fetched with FetchGitHubTaskfiles
+// without running anything from them: the go-task parser leaves dynamic
+// variables unevaluated, and the task CLI is never called. When the parser
+// fails, e.g. on an include that could not be fetched, the tasks are read
+// from the YAML and come with a *PartialError.
+func BrowseTasks(root string) ([]Task, error) {
+	tasks, err := listViaLibrary(root)
+	if err != nil {
+		tasks, _ = parseTaskfileTree(root)
+		if len(tasks) == 0 {
+			if tfErr := asTaskfileError(err); tfErr != nil {
+				return nil, tfErr
+			}
+			return nil, err
+		}
+		err = &PartialError{Reason: fmt.Sprintf("%v; showing tasks read from the Taskfiles", firstLine(err))}
+	}
+	applyDescTags(tasks)
+	return tasks, err
+}
+
+// firstLine returns the first line of err's message.
+func firstLine(err error) string {
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return msg
+}
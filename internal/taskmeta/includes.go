@@ -0,0 +1,130 @@
+package taskmeta
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth stops runaway include chains when walking Taskfiles.
+const maxIncludeDepth = 8
+
+// taskfileInclude is one entry of a Taskfile's includes: section.
+type taskfileInclude struct {
+	namespace string // empty for flatten: true
+	path      string // as written, relative to the including Taskfile
+}
+
+// parseIncludes reads the includes: section, in either the short
+// (`docs: ./docs`) or the long (`docs: {taskfile: ./docs}`) form. Remote and
+// templated paths are skipped since they cannot be read from disk here.
+func parseIncludes(section map[string]any) []taskfileInclude {
+	var out []taskfileInclude
+	for ns, raw := range section {
+		inc := taskfileInclude{namespace: ns}
+		switch v := raw.(type) {
+		case string:
+			inc.path = v
+		case map[string]any:
+			inc.path, _ = v["taskfile"].(string)
+			if flatten, _ := v["flatten"].(bool); flatten {
+				inc.namespace = ""
+			}
+		}
+		if inc.path == "" || strings.Contains(inc.path, "://") || strings.Contains(inc.path, "{{") {
+			continue
+		}
+		out = append(out, inc)
+	}
+	return out
+}
+
+// resolveTaskfile returns the Taskfile at path, which may name the file itself
+// or a directory holding one of the usual Taskfile names.
+func resolveTaskfile(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if !info.IsDir() {
+		return path, true
+	}
+	for _, name := range taskfileRootCandidates {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return filepath.Join(path, name), true
+		}
+	}
+	return "", false
+}
+
+// parseTaskfileTree parses the Taskfile in root and every local Taskfile it
+// includes, directly or through other includes. Each level of the include tree
+// is parsed concurrently; results are merged in include order so the outcome
+// does not depend on scheduling. Included tasks get their namespace prefix,
+// as `task --list` shows them. Unreadable includes are skipped. The paths of
+// all Taskfiles found are returned too.
+func parseTaskfileTree(root string) ([]Task, []string) {
+	rootPath, ok := resolveTaskfile(root)
+	if !ok {
+		return nil, nil
+	}
+	type pending struct {
+		namespace string
+		path      string
+	}
+	type parsed struct {
+		tasks    []Task
+		includes []taskfileInclude
+	}
+	frontier := []pending{{"", rootPath}}
+	visited := map[string]bool{rootPath: true}
+	files := []string{rootPath}
+	var all []Task
+	for depth := 0; len(frontier) > 0 && depth <= maxIncludeDepth; depth++ {
+		results := make([]parsed, len(frontier))
+		forEachParallel(len(frontier), func(i int) {
+			tasks, includes, err := readTaskfileYAML(frontier[i].path)
+			if err == nil {
+				results[i] = parsed{tasks, includes}
+			}
+		})
+
+		var next []pending
+		for i, f := range frontier {
+			for _, t := range results[i].tasks {
+				t.Name = joinNamespace(f.namespace, t.Name)
+				for j, d := range t.Deps {
+					// A leading ":" refers to a task of the root Taskfile.
+					if name, ok := strings.CutPrefix(d, ":"); ok {
+						t.Deps[j] = name
+					} else {
+						t.Deps[j] = joinNamespace(f.namespace, d)
+					}
+				}
+				all = append(all, t)
+			}
+			for _, inc := range results[i].includes {
+				p := inc.path
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(filepath.Dir(f.path), p)
+				}
+				p, ok := resolveTaskfile(p)
+				if !ok || visited[p] {
+					continue
+				}
+				visited[p] = true
+				files = append(files, p)
+				next = append(next, pending{joinNamespace(f.namespace, inc.namespace), p})
+			}
+		}
+		frontier = next
+	}
+	return all, files
+}
+
+func joinNamespace(ns, name string) string {
+	if ns == "" {
+		return name
+	}
+	return ns + ":" + name
+}
@@ -0,0 +1,157 @@
+package taskmeta
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	task "github.com/go-task/task/v3"
+	taskerrors "github.com/go-task/task/v3/errors"
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// goTaskModule is the module path of the go-task library.
+const goTaskModule = "github.com/go-task/task/v3"
+
+// goTaskVersion returns the release of the go-task library built into taskg;
+// ok is false when the binary carries no build information.
+var goTaskVersion = sync.OnceValues(func() (v Version, ok bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version{}, false
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != goTaskModule {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		return ParseVersion(dep.Version)
+	}
+	return Version{}, false
+})
+
+// setupExecutor reads the Taskfile into e. go-task compares the Taskfile's
+// version: against the version of the main module, which embedded in taskg
+// is taskg's own version (or a VCS pseudo-version), so every v3 Taskfile
+// would be rejected as too new. That check is skipped and made against the
+// go-task release built into taskg instead.
+func setupExecutor(e *task.Executor) error {
+	if err := e.Setup(); err != nil && !isEmbeddedVersionError(err) {
+		return err
+	}
+	have, ok := goTaskVersion()
+	schema := e.Taskfile.Version
+	if !ok || schema == nil {
+		return nil
+	}
+	if have.Less(Version{int(schema.Major()), int(schema.Minor()), int(schema.Patch())}) {
+		return fmt.Errorf("%s: version %s is newer than the Task parser built into taskg (%s)", e.Taskfile.Location, schema, have)
+	}
+	return nil
+}
+
+// isEmbeddedVersionError reports whether err is the schema check that compares
+// the Taskfile version against the version of the main module. The check is
+// the last fallible setup step, so the executor is complete enough to list
+// tasks when it is the only error.
+func isEmbeddedVersionError(err error) bool {
+	var verr *taskerrors.TaskfileVersionCheckError
+	return errors.As(err, &verr) && strings.Contains(verr.Message, "greater than the current version")
+}
+
+// listViaLibrary reads the Taskfile with the go-task parser itself, so includes,
+// namespaces, aliases and templated descriptions are resolved exactly as the
+// task CLI would, without spawning a process. Like `task --list-all`, only
+// internal tasks are left out. When the task binary is
+// missing, each task also carries a script for the built-in runner.
+func listViaLibrary(root string) ([]Task, error) {
+	e := &task.Executor{
+		Dir:    root,
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	}
+	if err := setupExecutor(e); err != nil {
+		return nil, err
+	}
+	// Compile everything: the built-in runner needs internal tasks for deps and calls.
+	all, err := e.GetTaskList()
+	if err != nil {
+		return nil, err
+	}
+	var scripts map[string]string
+	if !TaskBinaryAvailable() {
+		scripts = builtinScripts(all)
+	}
+
+	tasks := make([]Task, 0, len(all))
+	for _, t := range all {
+		if task.FilterOutInternal(t) {
+			continue
+		}
+		out := fromASTTask(t)
+		out.Script = scripts[t.Task]
+		// Compiled tasks drop their vars and have their templates rendered
+		// and loops expanded, so read vars and cmds from the parsed one.
+		parsed := e.Taskfile.Tasks.Get(t.Task)
+		out.Cmds = cmdLines(parsed.Cmds)
+		out.Deps = depNames(parsed.Deps)
+		out.Run = cmp.Or(parsed.Run, e.Taskfile.Run)
+		out.Tags = tagsFromVars(parsed.Vars)
+		out.Confirm = confirmFromVars(parsed.Vars)
+		out.Choices = choicesFromVars(parsed.Vars)
+		out.Retry = retryFromVars(parsed.Vars)
+		tasks = append(tasks, out)
+	}
+	return tasks, nil
+}
+
+// fromASTTask converts a compiled go-task task into our Task model.
+func fromASTTask(t *ast.Task) Task {
+	out := Task{
+		Name:      t.Task,
+		Desc:      t.Desc,
+		Backend:   BackendTask,
+		HasStatus: len(t.Sources) > 0 || len(t.Status) > 0,
+		Platforms: platformsFromAST(t.Platforms),
+		Prompt:    t.Prompt,
+	}
+	if t.Location != nil {
+		out.Line = t.Location.Line
+		out.Taskfile = t.Location.Taskfile
+	}
+	out.Cmds = cmdLines(t.Cmds)
+	return out
+}
+
+// depNames returns the task names of deps.
+func depNames(deps []*ast.Dep) []string {
+	var out []string
+	for _, d := range deps {
+		if d != nil && d.Task != "" {
+			out = append(out, d.Task)
+		}
+	}
+	return out
+}
+
+// cmdLines flattens cmds into command lines; calls of other tasks read
+// "task: name".
+func cmdLines(cmds []*ast.Cmd) []string {
+	var out []string
+	for _, c := range cmds {
+		switch {
+		case c == nil:
+		case c.Cmd != "":
+			out = append(out, c.Cmd)
+		case c.Task != "":
+			out = append(out, "task: "+c.Task)
+		}
+	}
+	return out
+}
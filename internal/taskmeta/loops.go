@@ -0,0 +1,135 @@
+package taskmeta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// Loop is a for: loop of a task's cmds or deps, with the iterations it
+// expands into.
+type Loop struct {
+	Cmd  string   // the command or call as written
+	Over string   // what it iterates, e.g. "ITEM in [a, b]" or "matrix OS × ARCH"
+	Runs []string // one rendered command or "task: name VAR=value" call per iteration
+	Deps bool     // the loop is in deps:, whose iterations run in parallel
+}
+
+// expandCmdLoops pairs the for: cmds of parsed with the iterations the
+// compiler expanded them into. Identical loops written one after another
+// expand into runs of identical For values, which are split evenly.
+func expandCmdLoops(parsed, compiled []*ast.Cmd) []Loop {
+	var src, out []step
+	for _, c := range parsed {
+		if c != nil {
+			src = append(src, step{line: callLine(c.Cmd, c.Task, c.Vars), loop: c.For})
+		}
+	}
+	for _, c := range compiled {
+		if c != nil {
+			out = append(out, step{line: callLine(c.Cmd, c.Task, c.Vars), loop: c.For})
+		}
+	}
+	return pairLoops(src, out, false)
+}
+
+// expandDepLoops is expandCmdLoops for deps.
+func expandDepLoops(parsed, compiled []*ast.Dep) []Loop {
+	var src, out []step
+	for _, d := range parsed {
+		if d != nil {
+			src = append(src, step{line: callLine("", d.Task, d.Vars), loop: d.For})
+		}
+	}
+	for _, d := range compiled {
+		if d != nil {
+			out = append(out, step{line: callLine("", d.Task, d.Vars), loop: d.For})
+		}
+	}
+	return pairLoops(src, out, true)
+}
+
+// step is a cmd or dep reduced to what loop expansion needs.
+type step struct {
+	line string
+	loop *ast.For
+}
+
+func pairLoops(src, out []step, deps bool) []Loop {
+	var loops []Loop
+	j := 0
+	for i, s := range src {
+		if s.loop == nil {
+			j++
+			continue
+		}
+		key := forKey(s.loop)
+		run := 0
+		for j+run < len(out) && out[j+run].loop != nil && forKey(out[j+run].loop) == key {
+			run++
+		}
+		same := 1
+		for k := i + 1; k < len(src) && src[k].loop != nil && forKey(src[k].loop) == key; k++ {
+			same++
+		}
+		n := run / same
+		l := Loop{Cmd: s.line, Over: describeFor(s.loop), Deps: deps}
+		for _, o := range out[j : j+n] {
+			l.Runs = append(l.Runs, o.line)
+		}
+		loops = append(loops, l)
+		j += n
+	}
+	return loops
+}
+
+// callLine renders a cmd, or a call of another task with its vars.
+func callLine(cmd, task string, vars *ast.Vars) string {
+	if task == "" {
+		return cmd
+	}
+	line := "task: " + task
+	if vars != nil {
+		_ = vars.Range(func(k string, v ast.Var) error {
+			if v.Value != nil {
+				line += fmt.Sprintf(" %s=%v", k, v.Value)
+			}
+			return nil
+		})
+	}
+	return line
+}
+
+// describeFor renders what a for: iterates.
+func describeFor(f *ast.For) string {
+	as := f.As
+	if as == "" {
+		as = "ITEM"
+	}
+	switch {
+	case f.Matrix.Len() > 0:
+		return "matrix " + strings.Join(f.Matrix.Keys(), " × ")
+	case f.List != nil:
+		items := make([]string, len(f.List))
+		for i, it := range f.List {
+			items[i] = fmt.Sprint(it)
+		}
+		return as + " in [" + strings.Join(items, ", ") + "]"
+	case f.Var != "":
+		return as + " in " + f.Var
+	default:
+		return as + " in " + f.From
+	}
+}
+
+// forKey identifies a for: by its content.
+func forKey(f *ast.For) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q %v %q %q %q;", f.From, f.List, f.Var, f.Split, f.As)
+	_ = f.Matrix.Range(func(k string, v []any) error {
+		fmt.Fprintf(&b, "%q=%v;", k, v)
+		return nil
+	})
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package taskmeta
+
+import "strings"
+
+// Step is one task run of an execution plan.
+type Step struct {
+	Name string
+	// Depth is how many deps or calls away from the planned task the step is.
+	Depth int
+	// Via says how the step is reached: "dep of build" or "called by build";
+	// empty for the planned task itself.
+	Via string
+	// Unknown is set for tasks taskg does not list, such as internal ones:
+	// what they depend on is not known.
+	Unknown bool
+	// Cycle is set when the task is already being run further up, which
+	// Task stops with an error.
+	Cycle bool
+}
+
+// Plan returns the order in which running t runs tasks: its deps first,
+// depth first, then t itself and the tasks its commands call, in order.
+// Deps of one task really run in parallel. Tasks with run: once (or
+// when_changed, whose calls here carry no vars) are planned only the first
+// time they come up, like Task skips them after that. Only tasks of t's
+// backend and project are considered.
+func Plan(tasks []Task, t Task) []Step {
+	byName := map[string]Task{}
+	for _, o := range tasks {
+		if o.Backend == t.Backend && o.Project == t.Project {
+			byName[o.Name] = o
+		}
+	}
+	var steps []Step
+	done := map[string]bool{}
+	running := map[string]bool{}
+	var visit func(name string, depth int, via string)
+	visit = func(name string, depth int, via string) {
+		o, ok := byName[name]
+		switch {
+		case !ok:
+			steps = append(steps, Step{Name: name, Depth: depth, Via: via, Unknown: true})
+			return
+		case running[name]:
+			steps = append(steps, Step{Name: name, Depth: depth, Via: via, Cycle: true})
+			return
+		case done[name] && (o.Run == "once" || o.Run == "when_changed"):
+			return
+		}
+		running[name] = true
+		for _, d := range o.Deps {
+			visit(d, depth+1, "dep of "+name)
+		}
+		steps = append(steps, Step{Name: name, Depth: depth, Via: via})
+		for _, c := range o.Cmds {
+			if call, ok := strings.CutPrefix(c, "task: "); ok {
+				visit(call, depth+1, "called by "+name)
+			}
+		}
+		running[name] = false
+		done[name] = true
+	}
+	visit(t.Name, 0, "")
+	return steps
+}
@@ -0,0 +1,68 @@
+package taskmeta
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// platformsFromAST renders a task's platforms: list as "os", "arch" or
+// "os/arch" strings.
+func platformsFromAST(ps []*ast.Platform) []string {
+	var out []string
+	for _, p := range ps {
+		if p == nil {
+			continue
+		}
+		switch {
+		case p.OS != "" && p.Arch != "":
+			out = append(out, p.OS+"/"+p.Arch)
+		case p.OS != "":
+			out = append(out, p.OS)
+		case p.Arch != "":
+			out = append(out, p.Arch)
+		}
+	}
+	return out
+}
+
+// platformsFromYAML reads a raw platforms: value.
+func platformsFromYAML(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, it := range list {
+		if s, ok := it.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+// Platform returns the platform taskg runs on, e.g. "linux/amd64".
+func Platform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// Supported reports whether t can run on the current GOOS/GOARCH. Like Task
+// itself, an entry may name an OS, an architecture or both ("windows",
+// "arm64", "darwin/arm64"); a task without platforms runs everywhere.
+func (t Task) Supported() bool {
+	return supportedOn(t.Platforms, runtime.GOOS, runtime.GOARCH)
+}
+
+func supportedOn(platforms []string, goos, goarch string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, p := range platforms {
+		osName, arch, both := strings.Cut(p, "/")
+		switch {
+		case both && osName == goos && arch == goarch:
+			return true
+		case !both && (p == goos || p == goarch):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package taskmeta
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxWorkers bounds concurrent Taskfile parsing and discovery so a monorepo
+// with dozens of Taskfiles does not start dozens of `task` processes at once.
+var maxWorkers = min(runtime.NumCPU(), 8)
+
+// forEachParallel calls fn(0..n-1) on up to maxWorkers goroutines and waits
+// for all calls. Callers write results into index i of a preallocated slice,
+// which keeps merging deterministic regardless of completion order.
+func forEachParallel(n int, fn func(i int)) {
+	workers := min(maxWorkers, n)
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,114 @@
+package taskmeta
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// skipDirs are never searched for subproject Taskfiles.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// FindTaskfileDirs walks the tree below root and returns every directory that
+// holds a Taskfile, root first and the rest in lexical order. Hidden
+// directories (.git, .task, .taskg, ...) and dependency folders are skipped.
+func FindTaskfileDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subdirectories are skipped, not fatal.
+			if path != root && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		for _, name := range taskfileRootCandidates {
+			if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+				dirs = append(dirs, path)
+				break
+			}
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// DiscoverRecursive runs discover in every Taskfile directory below root and
+// tags each task with its subproject, so it is run from the right directory.
+// An error is returned only when no subproject produced any task; partial
+// results of subprojects are reported as one *PartialError.
+func DiscoverRecursive(root string, discover func(string) ([]Task, error)) ([]Task, error) {
+	dirs, err := FindTaskfileDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(dirs))
+	for i, dir := range dirs {
+		rel, _ := filepath.Rel(root, dir)
+		names[i] = filepath.ToSlash(rel)
+	}
+	return DiscoverProjects(dirs, names, discover)
+}
+
+// DiscoverProjects runs discover in each of dirs and tags each task with the
+// name of its project in names, so it is run from the right directory.
+// Tasks of a project named "." are left untagged. Global mode uses it for
+// the registered projects.
+func DiscoverProjects(dirs, names []string, discover func(string) ([]Task, error)) ([]Task, error) {
+	// Discover every subproject concurrently, then merge in directory order.
+	type result struct {
+		tasks []Task
+		err   error
+	}
+	results := make([]result, len(dirs))
+	forEachParallel(len(dirs), func(i int) {
+		tasks, err := discover(dirs[i])
+		results[i] = result{tasks, err}
+	})
+
+	var all []Task
+	var errs, partial []string
+	for i, dir := range dirs {
+		tasks, err := results[i].tasks, results[i].err
+		rel := names[i]
+		var perr *PartialError
+		if errors.As(err, &perr) {
+			partial = append(partial, fmt.Sprintf("%s: %s", rel, perr.Reason))
+		} else if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		for i := range tasks {
+			if rel != "." {
+				tasks[i].Project = rel
+				tasks[i].ProjectDir = dir
+			}
+		}
+		all = append(all, tasks...)
+	}
+	if len(all) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("failed to discover tasks (%s)", strings.Join(errs, "; "))
+	}
+	return all, mergePartial(partial)
+}
+
+// WorkDir returns the directory t runs in: its subproject in recursive mode,
+// root otherwise.
+func (t Task) WorkDir(root string) string {
+	if t.ProjectDir != "" {
+		return t.ProjectDir
+	}
+	return root
+}
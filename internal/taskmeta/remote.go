@@ -0,0 +1,127 @@
+package taskmeta
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteTaskfilesEnv enables Task's remote Taskfiles experiment, which
+// includes of http(s) URLs need.
+const RemoteTaskfilesEnv = "TASK_X_REMOTE_TASKFILES=1"
+
+// isRemote reports whether a Taskfile path is a URL.
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// RemoteSource returns the URL of the remote Taskfile t comes from, or ""
+// for local tasks.
+func (t Task) RemoteSource() string {
+	if isRemote(t.Taskfile) {
+		return t.Taskfile
+	}
+	return ""
+}
+
+// remoteCache memoizes HasRemoteIncludes per root Taskfile.
+var remoteCache sync.Map // root Taskfile path -> remoteScan
+
+// remoteScan is what HasRemoteIncludes found, and the Taskfiles it read with
+// their modification times: editing any of them, not only the root
+// Taskfile, can add or drop a remote include.
+type remoteScan struct {
+	found bool
+	files map[string]time.Time
+}
+
+// current reports whether none of the Taskfiles of the scan changed since.
+func (s remoteScan) current() bool {
+	for f, mtime := range s.files {
+		info, err := os.Stat(f)
+		if err != nil || !info.ModTime().Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasRemoteIncludes reports whether the Taskfile in root, or a local
+// Taskfile it includes, includes a remote Taskfile.
+func HasRemoteIncludes(root string) bool {
+	path, ok := resolveTaskfile(root)
+	if !ok {
+		return false
+	}
+	if v, ok := remoteCache.Load(path); ok && v.(remoteScan).current() {
+		return v.(remoteScan).found
+	}
+	scan := remoteScan{files: make(map[string]time.Time)}
+	// Stat the root before parsing so an edit made meanwhile is seen next
+	// time.
+	if info, err := os.Stat(path); err == nil {
+		scan.files[path] = info.ModTime()
+	}
+	_, files := parseTaskfileTree(root)
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			if _, seen := scan.files[f]; !seen {
+				scan.files[f] = info.ModTime()
+			}
+		}
+		if len(remoteIncludes(f)) > 0 {
+			scan.found = true
+		}
+	}
+	remoteCache.Store(path, scan)
+	return scan.found
+}
+
+// remoteIncludes returns the URLs the Taskfile at path includes.
+func remoteIncludes(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		Includes map[string]any `yaml:"includes"`
+	}
+	if yaml.Unmarshal(data, &doc) != nil {
+		return nil
+	}
+	var urls []string
+	for _, raw := range doc.Includes {
+		var p string
+		switch v := raw.(type) {
+		case string:
+			p = v
+		case map[string]any:
+			p, _ = v["taskfile"].(string)
+		}
+		if isRemote(p) {
+			urls = append(urls, p)
+		}
+	}
+	return urls
+}
+
+// ExperimentEnv returns the variables the task CLI needs to load the
+// Taskfile in root, to be added to the environment of task runs.
+func ExperimentEnv(root string) []string {
+	if HasRemoteIncludes(root) {
+		return []string{RemoteTaskfilesEnv}
+	}
+	return nil
+}
+
+// taskCommandEnv is the environment of task CLI calls in root: nil to
+// inherit taskg's own, unless experiments have to be enabled.
+func taskCommandEnv(root string) []string {
+	if extra := ExperimentEnv(root); extra != nil {
+		return append(os.Environ(), extra...)
+	}
+	return nil
+}
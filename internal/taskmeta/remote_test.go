@@ -0,0 +1,67 @@
+package taskmeta
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverTasksRemoteInclude(t *testing.T) {
+	if !TaskBinaryAvailable() {
+		t.Skip("task binary not found in PATH")
+	}
+	if err := Require(FeatureRemoteTaskfiles); err != nil {
+		t.Skip(err)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("version: '3'\ntasks:\n  hello:\n    desc: Say hello\n    cmds: [echo hello]\n"))
+	}))
+	defer srv.Close()
+	// The task CLI is a child process: it trusts the server's certificate
+	// through SSL_CERT_FILE, which it inherits.
+	cert := filepath.Join(t.TempDir(), "cert.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(cert, pemData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SSL_CERT_FILE", cert)
+
+	root := t.TempDir()
+	taskfile := "version: '3'\nincludes:\n  remote: " + srv.URL + "/Taskfile.yml\ntasks:\n  build:\n    desc: Build\n    cmds: [echo build]\n"
+	if err := os.WriteFile(filepath.Join(root, "Taskfile.yml"), []byte(taskfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := DiscoverTasks(root)
+	if err != nil {
+		t.Fatalf("DiscoverTasks: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		remote bool
+	}{
+		{"build", false},
+		{"remote:hello", true},
+	}
+
+	for _, test := range tests {
+		var found *Task
+		for i := range tasks {
+			if tasks[i].Name == test.name {
+				found = &tasks[i]
+			}
+		}
+		if found == nil {
+			t.Errorf("Task '%s': not discovered in %v", test.name, tasks)
+			continue
+		}
+		if remote := found.RemoteSource() != ""; remote != test.remote {
+			t.Errorf("Task '%s': expected remote %v, got %v (Taskfile %s)", test.name, test.remote, remote, found.Taskfile)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package taskmeta
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// RetryVar is the task variable that makes taskg run a failed task again,
+// e.g. for flaky integration tests. It gives the number of retries and
+// optionally the wait before the first one, which doubles for each further
+// retry:
+//
+//	vars:
+//	  taskg_retry: 3 5s
+//
+// taskg_retry: 0 turns off a retry default from the config file.
+const RetryVar = "taskg_retry"
+
+// Retry is how often a failed run is retried.
+type Retry struct {
+	Count int
+	// Backoff is the wait before the first retry; it doubles for each
+	// further one. Zero retries right away.
+	Backoff time.Duration
+}
+
+// Delay returns the wait before retry n, counted from 1.
+func (r Retry) Delay(n int) time.Duration {
+	return r.Backoff << min(n-1, 16)
+}
+
+// ParseRetry reads a retry policy such as "3" or "3 5s".
+func ParseRetry(s string) (Retry, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Retry{}, fmt.Errorf("retry %q: want a count and an optional backoff, e.g. 3 5s", s)
+	}
+	var r Retry
+	var err error
+	if r.Count, err = strconv.Atoi(fields[0]); err != nil || r.Count < 0 {
+		return Retry{}, fmt.Errorf("retry %q: count must be a number ≥ 0", s)
+	}
+	if len(fields) == 2 {
+		if r.Backoff, err = time.ParseDuration(fields[1]); err != nil || r.Backoff < 0 {
+			return Retry{}, fmt.Errorf("retry %q: invalid backoff %s", s, fields[1])
+		}
+	}
+	return r, nil
+}
+
+// retryFromValue reads a RetryVar value, a YAML number or a string. Invalid
+// values are ignored like an unset var.
+func retryFromValue(v any) *Retry {
+	if v == nil {
+		return nil
+	}
+	r, err := ParseRetry(fmt.Sprint(v))
+	if err != nil {
+		return nil
+	}
+	return &r
+}
+
+// retryFromVars reads RetryVar from the vars of a parsed task.
+func retryFromVars(vars *ast.Vars) *Retry {
+	if vars == nil || !vars.Exists(RetryVar) {
+		return nil
+	}
+	return retryFromValue(vars.Get(RetryVar).Value)
+}
@@ -0,0 +1,77 @@
+package taskmeta
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// The built-in runner and the panes run_in opens run POSIX sh scripts. On
+// Windows they need an sh on PATH, such as the one Git for Windows ships;
+// without one, taskg refuses them up front rather than failing with
+// "executable file not found".
+
+// ShellAvailable reports whether sh can be run: always outside Windows, and
+// on Windows when it is on PATH.
+var ShellAvailable = sync.OnceValue(func() bool {
+	if runtime.GOOS != "windows" {
+		return true
+	}
+	_, err := exec.LookPath("sh")
+	return err == nil
+})
+
+// ErrNoShell explains why a task needing sh cannot run.
+var ErrNoShell = fmt.Errorf("there is no sh on PATH to run it with on %s: install Task, or Git for Windows for its sh", runtime.GOOS)
+
+// ShellError returns why t cannot run here, or nil: tasks of the built-in
+// runner need sh.
+func ShellError(t Task) error {
+	if t.Script == "" || ShellAvailable() {
+		return nil
+	}
+	return fmt.Errorf("%s needs the built-in runner, but %w", t.Name, ErrNoShell)
+}
+
+// PowerShellPrompt reports whether command lines printed for the user's own
+// shell should be PowerShell: on Windows, unless taskg was started from an
+// MSYS shell such as Git Bash.
+func PowerShellPrompt() bool {
+	return runtime.GOOS == "windows" && os.Getenv("MSYSTEM") == ""
+}
+
+// psSafeWordRe matches arguments that need no quoting in PowerShell.
+var psSafeWordRe = regexp.MustCompile(`^[A-Za-z0-9_%+=:./\\-]+$`)
+
+// PowerShellLine renders bin and args as a PowerShell command line, quoting
+// only the words that need it, with each KEY=value of env set first.
+func PowerShellLine(env []string, bin string, args []string) string {
+	var b strings.Builder
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "$env:%s = %s; ", k, psQuote(v))
+	}
+	if !psSafeWordRe.MatchString(bin) {
+		// A quoted command name is a string unless it is invoked with &.
+		b.WriteString("& ")
+	}
+	words := make([]string, 0, len(args)+1)
+	for _, w := range append([]string{bin}, args...) {
+		if psSafeWordRe.MatchString(w) {
+			words = append(words, w)
+		} else {
+			words = append(words, psQuote(w))
+		}
+	}
+	b.WriteString(strings.Join(words, " "))
+	return b.String()
+}
+
+// psQuote wraps s in single quotes for PowerShell.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,34 @@
+package taskmeta
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// IsUpToDate runs `task --status name` in root. Task exits 0 when the task's
+// sources/status checks say it is up to date, and 1 saying so on stderr when
+// it needs to run. Any other failure, such as a Taskfile that does not parse
+// or a missing binary, is returned as an error, a *ListError when task ran.
+func IsUpToDate(root, name string) (bool, error) {
+	args := []string{"--status", name}
+	var stderr bytes.Buffer
+	cmd := exec.Command("task", args...)
+	cmd.Dir = root
+	cmd.Env = taskCommandEnv(root)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false, err
+	}
+	msg := stderrMessage(stderr.String())
+	if exitErr.ExitCode() == 1 && strings.Contains(msg, "is not up-to-date") {
+		return false, nil
+	}
+	return false, &ListError{Dir: root, Args: args, Stderr: msg, Err: err}
+}
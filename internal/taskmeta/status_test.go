@@ -0,0 +1,35 @@
+package taskmeta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsUpToDate(t *testing.T) {
+	if !TaskBinaryAvailable() {
+		t.Skip("task binary not found in PATH")
+	}
+	root := t.TempDir()
+	taskfile := "version: '3'\ntasks:\n  fresh:\n    status: ['true']\n  stale:\n    status: ['false']\n"
+	if err := os.WriteFile(filepath.Join(root, "Taskfile.yml"), []byte(taskfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		upToDate bool
+		fails    bool
+	}{
+		{"fresh", true, false},
+		{"stale", false, false},
+		{"missing", false, true}, // task does not know it: not "needs run"
+	}
+
+	for _, test := range tests {
+		upToDate, err := IsUpToDate(root, test.name)
+		if upToDate != test.upToDate || (err != nil) != test.fails {
+			t.Errorf("Task '%s': expected up-to-date %v, error %v; got %v, %v", test.name, test.upToDate, test.fails, upToDate, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package taskmeta
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Summary returns the output of `task --summary name` run in root, which is
+// Task's own rendering of the description, dependencies and commands.
+func Summary(root, name string) (string, error) {
+	cmd := exec.Command("task", "--summary", name)
+	cmd.Dir = root
+	cmd.Env = taskCommandEnv(root)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(errOut.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
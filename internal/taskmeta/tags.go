@@ -0,0 +1,86 @@
+package taskmeta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// TagsVar is the task variable taskg reads tags from, e.g.
+//
+//	vars:
+//	  taskg_tags: deploy, prod
+const TagsVar = "taskg_tags"
+
+// ParseDescTags splits leading bracketed tags off a description:
+// "[deploy] [prod] Ship to prod" and "[deploy, prod] Ship to prod" both yield
+// tags deploy and prod and the description "Ship to prod".
+func ParseDescTags(desc string) (tags []string, rest string) {
+	rest = strings.TrimSpace(desc)
+	for strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			break
+		}
+		inner := splitTags(rest[1:end])
+		if len(inner) == 0 {
+			break
+		}
+		tags = append(tags, inner...)
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return tags, rest
+}
+
+// splitTags splits a comma or space separated tag list.
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// tagsFromVars reads TagsVar, given either as a string or a YAML list.
+func tagsFromVars(vars *ast.Vars) []string {
+	if vars == nil || !vars.Exists(TagsVar) {
+		return nil
+	}
+	switch v := vars.Get(TagsVar).Value.(type) {
+	case string:
+		return splitTags(v)
+	case []any:
+		var tags []string
+		for _, item := range v {
+			tags = append(tags, splitTags(fmt.Sprint(item))...)
+		}
+		return tags
+	}
+	return nil
+}
+
+// applyDescTags moves description tags into Tags for every task, keeping any
+// tags already set from TagsVar and dropping duplicates.
+func applyDescTags(tasks []Task) {
+	for i := range tasks {
+		tags, rest := ParseDescTags(tasks[i].Desc)
+		if len(tags) == 0 {
+			continue
+		}
+		tasks[i].Desc = rest
+		for _, tag := range tags {
+			if !tasks[i].HasTag(tag) {
+				tasks[i].Tags = append(tasks[i].Tags, tag)
+			}
+		}
+	}
+}
+
+// HasTag reports whether t declares tag (case-insensitively).
+func (t Task) HasTag(tag string) bool {
+	for _, have := range t.Tags {
+		if strings.EqualFold(have, tag) {
+			return true
+		}
+	}
+	return false
+}
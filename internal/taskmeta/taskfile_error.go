@@ -0,0 +1,83 @@
+package taskmeta
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	taskerrors "github.com/go-task/task/v3/errors"
+)
+
+// TaskfileError reports a Taskfile that could not be parsed, with the
+// position of the problem when it is known.
+type TaskfileError struct {
+	File   string
+	Line   int // 1-based; 0 when unknown
+	Column int // 1-based; 0 when unknown
+	Msg    string
+	Err    error
+}
+
+func (e *TaskfileError) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		pos += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			pos += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return fmt.Sprintf("%s: %s", pos, e.Msg)
+}
+
+func (e *TaskfileError) Unwrap() error { return e.Err }
+
+var (
+	yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	// cliParseRe matches the task CLI reporting a broken Taskfile on stderr.
+	cliParseRe = regexp.MustCompile(`Failed to parse (\S+?):? yaml: line (\d+): (.*)$`)
+	ansiRe     = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// asTaskfileError extracts the file and position from go-task's parse
+// errors, or returns nil when err is not about the Taskfile's contents.
+func asTaskfileError(err error) *TaskfileError {
+	var decode *taskerrors.TaskfileDecodeError
+	if errors.As(err, &decode) {
+		msg := decode.Message
+		if msg == "" {
+			// The message is the first line of the formatted error
+			// ("err:  cannot unmarshal ...").
+			msg, _, _ = strings.Cut(ansiRe.ReplaceAllString(decode.Error(), ""), "\n")
+			msg = strings.TrimSpace(strings.TrimPrefix(msg, "err:"))
+		}
+		return &TaskfileError{File: decode.Location, Line: decode.Line, Column: decode.Column, Msg: msg, Err: err}
+	}
+	var invalid *taskerrors.TaskfileInvalidError
+	if errors.As(err, &invalid) {
+		file := invalid.URI
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		e := &TaskfileError{File: file, Msg: invalid.Err.Error(), Err: err}
+		if m := yamlLineRe.FindStringSubmatch(e.Msg); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+			e.Msg = m[2]
+		}
+		return e
+	}
+	var list *ListError
+	if errors.As(err, &list) {
+		if m := cliParseRe.FindStringSubmatch(list.Stderr); m != nil {
+			file := m[1]
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(list.Dir, file)
+			}
+			line, _ := strconv.Atoi(m[2])
+			return &TaskfileError{File: file, Line: line, Msg: m[3], Err: err}
+		}
+	}
+	return nil
+}
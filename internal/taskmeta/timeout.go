@@ -0,0 +1,171 @@
+package taskmeta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiscoveryTimeout bounds how long the go-task parser, or a `task --list`
+// call, may take to read the Taskfile, so a Taskfile with slow dynamic
+// variables or a hung file system cannot freeze the UI.
+var DiscoveryTimeout = 10 * time.Second
+
+// ErrDiscoveryTimeout is wrapped by the error of a discovery given up after
+// DiscoveryTimeout.
+var ErrDiscoveryTimeout = errors.New("timed out")
+
+// PartialError is returned together with usable tasks when discovery had to
+// fall back to a less complete source. Callers should show the tasks and
+// warn with the error message instead of failing.
+type PartialError struct {
+	Reason string
+}
+
+func (e *PartialError) Error() string { return e.Reason }
+
+// maxStderrLines bounds how much of a failed command's stderr is reported.
+const maxStderrLines = 5
+
+// ListError is a failed task CLI call, carrying what task printed on stderr
+// (e.g. "task: Failed to parse Taskfile.yml: ...") instead of only the exit
+// status.
+type ListError struct {
+	Dir    string
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *ListError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("task %s: %v", strings.Join(e.Args, " "), e.Err)
+	}
+	return fmt.Sprintf("task %s: %s", strings.Join(e.Args, " "), e.Stderr)
+}
+
+func (e *ListError) Unwrap() error { return e.Err }
+
+// isUnknownFlag reports whether err is a task CLI rejecting a flag it does
+// not know yet, e.g. --list-all on an old release.
+func isUnknownFlag(err error) bool {
+	var lerr *ListError
+	return errors.As(err, &lerr) && strings.Contains(lerr.Stderr, "unknown flag")
+}
+
+// runListAll runs `task --list-all args...`, falling back to --list on task
+// releases without --list-all; those leave out tasks without a description.
+func runListAll(root string, out *bytes.Buffer, args ...string) error {
+	if !Supports(FeatureListAll) {
+		return runListCommand(root, out, append([]string{"--list"}, args...)...)
+	}
+	err := runListCommand(root, out, append([]string{"--list-all"}, args...)...)
+	if isUnknownFlag(err) {
+		out.Reset()
+		err = runListCommand(root, out, append([]string{"--list"}, args...)...)
+	}
+	return err
+}
+
+// runListCommand runs `task args...` in root with stdout going to out, killing
+// it after DiscoveryTimeout. Remote includes are enabled and trusted for
+// listing, which only reads them; taskg asks before running their tasks.
+func runListCommand(root string, out io.Writer, args ...string) error {
+	env := taskCommandEnv(root)
+	if env != nil {
+		if err := Require(FeatureRemoteTaskfiles); err != nil {
+			return err
+		}
+		args = append([]string{"--yes"}, args...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DiscoveryTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "task", args...)
+	cmd.Dir = root
+	cmd.Env = env
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	// Don't wait for children of task that still hold the output pipe.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("task %s %w after %s", strings.Join(args, " "), ErrDiscoveryTimeout, DiscoveryTimeout)
+	}
+	if err != nil {
+		return &ListError{Dir: root, Args: args, Stderr: stderrMessage(stderr.String()), Err: err}
+	}
+	return nil
+}
+
+// stderrMessage returns the first lines of stderr without colors, joined
+// into one line.
+func stderrMessage(stderr string) string {
+	var lines []string
+	for _, l := range strings.Split(ansiRe.ReplaceAllString(stderr, ""), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+		if len(lines) == maxStderrLines {
+			break
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// libraryRead is a listViaLibrary call in progress; done is closed once
+// tasks and err are set.
+type libraryRead struct {
+	done  chan struct{}
+	tasks []Task
+	err   error
+}
+
+var (
+	libraryMu    sync.Mutex
+	libraryReads = make(map[string]*libraryRead) // root -> read in progress
+)
+
+// listViaLibraryWithin runs listViaLibrary, giving up after timeout. The
+// parser cannot be interrupted, so a hung one is left to finish in the
+// background. Until it does, later calls for the same root wait for it
+// instead of starting another, so a Taskfile that hangs the parser costs one
+// goroutine rather than one per refresh.
+func listViaLibraryWithin(root string, timeout time.Duration) ([]Task, error) {
+	libraryMu.Lock()
+	r, ok := libraryReads[root]
+	if !ok {
+		r = &libraryRead{done: make(chan struct{})}
+		libraryReads[root] = r
+		go func() {
+			r.tasks, r.err = listViaLibrary(root)
+			libraryMu.Lock()
+			delete(libraryReads, root)
+			libraryMu.Unlock()
+			close(r.done)
+		}()
+	}
+	libraryMu.Unlock()
+	select {
+	case <-r.done:
+		// Callers sharing a read must not share its slice.
+		return slices.Clone(r.tasks), r.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("reading the Taskfile %w after %s", ErrDiscoveryTimeout, timeout)
+	}
+}
+
+// mergePartial joins the reasons of partial results from several sources.
+func mergePartial(reasons []string) error {
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &PartialError{Reason: strings.Join(reasons, "; ")}
+}
@@ -0,0 +1,55 @@
+package taskmeta
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// UsageVar is a variable documented in a task description's usage line,
+// e.g. `Usage: task deploy -- ENV="staging"`, with its default value.
+type UsageVar struct {
+	Name    string
+	Default string
+	// Choices are the allowed values, when the task declares them; Default
+	// is then one of them.
+	Choices []string
+}
+
+var (
+	usageRe    = regexp.MustCompile(`Usage: task [^ ]+ -- (.*)`)
+	usageVarRe = regexp.MustCompile(`(\w+)="([^"]+)"`)
+)
+
+// UsageVars returns the variables of the usage line in t's description,
+// followed by the other variables t declares choices for, and whether there
+// are any at all.
+func (t Task) UsageVars() ([]UsageVar, bool) {
+	var vars []UsageVar
+	if m := usageRe.FindStringSubmatch(t.Desc); m != nil {
+		for _, v := range usageVarRe.FindAllStringSubmatch(m[1], -1) {
+			vars = append(vars, UsageVar{Name: v[1], Default: v[2]})
+		}
+	} else if len(t.Choices) == 0 {
+		return nil, false
+	}
+	var extra []UsageVar
+	for name := range t.Choices {
+		if !slices.ContainsFunc(vars, func(v UsageVar) bool { return v.Name == name }) {
+			extra = append(extra, UsageVar{Name: name})
+		}
+	}
+	slices.SortFunc(extra, func(a, b UsageVar) int { return strings.Compare(a.Name, b.Name) })
+	vars = append(vars, extra...)
+	for i, v := range vars {
+		choices := t.Choices[v.Name]
+		if len(choices) == 0 {
+			continue
+		}
+		vars[i].Choices = choices
+		if !slices.Contains(choices, v.Default) {
+			vars[i].Default = choices[0]
+		}
+	}
+	return vars, true
+}
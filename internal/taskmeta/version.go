@@ -0,0 +1,109 @@
+package taskmeta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Version is a parsed Task release number.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string { return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch) }
+
+// Less reports whether v is an older release than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// versionRe finds the release in `task --version` output, e.g.
+// "Task version: v3.39.2 (h1:...)" or "3.39.2".
+var versionRe = regexp.MustCompile(`v?(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion extracts the first release number from s.
+func ParseVersion(s string) (Version, bool) {
+	m := versionRe.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, false
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v, true
+}
+
+// Feature is a task CLI capability taskg relies on, with the release that
+// introduced it.
+type Feature struct {
+	Name  string
+	Since Version
+}
+
+var (
+	FeatureListAll         = Feature{"--list-all", Version{3, 10, 0}}
+	FeatureExitCode        = Feature{"--exit-code", Version{3, 13, 0}}
+	FeatureJSON            = Feature{"--json", Version{3, 20, 0}}
+	FeatureRemoteTaskfiles = Feature{"remote Taskfiles", Version{3, 30, 0}}
+)
+
+// VersionError reports a feature the installed task binary is too old for.
+type VersionError struct {
+	Feature Feature
+	Have    Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("task %s is too old for %s (needs %s)", e.Have, e.Feature.Name, e.Feature.Since)
+}
+
+var (
+	versionOnce  sync.Once
+	taskVersion  Version
+	versionKnown bool
+)
+
+// TaskVersion runs `task --version` on first use and returns the parsed
+// release; ok is false when the binary is missing or its version unknown,
+// e.g. for development builds.
+func TaskVersion() (v Version, ok bool) {
+	versionOnce.Do(func() {
+		if !TaskBinaryAvailable() {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var out bytes.Buffer
+		cmd := exec.CommandContext(ctx, "task", "--version")
+		cmd.Stdout = &out
+		if cmd.Run() == nil {
+			taskVersion, versionKnown = ParseVersion(out.String())
+		}
+	})
+	return taskVersion, versionKnown
+}
+
+// Require returns a *VersionError when the installed task binary is known
+// to predate f. An unknown version is given the benefit of the doubt.
+func Require(f Feature) error {
+	if v, ok := TaskVersion(); ok && v.Less(f.Since) {
+		return &VersionError{Feature: f, Have: v}
+	}
+	return nil
+}
+
+// Supports reports whether the installed task binary has f, see Require.
+func Supports(f Feature) bool { return Require(f) == nil }
@@ -0,0 +1,44 @@
+package taskmeta
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		ok       bool
+	}{
+		{"Task version: v3.39.2 (h1:Zt7KXHmMNq5xWZ1ihphDb+n2zYLCo4BdRe09AnMMIgA=)", Version{3, 39, 2}, true},
+		{"3.10.0", Version{3, 10, 0}, true},
+		{"Task version: v3.9.0\n", Version{3, 9, 0}, true},
+		{"v3.39.3-0.20240910120000-abcdef123456", Version{3, 39, 3}, true},
+		{"Task version: (devel)", Version{}, false},
+		{"", Version{}, false},
+	}
+
+	for _, test := range tests {
+		v, ok := ParseVersion(test.input)
+		if v != test.expected || ok != test.ok {
+			t.Errorf("Version '%s': expected %v %v, got %v %v", test.input, test.expected, test.ok, v, ok)
+		}
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		a, b     Version
+		expected bool
+	}{
+		{Version{3, 9, 0}, Version{3, 10, 0}, true},
+		{Version{3, 10, 0}, Version{3, 9, 9}, false},
+		{Version{2, 99, 99}, Version{3, 0, 0}, true},
+		{Version{3, 20, 0}, Version{3, 20, 0}, false},
+		{Version{3, 20, 0}, Version{3, 20, 1}, true},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Less(test.b); got != test.expected {
+			t.Errorf("%s < %s: expected %v, got %v", test.a, test.b, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package telemetry exports finished task runs as OpenTelemetry traces, so
+// platform teams can see local build and test times in their tracing
+// backend. Spans are posted to an OTLP/HTTP collector in the JSON encoding,
+// which every collector accepts, without pulling in the OpenTelemetry SDK.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskg/internal/config"
+	"taskg/internal/version"
+)
+
+// Timeout bounds each export request.
+const Timeout = 10 * time.Second
+
+// Run is a finished run of a task.
+type Run struct {
+	Task     string
+	Args     []string
+	Project  string // project name, e.g. the base name of its root
+	Dir      string // directory the task ran in
+	Started  time.Time
+	Duration time.Duration
+	ExitCode int
+	Attempt  int // 1 for the first attempt, 0 when unknown
+	// Steps are the tasks the run started besides Task itself, e.g. its
+	// deps, exported as child spans.
+	Steps []Step
+}
+
+// Step is a task run as part of another one.
+type Step struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// OTLP/JSON messages, as far as taskg fills them in.
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	resource struct {
+		Attributes []attribute `json:"attributes"`
+	}
+	scopeSpans struct {
+		Scope scope  `json:"scope"`
+		Spans []span `json:"spans"`
+	}
+	scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	span struct {
+		TraceID      string      `json:"traceId"`
+		SpanID       string      `json:"spanId"`
+		ParentSpanID string      `json:"parentSpanId,omitempty"`
+		Name         string      `json:"name"`
+		Kind         int         `json:"kind"`
+		Start        string      `json:"startTimeUnixNano"`
+		End          string      `json:"endTimeUnixNano"`
+		Attributes   []attribute `json:"attributes,omitempty"`
+		Status       status      `json:"status"`
+	}
+	attribute struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	value struct {
+		String *string `json:"stringValue,omitempty"`
+		Int    *string `json:"intValue,omitempty"` // int64 travels as a string
+	}
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+func stringAttr(key, v string) attribute { return attribute{key, value{String: &v}} }
+
+func intAttr(key string, v int) attribute {
+	s := strconv.Itoa(v)
+	return attribute{key, value{Int: &s}}
+}
+
+func nanos(t time.Time) string { return strconv.FormatInt(t.UnixNano(), 10) }
+
+// randomID returns n random bytes, hex-encoded as OTLP/JSON expects ids.
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// spans turns r into a root span for the run and a child span per step.
+func (r Run) spans() []span {
+	traceID, rootID := randomID(16), randomID(8)
+	end := r.Started.Add(r.Duration)
+	attrs := []attribute{
+		stringAttr("taskg.task", r.Task),
+		stringAttr("taskg.project", r.Project),
+		stringAttr("taskg.dir", r.Dir),
+		intAttr("process.exit_code", r.ExitCode),
+	}
+	if len(r.Args) > 0 {
+		attrs = append(attrs, stringAttr("taskg.args", strings.Join(r.Args, " ")))
+	}
+	if r.Attempt > 0 {
+		attrs = append(attrs, intAttr("taskg.attempt", r.Attempt))
+	}
+	st := status{Code: statusOK}
+	if r.ExitCode != 0 {
+		st = status{Code: statusError, Message: fmt.Sprintf("exit code %d", r.ExitCode)}
+	}
+	out := []span{{
+		TraceID:    traceID,
+		SpanID:     rootID,
+		Name:       "task " + r.Task,
+		Kind:       spanKindInternal,
+		Start:      nanos(r.Started),
+		End:        nanos(end),
+		Attributes: attrs,
+		Status:     st,
+	}}
+	for _, s := range r.Steps {
+		out = append(out, span{
+			TraceID:      traceID,
+			SpanID:       randomID(8),
+			ParentSpanID: rootID,
+			Name:         "task " + s.Name,
+			Kind:         spanKindInternal,
+			Start:        nanos(s.Start),
+			End:          nanos(s.End),
+			Attributes:   []attribute{stringAttr("taskg.task", s.Name)},
+		})
+	}
+	return out
+}
+
+// Enabled reports whether runs are exported with cfg.
+func Enabled(cfg config.Telemetry) bool { return cfg.Endpoint != "" }
+
+// Export posts r as a trace to the collector of cfg.
+func Export(ctx context.Context, cfg config.Telemetry, r Run) error {
+	if !Enabled(cfg) {
+		return nil
+	}
+	service := cfg.ServiceName
+	if service == "" {
+		service = "taskg"
+	}
+	body, err := json.Marshal(exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: []attribute{stringAttr("service.name", service)}},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: "taskg", Version: version.Version}, Spans: r.spans()}},
+	}}})
+	if err != nil {
+		return err
+	}
+	target := strings.TrimSuffix(cfg.Endpoint, "/")
+	if !strings.HasSuffix(target, "/v1/traces") {
+		target += "/v1/traces"
+	}
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package textdiff compares runs of output line by line, with Myers'
+// algorithm, and renders the result as a unified diff.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op says what an Edit does to the old text.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is one line of a diff.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// maxEdits bounds the search for the shortest diff; beyond it the differing
+// middle is shown as deleted and inserted as a whole, which keeps memory and
+// time in check for outputs that have little in common.
+const maxEdits = 1000
+
+// Lines returns the edits turning a into b.
+func Lines(a, b []string) []Edit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	var edits []Edit
+	for _, l := range a[:pre] {
+		edits = append(edits, Edit{Equal, l})
+	}
+	edits = append(edits, myers(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		edits = append(edits, Edit{Equal, l})
+	}
+	return edits
+}
+
+// myers finds a shortest edit script. trace[d] keeps the furthest x reached
+// on diagonals -d-1..d+1 before step d, for walking back along the path.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+	limit := min(n+m, maxEdits)
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	edits := make([]Edit, 0, n+m)
+	for _, l := range a {
+		edits = append(edits, Edit{Delete, l})
+	}
+	for _, l := range b {
+		edits = append(edits, Edit{Insert, l})
+	}
+	return edits
+}
+
+func backtrack(a, b []string, trace [][]int) []Edit {
+	x, y := len(a), len(b)
+	var rev []Edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		at := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			rev = append(rev, Edit{Equal, a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				rev = append(rev, Edit{Insert, b[y]})
+			} else {
+				x--
+				rev = append(rev, Edit{Delete, a[x]})
+			}
+		}
+	}
+	edits := make([]Edit, len(rev))
+	for i, e := range rev {
+		edits[len(rev)-1-i] = e
+	}
+	return edits
+}
+
+// Unified renders the differences between a and b as a unified diff with
+// the given number of context lines, or returns "" when they are equal.
+func Unified(aName, bName string, a, b []string, context int) string {
+	edits := Lines(a, b)
+	// starts[i] holds the line numbers in a and b that edit i is at.
+	starts := make([][2]int, len(edits)+1)
+	for i, e := range edits {
+		starts[i+1] = starts[i]
+		if e.Op != Insert {
+			starts[i+1][0]++
+		}
+		if e.Op != Delete {
+			starts[i+1][1]++
+		}
+	}
+
+	var sb strings.Builder
+	i := 0
+	for {
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start, end := max(0, i-context), i
+		// Merge changes separated by less than twice the context.
+		for {
+			for end < len(edits) && edits[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(len(edits), end+context)
+			break
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+		}
+		from, to := starts[start], starts[end]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(from[0], to[0]-from[0]), hunkRange(from[1], to[1]-from[1]))
+		for _, e := range edits[start:end] {
+			sb.WriteString([]string{" ", "-", "+"}[e.Op] + e.Line + "\n")
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the 0-based start and length of a hunk as diff does.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
@@ -0,0 +1,85 @@
+package textdiff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sides returns the old and new text an edit script describes, and how many
+// lines it changes.
+func sides(edits []Edit) (a, b []string, changes int) {
+	for _, e := range edits {
+		if e.Op != Insert {
+			a = append(a, e.Line)
+		}
+		if e.Op != Delete {
+			b = append(b, e.Line)
+		}
+		if e.Op != Equal {
+			changes++
+		}
+	}
+	return a, b, changes
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		changes int
+	}{
+		{"", "", 0},
+		{"a b c", "a b c", 0},
+		{"", "a b", 2},
+		{"a b", "", 2},
+		{"a b c", "a x c", 2},
+		{"a b c a b b a", "c b a b a c", 5}, // Myers' paper example
+		{"x a b c", "a b c y", 2},
+		{"a b c d e", "e d c b a", 8},
+	}
+
+	for _, test := range tests {
+		a, b := strings.Fields(test.a), strings.Fields(test.b)
+		gotA, gotB, changes := sides(Lines(a, b))
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Errorf("Diff '%s' → '%s': edits describe %v → %v", test.a, test.b, gotA, gotB)
+		}
+		if changes != test.changes {
+			t.Errorf("Diff '%s' → '%s': expected %d changed lines, got %d", test.a, test.b, test.changes, changes)
+		}
+	}
+}
+
+func TestLinesBeyondMaxEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxEdits; i++ {
+		a = append(a, "a")
+		b = append(b, "b")
+	}
+	gotA, gotB, changes := sides(Lines(a, b))
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) || changes != 2*maxEdits {
+		t.Errorf("Diff of %d unrelated lines: expected %d changes, got %d", maxEdits, 2*maxEdits, changes)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		context  int
+		expected string
+	}{
+		{"a b c", "a b c", 3, ""},
+		{"a b c", "a x c", 1, "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"a b c", "a b c d", 0, "--- old\n+++ new\n@@ -3,0 +4 @@\n+d\n"},
+		{"a b c d e f g h", "a B c d e f g H", 1, "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -7,2 +7,2 @@\n g\n-h\n+H\n"},
+		{"a b c d e", "a B c D e", 1, "--- old\n+++ new\n@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n-d\n+D\n e\n"},
+		{"a", "", 3, "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n"},
+	}
+
+	for _, test := range tests {
+		got := Unified("old", "new", strings.Fields(test.a), strings.Fields(test.b), test.context)
+		if got != test.expected {
+			t.Errorf("Diff '%s' → '%s' with %d context lines:\nexpected\n%s\ngot\n%s", test.a, test.b, test.context, test.expected, got)
+		}
+	}
+}
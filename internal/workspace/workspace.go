@@ -0,0 +1,245 @@
+// Package workspace runs one task in each project of a workspace, one after
+// another or in parallel, and sums up which projects passed.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"taskg/internal/runner"
+	"taskg/internal/taskmeta"
+)
+
+// Project is a project root of a workspace.
+type Project struct {
+	Name string
+	Root string
+}
+
+// Projects names the roots by their directory, or by their full path when
+// two directories share a name.
+func Projects(roots []string) []Project {
+	count := map[string]int{}
+	for _, r := range roots {
+		count[filepath.Base(r)]++
+	}
+	out := make([]Project, len(roots))
+	for i, r := range roots {
+		out[i] = Project{Name: filepath.Base(r), Root: r}
+		if count[out[i].Name] > 1 {
+			out[i].Name = r
+		}
+	}
+	return out
+}
+
+// Status is how a project's run ended.
+type Status int
+
+const (
+	Passed Status = iota
+	Failed
+	// Skipped projects have no such task, or their tasks could not be
+	// discovered.
+	Skipped
+)
+
+// Result is the outcome of the run in one project.
+type Result struct {
+	Project  Project
+	Task     taskmeta.Task
+	Status   Status
+	ExitCode int
+	Started  time.Time
+	Duration time.Duration
+	// Reason explains a skipped project or a run that could not start.
+	Reason string
+}
+
+// Options configures Run.
+type Options struct {
+	// Parallel runs all projects at once, with their output lines prefixed
+	// by the project name.
+	Parallel bool
+	// Discover finds the tasks of a project root.
+	Discover func(root string) ([]taskmeta.Task, error)
+	// Out receives the output and progress lines.
+	Out io.Writer
+	// Cancel, when closed, stops parallel runs. Sequential runs leave the
+	// interrupt to the task in the foreground and skip the projects after it.
+	Cancel <-chan struct{}
+}
+
+// Run runs the task called name with args in every project and returns the
+// results in project order.
+func Run(projects []Project, name string, args []string, opt Options) []Result {
+	results := make([]Result, len(projects))
+	if !opt.Parallel {
+		for i, p := range projects {
+			select {
+			case <-opt.Cancel:
+				results[i] = Result{Project: p, Status: Skipped, Reason: "interrupted"}
+				continue
+			default:
+			}
+			fmt.Fprintf(opt.Out, "==> %s (%s)\n", p.Name, p.Root)
+			results[i] = runOne(p, name, args, opt)
+			if results[i].Status == Skipped {
+				fmt.Fprintf(opt.Out, "skipped: %s\n", results[i].Reason)
+			}
+			fmt.Fprintln(opt.Out)
+		}
+		return results
+	}
+	width := 0
+	for _, p := range projects {
+		width = max(width, len(p.Name))
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prefix := fmt.Sprintf("%-*s | ", width, p.Name)
+			print := func(line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Fprintln(opt.Out, prefix+line)
+			}
+			results[i] = runOne(p, name, args, opt, print)
+			if results[i].Status == Skipped {
+				print("skipped: " + results[i].Reason)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runOne runs the task in p: in the foreground with the terminal, or, given
+// print, with its output lines passed to print.
+func runOne(p Project, name string, args []string, opt Options, print ...func(string)) Result {
+	res := Result{Project: p, Status: Skipped}
+	tasks, err := opt.Discover(p.Root)
+	var partial *taskmeta.PartialError
+	if err != nil && !errors.As(err, &partial) {
+		res.Reason = err.Error()
+		return res
+	}
+	i := slices.IndexFunc(tasks, func(t taskmeta.Task) bool { return t.Name == name })
+	if i < 0 {
+		res.Reason = "no task " + name
+		return res
+	}
+	res.Task = tasks[i]
+	if !res.Task.Supported() {
+		res.Reason = "not for this platform"
+		return res
+	}
+	if !taskmeta.ShellAvailable() && res.Task.Script != "" {
+		res.Reason = "no sh to run it with"
+		return res
+	}
+	bin, argv := res.Task.RunInvocation(args)
+	env := append(os.Environ(), taskmeta.ExperimentEnv(p.Root)...)
+	res.Started = time.Now()
+	if len(print) == 0 {
+		c := exec.Command(bin, argv...)
+		c.Dir, c.Env = p.Root, env
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, opt.Out, opt.Out
+		err = c.Run()
+		res.Duration = time.Since(res.Started)
+		return finish(res, c.ProcessState, err)
+	}
+	r, err := runner.Start(p.Root, bin, argv, env)
+	if err != nil {
+		return finish(res, nil, err)
+	}
+	cancel, canceled := opt.Cancel, false
+	for {
+		select {
+		case ev := <-r.Events():
+			if ev.Done {
+				res.Duration = time.Since(res.Started)
+				res.Status, res.ExitCode = Passed, ev.ExitCode
+				if ev.ExitCode != 0 || ev.Err != nil {
+					res.Status = Failed
+				}
+				if ev.Err != nil {
+					res.Reason = ev.Err.Error()
+				}
+				if canceled {
+					res.Reason = "interrupted"
+				}
+				return res
+			}
+			if !ev.Partial {
+				print[0](ev.Line)
+			}
+		case <-cancel:
+			r.Cancel()
+			cancel, canceled = nil, true // wait for the Done event
+		}
+	}
+}
+
+// finish records how a foreground run ended.
+func finish(res Result, state *os.ProcessState, err error) Result {
+	res.Status = Passed
+	if state != nil {
+		res.ExitCode = state.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	if err != nil {
+		res.Status = Failed
+		if !errors.As(err, &exitErr) {
+			res.Reason = err.Error()
+			res.ExitCode = -1
+		}
+	}
+	return res
+}
+
+// Summary writes one line per project and the totals, e.g.
+//
+//	✓ frontend  12s
+//	✗ backend   exit 1 after 3s
+//	- docs      skipped: no task test
+//	1 passed, 1 failed, 1 skipped
+func Summary(w io.Writer, results []Result) {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Project.Name))
+	}
+	var counts [3]int
+	for _, r := range results {
+		counts[r.Status]++
+		var mark, detail string
+		switch r.Status {
+		case Passed:
+			mark, detail = "✓", r.Duration.Round(100*time.Millisecond).String()
+		case Failed:
+			mark, detail = "✗", fmt.Sprintf("exit %d after %s", r.ExitCode, r.Duration.Round(100*time.Millisecond))
+			if r.Reason != "" {
+				detail = r.Reason
+			}
+		case Skipped:
+			mark, detail = "-", "skipped: "+r.Reason
+		}
+		fmt.Fprintf(w, "%s %-*s  %s\n", mark, width, r.Project.Name, detail)
+	}
+	parts := []string{fmt.Sprintf("%d passed", counts[Passed]), fmt.Sprintf("%d failed", counts[Failed])}
+	if counts[Skipped] > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", counts[Skipped]))
+	}
+	fmt.Fprintln(w, strings.Join(parts, ", "))
+}